@@ -0,0 +1,29 @@
+package guesser
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// global admin token; admin features are disabled while it is empty
+var adminToken string
+
+// ConfigureAdmin sets the bearer token required by admin-only features.
+func ConfigureAdmin(token string) {
+	adminToken = token
+}
+
+// isAdmin reports whether r carries "Authorization: Bearer <admin token>".
+func isAdmin(r *http.Request) bool {
+	if adminToken == "" {
+		return false
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
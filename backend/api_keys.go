@@ -0,0 +1,243 @@
+package guesser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// APIKey is a credential issued to a third party embedding the guesser.
+type APIKey struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// AllowedOrigins lists the browser origins the key may be used from,
+	// e.g. "https://example.com". "*" allows any origin. Requests without
+	// an Origin header (server-to-server) are always allowed.
+	AllowedOrigins []string  `json:"allowedOrigins"`
+	RateLimit      RateLimit `json:"rateLimit"`
+	// Datasets restricts which datasets the key may use, "default" being
+	// the main catalog. Empty means all.
+	Datasets []string `json:"datasets"`
+
+	requests atomic.Int64
+	rejected atomic.Int64
+	lastUsed atomic.Int64 // unix seconds
+}
+
+// AllowsOrigin reports whether origin may use the key.
+func (k *APIKey) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range k.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsDataset reports whether the key may use the given dataset.
+func (k *APIKey) AllowsDataset(id string) bool {
+	if len(k.Datasets) == 0 {
+		return true
+	}
+	for _, d := range k.Datasets {
+		if d == id {
+			return true
+		}
+	}
+	return false
+}
+
+// keyAllowsDataset reports whether the request's API key, if it has one,
+// may use datasetID ("" being the default dataset).
+func keyAllowsDataset(r *http.Request, datasetID string) bool {
+	if datasetID == "" {
+		datasetID = defaultDatasetID
+	}
+	key, ok := apiKeyFromContext(r.Context())
+	return !ok || key.AllowsDataset(datasetID)
+}
+
+// allowKeyDataset answers 403 when the request's API key may not use
+// datasetID, and reports whether it may.
+func allowKeyDataset(w http.ResponseWriter, r *http.Request, datasetID string) bool {
+	if keyAllowsDataset(r, datasetID) {
+		return true
+	}
+	writeError(w, http.StatusForbidden, CodeForbidden, "this API key may not use that dataset")
+	return false
+}
+
+// DefaultDatasetOnly guards endpoints that only serve the default dataset
+// from API keys restricted to others.
+func DefaultDatasetOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allowKeyDataset(w, r, defaultDatasetID) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// scope tells the key's clients apart from other keys' without giving
+// the key away.
+func (k *APIKey) scope() string {
+	sum := sha256.Sum256([]byte(k.Key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// APIKeyUsage is the per-key counters shown to admins.
+type APIKeyUsage struct {
+	Name      string     `json:"name"`
+	KeyPrefix string     `json:"keyPrefix"`
+	Requests  int64      `json:"requests"`
+	Rejected  int64      `json:"rejected"`
+	LastUsed  *time.Time `json:"lastUsed,omitempty"`
+}
+
+const apiKeyHeader = "X-API-Key"
+
+type apiKeyContextKey struct{}
+
+// apiKeyFromContext returns the key a request was authenticated with.
+func apiKeyFromContext(ctx context.Context) (*APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key, ok
+}
+
+type apiKeyRegistry struct {
+	mu      sync.RWMutex
+	keys    map[string]*APIKey
+	limiter *rateLimiter
+}
+
+// global key registry; empty until LoadAPIKeys is called
+var apiKeys = &apiKeyRegistry{
+	keys:    make(map[string]*APIKey),
+	limiter: newRateLimiter(),
+}
+
+// LoadAPIKeys reads a JSON array of APIKey definitions.
+func LoadAPIKeys(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var list []*APIKey
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	keys := make(map[string]*APIKey, len(list))
+	for i, k := range list {
+		if len(k.Key) < 16 {
+			return fmt.Errorf("%s: key #%d (%q) must be at least 16 characters", path, i, k.Name)
+		}
+		if _, dup := keys[k.Key]; dup {
+			return fmt.Errorf("%s: key #%d (%q) is a duplicate", path, i, k.Name)
+		}
+		keys[k.Key] = k
+	}
+
+	apiKeys.mu.Lock()
+	apiKeys.keys = keys
+	apiKeys.mu.Unlock()
+	return nil
+}
+
+func (reg *apiKeyRegistry) lookup(key string) (*APIKey, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	k, ok := reg.keys[key]
+	return k, ok
+}
+
+func (reg *apiKeyRegistry) usage() []APIKeyUsage {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	out := make([]APIKeyUsage, 0, len(reg.keys))
+	for _, k := range reg.keys {
+		entry := APIKeyUsage{
+			Name:      k.Name,
+			KeyPrefix: k.Key[:6],
+			Requests:  k.requests.Load(),
+			Rejected:  k.rejected.Load(),
+		}
+		if ts := k.lastUsed.Load(); ts != 0 {
+			t := time.Unix(ts, 0).UTC()
+			entry.LastUsed = &t
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// APIKeyMiddleware checks X-API-Key when present: the key must exist, be
+// used from an allowed origin and stay under its rate limit. Requests
+// without a key (the first-party frontend) pass through untouched.
+func APIKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.Header.Get(apiKeyHeader)
+		if raw == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, ok := apiKeys.lookup(raw)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, CodeInvalidAPIKey, "invalid API key")
+			return
+		}
+
+		key.requests.Add(1)
+		key.lastUsed.Store(time.Now().Unix())
+
+		if !key.AllowsOrigin(r.Header.Get("Origin")) {
+			key.rejected.Add(1)
+			writeError(w, http.StatusForbidden, CodeOriginNotAllowed, "origin not allowed for this API key")
+			return
+		}
+
+		if ok, wait := apiKeys.limiter.allow(key.Key, key.RateLimit); !ok {
+			key.rejected.Add(1)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, CodeRateLimited, "API key rate limit exceeded")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), apiKeyContextKey{}, key)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ---------------------------------
+// /api/v1/admin/api-keys   (GET, admin only)
+// ---------------------------------
+
+func APIKeyUsageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		if !isAdmin(r) {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "admin credentials required")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, apiKeys.usage())
+	})
+}
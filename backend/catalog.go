@@ -0,0 +1,189 @@
+package guesser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type CatalogResponse struct {
+	Games []*Game `json:"games"`
+	// Total counts every game matching the filters, across all pages.
+	Total int `json:"total"`
+	// NextCursor fetches the following page; empty on the last one.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+const (
+	defaultCatalogLimit = 24
+	maxCatalogLimit     = 100
+)
+
+// catalogLess orders the catalog by normalized title, then ID, so the
+// order is total and stays put across dataset reloads.
+func catalogLess(a, b *Game) bool {
+	an, bn := normalizeTitle(a.Name), normalizeTitle(b.Name)
+	if an != bn {
+		return an < bn
+	}
+	return a.ID < b.ID
+}
+
+// catalogCursor marks the last game of a page. It holds sort keys rather
+// than an offset, so pages stay stable when games are added or removed.
+type catalogCursor struct {
+	Name string `json:"n"`
+	ID   int    `json:"i"`
+}
+
+func encodeCursor(g *Game) string {
+	data, _ := json.Marshal(catalogCursor{Name: normalizeTitle(g.Name), ID: g.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (catalogCursor, bool) {
+	var c catalogCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || json.Unmarshal(data, &c) != nil {
+		return catalogCursor{}, false
+	}
+	return c, true
+}
+
+// CatalogFilter narrows the catalog; zero values match everything.
+type CatalogFilter struct {
+	Genre    string
+	Platform string
+	YearFrom int
+	YearTo   int
+	Query    string // normalized title substring
+}
+
+func (f CatalogFilter) Matches(g *Game) bool {
+	switch {
+	case f.Genre != "" && !stringSliceContains(g.Genres, f.Genre):
+		return false
+	case f.Platform != "" && !stringSliceContains(g.Platforms, f.Platform):
+		return false
+	case f.YearFrom != 0 && g.Year < f.YearFrom:
+		return false
+	case f.YearTo != 0 && g.Year > f.YearTo:
+		return false
+	case f.Query != "" && !strings.Contains(normalizeTitle(g.Name), f.Query):
+		return false
+	}
+	return true
+}
+
+// catalogOrder lists the games f could match in catalog order: with a
+// genre or platform, only those indexed under it, otherwise every game.
+func catalogOrder(idx GameIndex, f CatalogFilter) []int {
+	var lists [][]int
+	if f.Genre != "" {
+		lists = append(lists, idx.ByGenre[strings.ToLower(f.Genre)])
+	}
+	if f.Platform != "" {
+		lists = append(lists, idx.ByPlatform[strings.ToLower(f.Platform)])
+	}
+	if len(lists) == 0 || idx.ByGenre == nil {
+		return idx.ByName
+	}
+
+	order := slices.Clone(intersectAll(lists))
+	sort.Slice(order, func(i, j int) bool {
+		return catalogLess(idx.Games[order[i]], idx.Games[order[j]])
+	})
+	return order
+}
+
+func parseCatalogFilter(r *http.Request) (CatalogFilter, string) {
+	q := r.URL.Query()
+	f := CatalogFilter{
+		Genre:    strings.TrimSpace(q.Get("genre")),
+		Platform: strings.TrimSpace(q.Get("platform")),
+		Query:    normalizeTitle(q.Get("q")),
+	}
+
+	for name, dst := range map[string]*int{"yearFrom": &f.YearFrom, "yearTo": &f.YearTo} {
+		raw := q.Get(name)
+		if raw == "" {
+			continue
+		}
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			return f, name + " must be a year"
+		}
+		*dst = year
+	}
+
+	return f, ""
+}
+
+// ---------------------------------
+// /api/v1/games   (GET)
+//   ?genre=&platform=&yearFrom=&yearTo=&q=&limit=&cursor=&fields=
+// ---------------------------------
+
+func CatalogHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		filter, problem := parseCatalogFilter(r)
+		if problem != "" {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, problem)
+			return
+		}
+
+		limit, ok := queryInt(r, "limit", defaultCatalogLimit, maxCatalogLimit)
+		if !ok {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "limit must be a positive integer")
+			return
+		}
+
+		idx := data.Current().Index
+		order := catalogOrder(idx, filter)
+		matching := order
+
+		// Skip past the cursor with a binary search over the sort keys.
+		if raw := r.URL.Query().Get("cursor"); raw != "" {
+			cursor, ok := decodeCursor(raw)
+			if !ok {
+				writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid cursor")
+				return
+			}
+			start := sort.Search(len(order), func(i int) bool {
+				g := idx.Games[order[i]]
+				name := normalizeTitle(g.Name)
+				return name > cursor.Name || (name == cursor.Name && g.ID > cursor.ID)
+			})
+			order = order[start:]
+		}
+
+		resp := CatalogResponse{Games: make([]*Game, 0, limit)}
+		for _, id := range matching {
+			if filter.Matches(idx.Games[id]) {
+				resp.Total++
+			}
+		}
+		for _, id := range order {
+			g := idx.Games[id]
+			if !filter.Matches(g) {
+				continue
+			}
+			if len(resp.Games) == limit {
+				resp.NextCursor = encodeCursor(resp.Games[limit-1])
+				break
+			}
+			resp.Games = append(resp.Games, g)
+		}
+
+		writeResponse(w, r, http.StatusOK, resp)
+	})
+}
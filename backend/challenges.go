@@ -0,0 +1,206 @@
+package guesser
+
+import (
+	"crypto/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A challenge freezes a finished session's puzzle (data, pool, secret and
+// mode) under a short code. Starting a session with the code replays the
+// same puzzle, so friends can race each other on it. Codes last
+// challengeTTL, and keep the dataset by ID and version rather than holding
+// on to its snapshot.
+
+// challengeAlphabet leaves out characters that are easy to misread
+// (0/O, 1/I/L) when codes are read aloud or typed from a screenshot.
+const challengeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const challengeCodeLength = 6
+
+const (
+	// challengeTTL is how long a seed code keeps working.
+	challengeTTL = 7 * 24 * time.Hour
+	// maxChallenges bounds the store; past it, expired codes are dropped,
+	// then the oldest half.
+	maxChallenges = 100_000
+)
+
+// Challenge is a frozen puzzle. Version is that of the dataset snapshot
+// it was played on.
+type Challenge struct {
+	Tenant     string
+	DatasetID  string
+	Version    uint64
+	Pool       []int
+	SecretID   int
+	Mode       SessionMode
+	Difficulty SessionDifficulty
+	CreatedAt  time.Time
+}
+
+type challengeStore struct {
+	mu         sync.RWMutex
+	challenges map[string]Challenge
+}
+
+func newChallengeStore() *challengeStore {
+	return &challengeStore{
+		challenges: make(map[string]Challenge),
+	}
+}
+
+// global in-memory challenge store
+var challenges = newChallengeStore()
+
+func randomChallengeCode() string {
+	buf := make([]byte, challengeCodeLength)
+	_, _ = rand.Read(buf)
+	for i, b := range buf {
+		buf[i] = challengeAlphabet[int(b)%len(challengeAlphabet)]
+	}
+	return string(buf)
+}
+
+// save stores c under a new code and returns the code.
+func (s *challengeStore) save(c Challenge) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c.CreatedAt = time.Now()
+	if len(s.challenges) >= maxChallenges {
+		s.forgetLocked(c.CreatedAt)
+	}
+	for {
+		code := randomChallengeCode()
+		if _, taken := s.challenges[code]; !taken {
+			s.challenges[code] = c
+			return code
+		}
+	}
+}
+
+// get looks a code up for tenant; codes are only valid where they were
+// created.
+func (s *challengeStore) get(tenant, code string) (Challenge, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.challenges[code]
+	if !ok || c.Tenant != tenant || time.Since(c.CreatedAt) > challengeTTL {
+		return Challenge{}, false
+	}
+	return c, true
+}
+
+func (s *challengeStore) forgetLocked(now time.Time) {
+	for code, c := range s.challenges {
+		if now.Sub(c.CreatedAt) > challengeTTL {
+			delete(s.challenges, code)
+		}
+	}
+	if len(s.challenges) < maxChallenges {
+		return
+	}
+
+	codes := make([]string, 0, len(s.challenges))
+	for code := range s.challenges {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return s.challenges[codes[i]].CreatedAt.Before(s.challenges[codes[j]].CreatedAt) })
+	for _, code := range codes[:len(codes)/2] {
+		delete(s.challenges, code)
+	}
+}
+
+type ChallengeResponse struct {
+	Code string `json:"code"`
+}
+
+// handleChallenge shares a finished session as a seed code. Asking again
+// returns the same code.
+func handleChallenge(w http.ResponseWriter, r *http.Request, session *Session) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+
+	switch {
+	case session.State.Status != StatusFinished:
+		writeError(w, http.StatusConflict, CodeSessionNotFinished, "finish the game before sharing it")
+		return
+	case session.State.Mode == ModeReverse:
+		writeError(w, http.StatusConflict, CodeNotShareable, "reverse sessions have no secret to share")
+		return
+	case session.daily != (dailyKey{}):
+		writeError(w, http.StatusConflict, CodeNotShareable, "daily challenges cannot be shared as seeds")
+		return
+	}
+
+	if session.challengeCode == "" {
+		session.challengeCode = challenges.save(Challenge{
+			Tenant:     session.Tenant,
+			DatasetID:  session.DatasetID,
+			Version:    session.Snapshot.Version,
+			Pool:       session.Pool,
+			SecretID:   session.State.SecretID,
+			Mode:       session.State.Mode,
+			Difficulty: session.State.Difficulty,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, ChallengeResponse{Code: session.challengeCode})
+}
+
+// startChallenge is POST /api/v1/session/start with a seed code.
+func startChallenge(w http.ResponseWriter, r *http.Request, data *SnapshotHolder, req StartSessionRequest) {
+	if req.GameIDs != nil || req.Filter.YearFrom != 0 || req.Filter.YearTo != 0 ||
+		req.Filter.Platforms != nil || req.Filter.MainGenres != nil ||
+		req.Mode != "" || req.ForceSecretID != 0 || req.DatasetID != "" || req.Tolerance != 0 ||
+		req.Difficulty != "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "seed cannot be combined with other options")
+		return
+	}
+
+	c, ok := challenges.get(tenantID(r), strings.ToUpper(strings.TrimSpace(req.Seed)))
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeUnknownSeed, "unknown seed")
+		return
+	}
+	if !allowKeyDataset(w, r, c.DatasetID) {
+		return
+	}
+	// A reloaded dataset still replays the puzzle as long as its games are
+	// all there.
+	holder, ok := selectDataset(r, data, c.DatasetID)
+	if !ok {
+		writeError(w, http.StatusGone, CodeDatasetChanged, "the challenge's dataset has changed")
+		return
+	}
+	snap := holder.Current()
+	if snap.Version != c.Version && !hasGames(snap.Index, c.Pool) {
+		writeError(w, http.StatusGone, CodeDatasetChanged, "the challenge's dataset has changed")
+		return
+	}
+
+	state := NewSessionStateFromPool(c.Pool, c.SecretID)
+	state.Mode = c.Mode
+	state.Difficulty = c.Difficulty
+
+	session := store.create(c.Tenant, snap, state)
+	session.DatasetID = c.DatasetID
+
+	datasetID := c.DatasetID
+	if datasetID == "" {
+		datasetID = defaultDatasetID
+	}
+
+	resp := newStartResponse(snap, state, datasetID)
+	resp.SessionID = session.ID
+	resp.ClientToken = session.Token
+
+	writeResponse(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,133 @@
+package guesser
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPResolver works out the real client address for a request.
+// Forwarding headers are only honoured when the direct peer is a trusted
+// proxy, so clients cannot spoof their address by sending the headers
+// themselves.
+type ClientIPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewClientIPResolver parses a list of trusted proxy CIDRs. Bare IPs
+// (e.g. "10.0.0.1") are accepted and treated as a single-host range.
+func NewClientIPResolver(cidrs []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+
+	for _, raw := range cidrs {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		if !strings.Contains(raw, "/") {
+			ip := net.ParseIP(raw)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", raw)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			raw = fmt.Sprintf("%s/%d", ip, bits)
+		}
+
+		_, network, err := net.ParseCIDR(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", raw, err)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+
+	return resolver, nil
+}
+
+func (c *ClientIPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range c.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the client address for r.
+//
+// X-Forwarded-For is walked from right to left: every hop appended by a
+// trusted proxy is skipped and the first untrusted address is the client.
+// X-Real-IP is used as a fallback when no X-Forwarded-For is present.
+func (c *ClientIPResolver) ClientIP(r *http.Request) string {
+	peer := parseHostIP(r.RemoteAddr)
+	if peer == nil {
+		return r.RemoteAddr
+	}
+
+	if !c.isTrusted(peer) {
+		return peer.String()
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := parseHostIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				// Garbage in the chain: stop at the last address we could trust.
+				break
+			}
+			if !c.isTrusted(hop) {
+				return hop.String()
+			}
+			peer = hop
+		}
+
+		// Every hop was a trusted proxy; the left-most one is the best we have.
+		return peer.String()
+	}
+
+	if real := parseHostIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); real != nil {
+		return real.String()
+	}
+
+	return peer.String()
+}
+
+// parseHostIP accepts "ip", "ip:port" and "[ipv6]:port" forms.
+func parseHostIP(value string) net.IP {
+	if value == "" {
+		return nil
+	}
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+
+	return net.ParseIP(strings.Trim(value, "[]"))
+}
+
+// global resolver; trusts no proxies until SetTrustedProxies is called
+var ipResolver = &ClientIPResolver{}
+
+// SetTrustedProxies replaces the global proxy allow-list used by clientIP.
+func SetTrustedProxies(cidrs []string) error {
+	resolver, err := NewClientIPResolver(cidrs)
+	if err != nil {
+		return err
+	}
+
+	ipResolver = resolver
+	return nil
+}
+
+// clientIP is the address used for per-client bookkeeping such as rate
+// limits and session caps.
+func clientIP(r *http.Request) string {
+	return ipResolver.ClientIP(r)
+}
@@ -0,0 +1,216 @@
+package guesser
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is everything the server is started with. Each setting is
+// defined once, as a flag; the same name works as a key in the -config
+// YAML file and, upper-cased with a GUESSER_ prefix, as an environment
+// variable (e.g. session-ttl, GUESSER_SESSION_TTL). The command line
+// beats the environment, which beats the file.
+//
+// Secrets (ADMIN_TOKEN, STEAM_API_KEY, ...) stay in their own variables.
+type Config struct {
+	Listen          string
+	StaticDir       string
+	Dataset         string
+	Datasets        string
+	Collisions      string
+	DatasetRefresh  time.Duration
+	Templates       string
+	Tenants         string
+	APIKeys         string
+	SessionsFile    string
+	ImageCacheDir   string
+	ImageCacheMB    int
+	LogLevel        string
+	Debug           bool
+	SecretSeed      int64
+	DeriveValues    bool
+	StrictTemplates bool
+
+	SessionTTL      time.Duration
+	ShutdownTimeout time.Duration
+
+	TrustedProxies []string
+	PubSubURL      string
+	WebhookURLs    []string
+
+	CORS       CORSConfig
+	Rules      Rules
+	RateLimits IPRateLimits
+
+	// Args are what's left after the flags, e.g. an offline tool's name.
+	Args []string
+}
+
+// envPrefix turns a flag name into its environment variable.
+const envPrefix = "GUESSER_"
+
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// listFlag is a comma-separated flag. Set replaces the list, so a value
+// can be applied again from another source.
+type listFlag struct{ list *[]string }
+
+func (f listFlag) String() string {
+	if f.list == nil {
+		return ""
+	}
+	return strings.Join(*f.list, ",")
+}
+
+func (f listFlag) Set(value string) error {
+	*f.list = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f.list = append(*f.list, item)
+		}
+	}
+	return nil
+}
+
+func newConfigFlags(cfg *Config) *flag.FlagSet {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+
+	fs.StringVar(&cfg.Listen, "listen", ":9000", "address to serve on")
+	fs.StringVar(&cfg.StaticDir, "static-dir", "", "serve the frontend from this directory instead of the embedded build (development)")
+	fs.StringVar(&cfg.Dataset, "dataset", "../dataset/games.json", "path or http(s) URL of games.json, a directory of game packs to merge, or an SQLite catalog (.db) made with export-sqlite")
+	fs.StringVar(&cfg.Collisions, "dataset-collisions", "error",
+		"when files in a dataset directory reuse a game ID: error, skip (keep the first) or remap (renumber the later game)")
+	fs.DurationVar(&cfg.DatasetRefresh, "dataset-refresh", 0,
+		"how often to check datasets loaded from an http(s) URL for changes, e.g. 10m (0 = only at startup)")
+	fs.StringVar(&cfg.Datasets, "datasets", "", "optional JSON file of extra datasets sessions can pick")
+	fs.StringVar(&cfg.Templates, "templates", "", "optional JSON or YAML file of question templates to add (or replace built-in ones by ID)")
+	fs.StringVar(&cfg.Tenants, "tenants", "", "optional JSON file of extra tenant catalogs")
+	fs.StringVar(&cfg.APIKeys, "api-keys", "", "optional JSON file of third-party API keys")
+	fs.StringVar(&cfg.SessionsFile, "sessions-file", "", "save sessions here on shutdown and restore them on start (empty = don't)")
+	fs.StringVar(&cfg.ImageCacheDir, "image-cache-dir", "", "proxy cover art through /api/v1/images, caching it here (empty = link to the image host)")
+	fs.IntVar(&cfg.ImageCacheMB, "image-cache-size", 512, "megabytes of cover art to keep in -image-cache-dir")
+	fs.StringVar(&cfg.LogLevel, "log-level", "info", "minimum log level: debug, info, warn or error")
+	fs.BoolVar(&cfg.Debug, "debug", false, "include engine internals (including the secret) in responses")
+	fs.Int64Var(&cfg.SecretSeed, "secret-seed", 0, "draw secrets from a source seeded with this, for reproducible demos and end-to-end tests (0 = random)")
+	fs.BoolVar(&cfg.DeriveValues, "derive-values", false, "take question options from the values each dataset actually has")
+	fs.BoolVar(&cfg.StrictTemplates, "strict-templates", false, "refuse datasets where a question option matches no games or every game")
+
+	fs.DurationVar(&cfg.SessionTTL, "session-ttl", 2*time.Hour, "evict sessions idle for this long (0 = never)")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", 15*time.Second, "how long to wait for in-flight requests on SIGTERM")
+
+	fs.Var(listFlag{&cfg.TrustedProxies}, "trusted-proxies",
+		"comma-separated CIDRs of the reverse proxies / load balancers in front of the server, e.g. 10.0.0.0/8,127.0.0.1")
+	fs.StringVar(&cfg.PubSubURL, "pubsub-url", "",
+		"cluster mode: share room state with other replicas over this pub/sub, e.g. redis://redis:6379/0 or nats://nats:4222")
+	fs.Var(listFlag{&cfg.WebhookURLs}, "webhook-urls", "comma-separated URLs that receive a signed POST when a game ends (signed with WEBHOOK_SECRET)")
+
+	fs.Var(listFlag{&cfg.CORS.Origins}, "cors-origins", "comma-separated origins allowed to call the API from a browser (* = any)")
+	fs.Var(listFlag{&cfg.CORS.Methods}, "cors-methods", "comma-separated methods allowed in CORS preflight answers")
+	fs.Var(listFlag{&cfg.CORS.Headers}, "cors-headers", "comma-separated request headers to allow beyond the API's own")
+	fs.DurationVar(&cfg.CORS.MaxAge, "cors-max-age", cfg.CORS.MaxAge, "how long browsers may cache a CORS preflight answer (0 = don't)")
+
+	fs.IntVar(&cfg.Rules.CandidateRevealThreshold, "reveal-threshold", cfg.Rules.CandidateRevealThreshold,
+		"max remaining candidates before their names may be listed (0 = never)")
+	fs.Float64Var(&cfg.Rules.GuessSimilarityThreshold, "guess-threshold", cfg.Rules.GuessSimilarityThreshold,
+		"how close (0-1) a typed guess must be to a title to count (1 = no typos)")
+	fs.IntVar(&cfg.Rules.MaxQuestions, "max-questions", cfg.Rules.MaxQuestions, "questions allowed per session before guessing (0 = unlimited)")
+	fs.IntVar(&cfg.Rules.MaxGuesses, "max-guesses", cfg.Rules.MaxGuesses, "guesses allowed per classic session (0 = unlimited)")
+	fs.IntVar(&cfg.Rules.EasySecrets, "easy-secrets", cfg.Rules.EasySecrets, "easy sessions draw the secret from this many of the most popular games")
+	fs.IntVar(&cfg.Rules.RecentSecrets, "recent-secrets", cfg.Rules.RecentSecrets, "new sessions avoid a client's last this many secrets (0 = off)")
+	fs.IntVar(&cfg.Rules.HintCost, "hint-cost", cfg.Rules.HintCost, "points each hint takes off the score of a win")
+
+	fs.IntVar(&cfg.RateLimits.SessionStart.RequestsPerMinute, "start-rate", cfg.RateLimits.SessionStart.RequestsPerMinute,
+		"sessions and rooms one IP may start per minute (0 = unlimited)")
+	fs.IntVar(&cfg.RateLimits.Play.RequestsPerMinute, "play-rate", cfg.RateLimits.Play.RequestsPerMinute,
+		"asks, guesses and answers one IP may send per minute (0 = unlimited)")
+
+	return fs
+}
+
+// LoadConfig builds the Config from args (without the program name), the
+// environment as seen through getenv, and the YAML file named by -config
+// or GUESSER_CONFIG.
+func LoadConfig(args []string, getenv func(string) string) (Config, error) {
+	cfg := Config{CORS: DefaultCORSConfig(), Rules: DefaultRules(), RateLimits: DefaultIPRateLimits()}
+	fs := newConfigFlags(&cfg)
+	configPath := fs.String("config", "", "optional YAML file of any of these settings, keyed by flag name")
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+	cfg.Args = fs.Args()
+
+	// Apply the file and environment underneath what was given on the
+	// command line, then put the command line back on top.
+	cmdline := map[string]string{}
+	fs.Visit(func(f *flag.Flag) { cmdline[f.Name] = f.Value.String() })
+
+	path := *configPath
+	if path == "" {
+		path = getenv(envName("config"))
+	}
+	if path != "" {
+		if err := applyConfigFile(fs, path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var envErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		if value := getenv(envName(f.Name)); value != "" && f.Name != "config" && envErr == nil {
+			if err := fs.Set(f.Name, value); err != nil {
+				envErr = fmt.Errorf("%s: %w", envName(f.Name), err)
+			}
+		}
+	})
+	if envErr != nil {
+		return Config{}, envErr
+	}
+
+	for name, value := range cmdline {
+		if err := fs.Set(name, value); err != nil {
+			return Config{}, err
+		}
+	}
+	return cfg, nil
+}
+
+// applyConfigFile sets the flags named in a YAML mapping. Lists become
+// comma-separated values.
+func applyConfigFile(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	for key, raw := range settings {
+		if key == "config" || fs.Lookup(key) == nil {
+			return fmt.Errorf("%s: unknown setting %q", path, key)
+		}
+
+		value := fmt.Sprint(raw)
+		if list, ok := raw.([]any); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			value = strings.Join(items, ",")
+		}
+		if err := fs.Set(key, value); err != nil {
+			return fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package guesser
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsRequestHeaders are the headers browser clients always need: the
+// JSON body, the session/API credentials and the CSRF token.
+var corsRequestHeaders = []string{
+	"Content-Type", "Accept", "Authorization",
+	sessionTokenHeader, statelessHeader, apiKeyHeader, csrfHeaderName,
+}
+
+// corsExposedHeaders are response headers scripts need to read.
+var corsExposedHeaders = strings.Join([]string{statelessHeader, "Retry-After"}, ", ")
+
+// CORSConfig says which browser pages may call the API.
+type CORSConfig struct {
+	// Origins may call the API; "*" allows any, but then without cookies.
+	Origins []string
+	// Methods are allowed in preflight answers.
+	Methods []string
+	// Headers are request headers allowed on top of the API's own.
+	Headers []string
+	// MaxAge is how long browsers may cache a preflight answer.
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig allows no origins; the API only has GET and POST
+// routes.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		Methods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		MaxAge:  10 * time.Minute,
+	}
+}
+
+// CORSMiddleware lets pages on cfg.Origins call the API from a browser and
+// answers their preflight requests. Requests from other origins get no
+// CORS headers, so browsers keep blocking them.
+func CORSMiddleware(cfg CORSConfig) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(cfg.Origins))
+	for _, o := range cfg.Origins {
+		allowed[strings.TrimRight(o, "/")] = true
+	}
+	methods := strings.Join(cfg.Methods, ", ")
+	headers := strings.Join(append(append([]string(nil), corsRequestHeaders...), cfg.Headers...), ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || (!allowed[origin] && !allowed["*"]) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if allowed[origin] {
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Credentials", "true")
+			} else {
+				h.Set("Access-Control-Allow-Origin", "*")
+			}
+			h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				if cfg.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", maxAge)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
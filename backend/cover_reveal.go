@@ -0,0 +1,117 @@
+package guesser
+
+import (
+	"context"
+	"fmt"
+	"image/jpeg"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// The secret's cover can be watched coming into focus: GET
+// /api/v1/session/{id}/cover is a pixelated copy that gets sharper with
+// every question, guess and (twice as fast) hint, and the cover itself
+// once the game is over. The copies are made here from the image cache,
+// so the client never sees the cover's URL, or even the game's ID,
+// before the end.
+
+// coverRevealDetail is how many pixels wide the cover is pixelated to at
+// each stage. However much is spent, the last stage is still blurry.
+var coverRevealDetail = []int{6, 8, 11, 14, 18, 23, 29, 36, 45, 56}
+
+// coverRevealWidth is how wide every stage, and the final cover, is served.
+const coverRevealWidth = 240
+
+// coverStage is how far state's cover has come into focus: an index into
+// coverRevealDetail, or -1 for the cover itself.
+func coverStage(state SessionState) int {
+	if state.Status == StatusFinished {
+		return -1
+	}
+	spent := len(state.Asked) + len(state.Guesses) + 2*len(state.Hints)
+	return min(spent, len(coverRevealDetail)-1)
+}
+
+// openCoverStage returns g's cover at stage (see coverStage) as a JPEG,
+// making and caching it first if needed.
+func openCoverStage(ctx context.Context, g *Game, stage int) (*os.File, error) {
+	key := imageKey(g.ID, g.ImageURL)
+	if stage < 0 {
+		return images.openVariant(ctx, key, g.ImageURL, coverRevealWidth, "jpeg")
+	}
+
+	detail := coverRevealDetail[stage]
+	variant := fmt.Sprintf("%s-px%d.jpeg", key, detail)
+	err := images.fill(ctx, variant, func(dst io.Writer) error {
+		src, err := images.open(context.Background(), key, g.ImageURL)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		img, err := decodeCover(src)
+		if err != nil {
+			return err
+		}
+		return jpeg.Encode(dst, obscureCover(img, detail, coverRevealWidth, xdraw.NearestNeighbor), &jpeg.Options{Quality: 80})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Join(images.dir, variant))
+}
+
+// coverAvailable reports whether g's cover can be served obscured.
+func coverAvailable(g *Game) bool {
+	return images != nil && g.ImageURL != ""
+}
+
+func handleCover(w http.ResponseWriter, r *http.Request, session *Session, idx GameIndex) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w)
+		return
+	}
+
+	if session.State.Mode == ModeReverse {
+		writeError(w, http.StatusConflict, CodeWrongMode, "in reverse mode the secret is yours: there is no cover to reveal")
+		return
+	}
+	secret, ok := idx.Games[session.State.SecretID]
+	if !ok {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "secret game not found")
+		return
+	}
+	if images == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "the image cache is not enabled")
+		return
+	}
+	if !coverAvailable(secret) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "the game has no image")
+		return
+	}
+
+	stage := coverStage(session.State)
+	f, err := openCoverStage(r.Context(), secret, stage)
+	if err != nil {
+		slog.Warn("cover reveal failed", "game", secret.ID, "stage", stage, "err", err)
+		writeError(w, http.StatusBadGateway, CodeUpstream, "could not fetch the cover")
+		return
+	}
+	defer f.Close()
+
+	// The same URL sharpens as the game goes on, and the file name would
+	// give the game away, so there is no ETag and nothing is cached.
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Cover-Stage", strconv.Itoa(stage))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		io.Copy(w, f)
+	}
+}
@@ -0,0 +1,231 @@
+package guesser
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The daily challenge gives every player the same secret for a UTC day.
+// Each client gets one attempt: starting again resumes the running game,
+// and once it has ended the result is all that's left to see.
+
+// clientCookieName identifies a client across daily challenges and, for
+// recent secrets, across games.
+const clientCookieName = "gg_client"
+
+const dailyDateLayout = "2006-01-02"
+
+// DailyResult is how one client did on one day's challenge.
+type DailyResult struct {
+	Date        string    `json:"date"`
+	Outcome     Outcome   `json:"outcome"`
+	Questions   int       `json:"questions"`
+	Guesses     int       `json:"guesses"`
+	Score       int       `json:"score"`
+	CompletedAt time.Time `json:"completedAt"`
+	// Streak is the client's streak with this day counted.
+	Streak DailyStreak `json:"streak"`
+}
+
+type dailyKey struct {
+	Tenant string
+	Date   string
+	Client string
+}
+
+// dailyResultDays is how many days of results are kept: today's, and
+// yesterday's for attempts started before midnight. Streaks carry
+// everything older.
+const dailyResultDays = 2
+
+// dailyStore keeps daily attempts apart from the session store, so
+// results outlive the sessions that produced them.
+type dailyStore struct {
+	mu       sync.Mutex
+	sessions map[dailyKey]string // running attempt's session ID
+	results  map[dailyKey]DailyResult
+	streaks  map[streakKey]streakRecord
+	// prunedOn is the day results were last pruned.
+	prunedOn string
+}
+
+func newDailyStore() *dailyStore {
+	return &dailyStore{
+		sessions: make(map[dailyKey]string),
+		results:  make(map[dailyKey]DailyResult),
+		streaks:  make(map[streakKey]streakRecord),
+	}
+}
+
+// pruneLocked drops the results and abandoned attempts of days before
+// the last dailyResultDays, once per day. daily.mu must be held.
+func (d *dailyStore) pruneLocked(today string) {
+	if d.prunedOn == today {
+		return
+	}
+	d.prunedOn = today
+
+	oldest := addDailyDays(today, 1-dailyResultDays)
+	for key := range d.results {
+		if key.Date < oldest {
+			delete(d.results, key)
+		}
+	}
+	for key := range d.sessions {
+		if key.Date < oldest {
+			delete(d.sessions, key)
+		}
+	}
+}
+
+// global in-memory daily challenge store
+var daily = newDailyStore()
+
+// dailySeedKey keys the daily seeds; see ConfigureDailySecret.
+var dailySeedKey = []byte(randomToken(32))
+
+// ConfigureDailySecret sets the key the daily secrets are derived with.
+// Every replica must use the same one. Left unset, each process makes up
+// its own, so nobody can work out tomorrow's secret from the public
+// catalog, but replicas (and restarts) disagree on today's.
+func ConfigureDailySecret(key string) error {
+	if len(key) < 16 {
+		return errors.New("key must be at least 16 characters")
+	}
+	dailySeedKey = []byte(key)
+	return nil
+}
+
+// dailySecretID derives the day's secret from the date and the daily
+// key, so every player (and every replica) agrees on it without
+// coordination. It is drawn like any secret, popular games more often,
+// from a picker seeded with an HMAC of the date.
+func dailySecretID(idx GameIndex, date string) int {
+	mac := hmac.New(sha256.New, dailySeedKey)
+	mac.Write([]byte("daily:" + date))
+	seed := int64(binary.BigEndian.Uint64(mac.Sum(nil)[:8]))
+	return RandomSecret(SeededPicker(seed), idx, idx.ByName)
+}
+
+// recordDailyResult stores the outcome when session was a daily attempt.
+func recordDailyResult(session *Session, recap Recap) {
+	if session.daily == (dailyKey{}) {
+		return
+	}
+
+	daily.mu.Lock()
+	defer daily.mu.Unlock()
+
+	delete(daily.sessions, session.daily)
+	result := DailyResult{
+		Date:        session.daily.Date,
+		Outcome:     recap.Outcome,
+		Questions:   len(recap.Questions),
+		Guesses:     len(recap.Guesses),
+		Score:       recap.Score,
+		CompletedAt: session.State.FinishedAt,
+	}
+	result.Streak = daily.recordStreakLocked(session.daily)
+	daily.results[session.daily] = result
+}
+
+// clientID returns the caller's client ID, issuing a cookie on first
+// contact.
+func clientID(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(clientCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	id := randomToken(16)
+	http.SetCookie(w, &http.Cookie{
+		Name:     clientCookieName,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   400 * 24 * 60 * 60,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+	})
+	return id
+}
+
+type DailyStartResponse struct {
+	Date string `json:"date"`
+	// Streak is the client's streak going into today.
+	Streak DailyStreak `json:"streak"`
+	StartSessionResponse
+}
+
+type DailyCompletedResponse struct {
+	ErrorResponse
+	Result DailyResult `json:"result"`
+}
+
+// ---------------------------------
+// /api/v1/daily/start   (POST)
+// ---------------------------------
+
+func DailyStartHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+
+		key := dailyKey{
+			Tenant: tenantID(r),
+			Date:   time.Now().UTC().Format(dailyDateLayout),
+			Client: clientID(w, r),
+		}
+
+		daily.mu.Lock()
+		daily.pruneLocked(key.Date)
+		if result, done := daily.results[key]; done {
+			daily.mu.Unlock()
+			writeJSON(w, http.StatusConflict, DailyCompletedResponse{
+				ErrorResponse: newErrorResponse(CodeDailyCompleted, "today's challenge is already completed"),
+				Result:        result,
+			})
+			return
+		}
+
+		// Resume the running attempt, unless it has expired.
+		var session *Session
+		if id, ok := daily.sessions[key]; ok {
+			if s, err := store.get(id); err == nil {
+				session = s
+			}
+		}
+
+		if session == nil {
+			snap := data.Current()
+			state := NewSessionStateFromPool(snap.Index.AllGameIDs, dailySecretID(snap.Index, key.Date))
+			session = store.create(key.Tenant, snap, state)
+			session.daily = key
+			daily.sessions[key] = session.ID
+		}
+		streak := daily.streakLocked(key)
+		// completeSession takes daily.mu while holding session.mu, so
+		// never hold both the other way round.
+		daily.mu.Unlock()
+
+		session.mu.Lock()
+		state := session.State
+		session.mu.Unlock()
+
+		start := newStartResponse(session.Snapshot, state, defaultDatasetID)
+		start.SessionID = session.ID
+		start.ClientToken = session.Token
+
+		writeResponse(w, r, http.StatusOK, DailyStartResponse{
+			Date:                 key.Date,
+			Streak:               streak,
+			StartSessionResponse: start,
+		})
+	})
+}
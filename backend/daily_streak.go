@@ -0,0 +1,101 @@
+package guesser
+
+import "time"
+
+// A daily streak is the run of consecutive days a client has completed
+// the challenge, won or not. Days are the UTC dates the challenge is
+// keyed by, and an attempt counts for the day it was started on, so
+// finishing after midnight, or from another time zone, doesn't break it.
+
+// DailyStreak is a client's current and longest runs of completed days.
+// Current is 0 once a day has been missed.
+type DailyStreak struct {
+	Current  int    `json:"current"`
+	Best     int    `json:"best"`
+	LastDate string `json:"lastDate,omitempty"`
+}
+
+type streakKey struct {
+	Tenant string
+	Client string
+}
+
+// addDailyDays is the date days after date, or "" when date doesn't parse.
+func addDailyDays(date string, days int) string {
+	t, err := time.Parse(dailyDateLayout, date)
+	if err != nil {
+		return ""
+	}
+	return t.AddDate(0, 0, days).Format(dailyDateLayout)
+}
+
+// dailyRun is a run of Days consecutive completed days ending on Last.
+type dailyRun struct {
+	Days int    `json:"days"`
+	Last string `json:"last"`
+}
+
+func (r dailyRun) first() string { return addDailyDays(r.Last, 1-r.Days) }
+
+// extend adds date to r if it is the day after r or the day before it.
+func (r *dailyRun) extend(date string) bool {
+	switch {
+	case r.Days == 0:
+		return false
+	case date == addDailyDays(r.Last, 1):
+		r.Last = date
+	case date == addDailyDays(r.first(), -1):
+	default:
+		return false
+	}
+	r.Days++
+	return true
+}
+
+// streakRecord is what's kept per client: the running and longest runs,
+// and the run before the running one, so that a day between the two
+// completed late (yesterday's attempt finished after today's) joins them
+// up without the past results being kept.
+type streakRecord struct {
+	current, previous dailyRun
+	best              int
+}
+
+func (rec streakRecord) streak() DailyStreak {
+	return DailyStreak{Current: rec.current.Days, Best: rec.best, LastDate: rec.current.Last}
+}
+
+// recordStreakLocked updates the streak of key's client now that key's
+// day is completed. daily.mu must be held.
+func (d *dailyStore) recordStreakLocked(key dailyKey) DailyStreak {
+	sk := streakKey{Tenant: key.Tenant, Client: key.Client}
+	rec := d.streaks[sk]
+	cur, prev := &rec.current, &rec.previous
+
+	switch date := key.Date; {
+	case cur.Days > 0 && date >= cur.first() && date <= cur.Last:
+	case cur.Days == 0 || date > addDailyDays(cur.Last, 1):
+		rec.previous, rec.current = rec.current, dailyRun{Days: 1, Last: date}
+	case cur.extend(date):
+		if prev.Days > 0 && cur.first() == addDailyDays(prev.Last, 1) {
+			cur.Days += prev.Days
+			*prev = dailyRun{}
+		}
+	case prev.extend(date):
+	}
+	rec.best = max(rec.best, cur.Days, prev.Days)
+
+	d.streaks[sk] = rec
+	return rec.streak()
+}
+
+// streakLocked is the streak of key's client as of key's day: still
+// current if the last completed day was that day or the one before.
+// daily.mu must be held.
+func (d *dailyStore) streakLocked(key dailyKey) DailyStreak {
+	streak := d.streaks[streakKey{Tenant: key.Tenant, Client: key.Client}].streak()
+	if streak.LastDate != key.Date && streak.LastDate != addDailyDays(key.Date, -1) {
+		streak.Current = 0
+	}
+	return streak
+}
@@ -0,0 +1,50 @@
+package guesser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDailySecretID(t *testing.T) {
+	saved := dailySeedKey
+	defer func() { dailySeedKey = saved }()
+	idx := testIndex()
+
+	if err := ConfigureDailySecret("short"); err == nil {
+		t.Error("ConfigureDailySecret accepted a 5-character key")
+	}
+	if err := ConfigureDailySecret("0123456789abcdef"); err != nil {
+		t.Fatalf("ConfigureDailySecret: %v", err)
+	}
+
+	dates := []string{"2026-01-01", "2026-01-02", "2026-01-03", "2026-01-04", "2026-01-05", "2026-01-06", "2026-01-07", "2026-01-08"}
+	first := make([]int, len(dates))
+	for i, date := range dates {
+		first[i] = dailySecretID(idx, date)
+		if !slices.Contains(idx.AllGameIDs, first[i]) {
+			t.Fatalf("%s: secret %d not in the catalog", date, first[i])
+		}
+		if again := dailySecretID(idx, date); again != first[i] {
+			t.Errorf("%s: drew %d, then %d", date, first[i], again)
+		}
+	}
+	distinct := map[int]bool{}
+	for _, id := range first {
+		distinct[id] = true
+	}
+	if len(distinct) == 1 {
+		t.Errorf("every date drew game %d", first[0])
+	}
+
+	// Another key gives another sequence.
+	if err := ConfigureDailySecret("fedcba9876543210"); err != nil {
+		t.Fatalf("ConfigureDailySecret: %v", err)
+	}
+	other := make([]int, len(dates))
+	for i, date := range dates {
+		other[i] = dailySecretID(idx, date)
+	}
+	if slices.Equal(first, other) {
+		t.Errorf("both keys drew %v", first)
+	}
+}
@@ -1,20 +1,124 @@
 package guesser
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"strings"
 )
 
+// ErrEmptyDataset is returned when a dataset parses but contains no games.
+var ErrEmptyDataset = errors.New("dataset contains no games")
+
+// Games must have a positive, unique ID, a name and a release year in
+// this range.
+const (
+	minGameYear = 1950
+	maxGameYear = 2100
+)
+
+// LoadGamesJSON reads a games.json file: a JSON array of Game.
 func LoadGamesJSON(path string) ([]Game, error) {
-	data, err := os.ReadFile(path)
+	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
+
+	games, err := ReadGames(bufio.NewReaderSize(f, 1<<16))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return games, nil
+}
+
+// ReadGames decodes a JSON array of Game one record at a time, so the
+// document is never held in memory as a whole, and checks each record as
+// it goes. Errors name the record: "game #1234 (id 5678): invalid year 0".
+func ReadGames(r io.Reader) ([]Game, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, errors.New("expected a JSON array of games")
+	}
 
 	var games []Game
-	if err := json.Unmarshal(data, &games); err != nil {
-		return nil, err
+	seen := map[int]int{}
+	interner := stringInterner{}
+	for n := 1; dec.More(); n++ {
+		var g Game
+		if err := dec.Decode(&g); err != nil {
+			return nil, fmt.Errorf("game #%d: %w", n, err)
+		}
+		if err := validateGame(g); err != nil {
+			return nil, fmt.Errorf("game #%d (id %d): %w", n, g.ID, err)
+		}
+		if first, ok := seen[g.ID]; ok {
+			return nil, fmt.Errorf("game #%d: id %d is already used by game #%d", n, g.ID, first)
+		}
+		seen[g.ID] = n
+
+		interner.game(&g)
+		games = append(games, g)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("after game #%d: %w", len(games), err)
 	}
 
+	if len(games) == 0 {
+		return nil, ErrEmptyDataset
+	}
 	return games, nil
 }
+
+func validateGame(g Game) error {
+	switch {
+	case g.ID <= 0:
+		return fmt.Errorf("invalid id %d", g.ID)
+	case strings.TrimSpace(g.Name) == "":
+		return errors.New("name is empty")
+	case g.Year < minGameYear || g.Year > maxGameYear:
+		return fmt.Errorf("invalid year %d", g.Year)
+	}
+	return nil
+}
+
+// stringInterner makes games share one copy of each repeated string.
+// Decoding gives every game its own "PlayStation", "Action", "Unknown",
+// ...
+type stringInterner map[string]string
+
+// internStrings interns a whole list.
+func internStrings(games []Game) {
+	interner := stringInterner{}
+	for i := range games {
+		interner.game(&games[i])
+	}
+}
+
+func (in stringInterner) game(g *Game) {
+	v := reflect.ValueOf(g).Elem()
+	for _, index := range gameFields {
+		f := v.FieldByIndex(index)
+		switch {
+		case f.Kind() == reflect.String:
+			in.intern(f)
+		case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String:
+			for j := 0; j < f.Len(); j++ {
+				in.intern(f.Index(j))
+			}
+		}
+	}
+}
+
+func (in stringInterner) intern(v reflect.Value) {
+	s := v.String()
+	if shared, ok := in[s]; ok {
+		v.SetString(shared)
+		return
+	}
+	in[s] = s
+}
@@ -0,0 +1,332 @@
+package guesser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Curators edit the catalog in spreadsheets, so it converts to and from
+// CSV without losing anything:
+//
+//	guesser export csv games.csv
+//	guesser import csv games.csv games.json
+//
+// There is one column per games.json field, named and ordered as in Game.
+// Lists are one cell with the values separated by "|"; a literal "|" or
+// "\" in a value is escaped with "\". Booleans are true/false. An empty
+// cell is an empty list, "", false or 0, so columns can be left out of an
+// import altogether.
+
+// csvListSeparator separates list values in a cell.
+const csvListSeparator = '|'
+
+// gameColumns are the CSV columns: Game's JSON fields in struct order.
+var gameColumns = func() []string {
+	var columns []string
+	t := reflect.TypeOf(Game{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}()
+
+// WriteGamesCSV writes games as CSV with a header row.
+func WriteGamesCSV(w io.Writer, games []Game) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(gameColumns); err != nil {
+		return err
+	}
+	row := make([]string, len(gameColumns))
+	for i := range games {
+		v := reflect.ValueOf(&games[i]).Elem()
+		for c, column := range gameColumns {
+			row[c] = formatCSVCell(v.FieldByIndex(gameFields[column]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSVCell(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice:
+		var b strings.Builder
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteRune(csvListSeparator)
+			}
+			for _, r := range v.Index(i).String() {
+				if r == csvListSeparator || r == '\\' {
+					b.WriteByte('\\')
+				}
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	default:
+		return v.String()
+	}
+}
+
+// ReadGamesCSV reads what WriteGamesCSV writes and checks each game as
+// ReadGames does. Errors name the line: "line 12 (id 5678): invalid year 0".
+func ReadGamesCSV(r io.Reader) ([]Game, error) {
+	br := bufio.NewReader(r)
+	// Spreadsheets like to start UTF-8 files with a byte order mark.
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+
+	cr := csv.NewReader(br)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	indexes := make([][]int, len(header))
+	for c, column := range header {
+		index, ok := gameFields[strings.TrimSpace(column)]
+		if !ok {
+			return nil, fmt.Errorf("header: %q is not a game field", column)
+		}
+		indexes[c] = index
+	}
+
+	var games []Game
+	seen := map[int]int{}
+	interner := stringInterner{}
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		var g Game
+		v := reflect.ValueOf(&g).Elem()
+		for c, cell := range row {
+			if err := parseCSVCell(v.FieldByIndex(indexes[c]), cell); err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", line, header[c], err)
+			}
+		}
+		if err := validateGame(g); err != nil {
+			return nil, fmt.Errorf("line %d (id %d): %w", line, g.ID, err)
+		}
+		if first, ok := seen[g.ID]; ok {
+			return nil, fmt.Errorf("line %d: id %d is already used on line %d", line, g.ID, first)
+		}
+		seen[g.ID] = line
+
+		interner.game(&g)
+		games = append(games, g)
+	}
+
+	if len(games) == 0 {
+		return nil, ErrEmptyDataset
+	}
+	return games, nil
+}
+
+func parseCSVCell(v reflect.Value, cell string) error {
+	switch v.Kind() {
+	case reflect.Int:
+		if cell == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(cell))
+		if err != nil {
+			return fmt.Errorf("%q is not a number", cell)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		if cell == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(cell))
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", cell)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if cell == "" {
+			return nil
+		}
+		v.Set(reflect.ValueOf(splitCSVList(cell)))
+	default:
+		v.SetString(cell)
+	}
+	return nil
+}
+
+// splitCSVList splits a list cell on unescaped separators.
+func splitCSVList(cell string) []string {
+	var values []string
+	var b strings.Builder
+	escaped := false
+	for _, r := range cell {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == csvListSeparator:
+			values = append(values, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(values, b.String())
+}
+
+// WriteGamesJSON writes games the way the dataset builders do: indented,
+// fields in Game order, optional fields left out while empty. It writes
+// to a temporary file first so a failed write leaves path as it was.
+func WriteGamesJSON(path string, games []Game) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	buf.WriteByte('[')
+	for i := range games {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		v := reflect.ValueOf(&games[i]).Elem()
+		first := true
+		for _, column := range gameColumns {
+			fv := v.FieldByIndex(gameFields[column])
+			if optionalGameFields[column] && fv.IsZero() {
+				continue
+			}
+			var value any = fv.Interface()
+			if fv.Kind() == reflect.Slice && fv.IsNil() {
+				value = []string{}
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			enc.Encode(column)
+			buf.WriteByte(':')
+			if err := enc.Encode(value); err != nil {
+				return fmt.Errorf("game %d: %s: %w", games[i].ID, column, err)
+			}
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RunExport implements "export": the loaded dataset as CSV, or as an
+// SQLite catalog like export-sqlite. A CSV path of "-" is stdout.
+func RunExport(args []string, out io.Writer, games []Game) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(out)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(out, "usage: export csv|sqlite <path>")
+		return 2
+	}
+
+	format, path := fs.Arg(0), fs.Arg(1)
+	switch format {
+	case "sqlite":
+		return RunExportSQLite([]string{path}, out, games)
+	case "csv":
+	default:
+		fmt.Fprintf(out, "export: unknown format %q (want csv or sqlite)\n", format)
+		return 2
+	}
+
+	if path == "-" {
+		if err := WriteGamesCSV(out, games); err != nil {
+			fmt.Fprintf(out, "export: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	f, err := os.Create(path)
+	if err == nil {
+		err = WriteGamesCSV(f, games)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(out, "export: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "wrote %d games to %s\n", len(games), path)
+	return 0
+}
+
+// RunImport implements "import": a CSV written by export (or edited from
+// one) back to games.json.
+func RunImport(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(out)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 3 || fs.Arg(0) != "csv" {
+		fmt.Fprintln(out, "usage: import csv <games.csv> <games.json>")
+		return 2
+	}
+	in, path := fs.Arg(1), fs.Arg(2)
+
+	f, err := os.Open(in)
+	if err != nil {
+		fmt.Fprintf(out, "import: %v\n", err)
+		return 1
+	}
+	games, err := ReadGamesCSV(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(out, "import %s: %v\n", in, err)
+		return 1
+	}
+
+	if err := WriteGamesJSON(path, games); err != nil {
+		fmt.Fprintf(out, "import: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "wrote %d games to %s\n", len(games), path)
+	return 0
+}
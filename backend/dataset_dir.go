@@ -0,0 +1,113 @@
+package guesser
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A -dataset directory holds the core games.json next to community game
+// packs: every *.json file in it, read in name order and merged into one
+// catalog. Packs are written independently, so their IDs can collide;
+// -dataset-collisions says what happens then.
+
+// CollisionPolicy is what to do with a game whose ID an earlier file in
+// the directory already used.
+type CollisionPolicy string
+
+const (
+	// CollisionError refuses the directory.
+	CollisionError CollisionPolicy = "error"
+	// CollisionSkip keeps the earlier game and drops the later one.
+	CollisionSkip CollisionPolicy = "skip"
+	// CollisionRemap gives the later game a new ID, derived from its file
+	// name and original ID so that it normally stays the same from one
+	// load to the next, whatever other packs are added.
+	CollisionRemap CollisionPolicy = "remap"
+)
+
+// collisionPolicy is set by ConfigureDatasetCollisions.
+var collisionPolicy = CollisionError
+
+// ConfigureDatasetCollisions sets how dataset directories merge colliding
+// IDs. Call it before loading datasets.
+func ConfigureDatasetCollisions(policy string) error {
+	switch p := CollisionPolicy(policy); p {
+	case CollisionError, CollisionSkip, CollisionRemap:
+		collisionPolicy = p
+		return nil
+	default:
+		return fmt.Errorf("unknown collision policy %q (want error, skip or remap)", policy)
+	}
+}
+
+// Remapped IDs live above any hand-picked one.
+const (
+	remapIDBase  = 1 << 30
+	remapIDRange = 1 << 30
+)
+
+// DirSource is a directory of games.json files, merged.
+type DirSource struct {
+	Path string
+}
+
+func (s DirSource) Games() ([]Game, error) {
+	files, err := filepath.Glob(filepath.Join(s.Path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no .json files", s.Path)
+	}
+
+	var games []Game
+	from := map[int]string{}
+	for _, file := range files {
+		pack, err := LoadGamesJSON(file)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(file)
+		for _, g := range pack {
+			if first, ok := from[g.ID]; ok {
+				switch collisionPolicy {
+				case CollisionSkip:
+					continue
+				case CollisionRemap:
+					g.ID = remapID(name, g.ID, from)
+				default:
+					return nil, fmt.Errorf("%s: game id %d (%s) is already used by %s", file, g.ID, g.Name, first)
+				}
+			}
+			from[g.ID] = name
+			games = append(games, g)
+		}
+	}
+
+	if len(games) == 0 {
+		return nil, ErrEmptyDataset
+	}
+	return games, nil
+}
+
+// remapID picks a free ID for game id of file: a hash of both, moved on
+// past any ID already taken.
+func remapID(file string, id int, taken map[int]string) int {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(file) + "#" + strconv.Itoa(id)))
+	n := int(h.Sum32() % remapIDRange)
+	for {
+		candidate := remapIDBase + n
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+		n = (n + 1) % remapIDRange
+	}
+}
+
+func (DirSource) Close() error { return nil }
@@ -0,0 +1,82 @@
+package guesser
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DatasetSchemaVersion is the version of the game record format (Game's
+// JSON fields). It goes up when a change would break a client that reads
+// games, not when fields are added.
+const DatasetSchemaVersion = 1
+
+// DatasetMeta describes the snapshot a dataset serves. Hash changes
+// whenever the games or question options do, so clients can poll this
+// (conditionally, with If-None-Match) to know when to refetch them.
+type DatasetMeta struct {
+	ID            string    `json:"id"`
+	Version       uint64    `json:"version"`
+	GameCount     int       `json:"gameCount"`
+	TemplateCount int       `json:"templateCount"`
+	LoadedAt      time.Time `json:"loadedAt"`
+	SchemaVersion int       `json:"schemaVersion"`
+	Hash          string    `json:"hash"`
+}
+
+// ---------------------------------
+// /api/v1/dataset   (GET)
+// ---------------------------------
+
+func DatasetMetaHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		datasetID := r.URL.Query().Get("dataset")
+		if !allowKeyDataset(w, r, datasetID) {
+			return
+		}
+		holder, ok := selectDataset(r, data, datasetID)
+		if !ok {
+			writeError(w, http.StatusNotFound, CodeUnknownDataset, "unknown dataset")
+			return
+		}
+		if datasetID == "" {
+			datasetID = defaultDatasetID
+		}
+		snap := holder.Current()
+
+		etag := `"` + snap.ContentHash() + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, DatasetMeta{
+			ID:            datasetID,
+			Version:       snap.Version,
+			GameCount:     len(snap.Index.Games),
+			TemplateCount: len(snap.Templates),
+			LoadedAt:      snap.LoadedAt.UTC(),
+			SchemaVersion: DatasetSchemaVersion,
+			Hash:          "sha256:" + snap.ContentHash(),
+		})
+	})
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, weakly
+// compared as RFC 9110 asks for GET.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
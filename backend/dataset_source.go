@@ -0,0 +1,55 @@
+package guesser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DatasetSource is where a catalog's games come from. Games reads the
+// whole catalog, which the engine indexes in memory.
+type DatasetSource interface {
+	Games() ([]Game, error)
+	Close() error
+}
+
+// OpenDatasetSource opens path by its extension: .db, .sqlite or .sqlite3
+// for an SQLite catalog (see SQLiteSource), anything else for games.json.
+// A directory is a core dataset plus game packs (see DirSource), and an
+// http(s) URL a games.json to download (see URLSource).
+func OpenDatasetSource(path string) (DatasetSource, error) {
+	if isDatasetURL(path) {
+		return URLSource{URL: path}, nil
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return DirSource{Path: path}, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return OpenSQLiteSource(path)
+	default:
+		return JSONSource{Path: path}, nil
+	}
+}
+
+// LoadGames reads every game from the catalog at path, whichever kind of
+// source it is.
+func LoadGames(path string) ([]Game, error) {
+	src, err := OpenDatasetSource(path)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return src.Games()
+}
+
+// JSONSource is a games.json file.
+type JSONSource struct {
+	Path string
+}
+
+func (s JSONSource) Games() ([]Game, error) {
+	return LoadGamesJSON(s.Path)
+}
+
+func (JSONSource) Close() error { return nil }
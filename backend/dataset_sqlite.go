@@ -0,0 +1,184 @@
+package guesser
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// An SQLite catalog keeps each game as its games.json record, next to
+// columns and attribute rows that other tools can filter on with plain
+// SQL. "export-sqlite" writes one from games.json.
+const sqliteSchema = `
+CREATE TABLE games (
+	id         INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	year       INTEGER NOT NULL,
+	main_genre TEXT NOT NULL,
+	data       TEXT NOT NULL -- the game as games.json has it
+);
+CREATE INDEX games_by_year ON games (year);
+
+-- One row per value of every list or scalar attribute, by JSON field
+-- name: ("platforms", "PC"), ("tone", "Dark"), ("esrb", "M"), ...
+CREATE TABLE game_attributes (
+	game_id INTEGER NOT NULL REFERENCES games (id),
+	field   TEXT NOT NULL,
+	value   TEXT NOT NULL COLLATE NOCASE
+);
+CREATE INDEX game_attributes_by_value ON game_attributes (field, value);
+`
+
+// SQLiteSource is a catalog in an SQLite database with sqliteSchema.
+type SQLiteSource struct {
+	db *sql.DB
+}
+
+// OpenSQLiteSource opens an existing catalog read-only.
+func OpenSQLiteSource(path string) (*SQLiteSource, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return &SQLiteSource{db: db}, nil
+}
+
+func (s *SQLiteSource) Close() error {
+	return s.db.Close()
+}
+
+// Games reads and checks every game, like LoadGamesJSON.
+func (s *SQLiteSource) Games() ([]Game, error) {
+	rows, err := s.db.Query(`SELECT id, data FROM games ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []Game
+	interner := stringInterner{}
+	for rows.Next() {
+		var id int
+		var data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		g, err := decodeSQLiteGame(id, data)
+		if err != nil {
+			return nil, err
+		}
+		interner.game(&g)
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(games) == 0 {
+		return nil, ErrEmptyDataset
+	}
+	return games, nil
+}
+
+func decodeSQLiteGame(id int, data string) (Game, error) {
+	var g Game
+	if err := json.Unmarshal([]byte(data), &g); err != nil {
+		return Game{}, fmt.Errorf("game id %d: %w", id, err)
+	}
+	if g.ID != id {
+		return Game{}, fmt.Errorf("game id %d: data has id %d", id, g.ID)
+	}
+	if err := validateGame(g); err != nil {
+		return Game{}, fmt.Errorf("game id %d: %w", id, err)
+	}
+	return g, nil
+}
+
+// WriteSQLiteCatalog creates a new SQLite catalog at path holding games.
+func WriteSQLiteCatalog(path string, games []Game) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	insertGame, err := tx.Prepare(`INSERT INTO games (id, name, year, main_genre, data) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	insertAttr, err := tx.Prepare(`INSERT INTO game_attributes (game_id, field, value) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range games {
+		data, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		if _, err := insertGame.Exec(g.ID, g.Name, g.Year, g.MainGenre, string(data)); err != nil {
+			return fmt.Errorf("game id %d: %w", g.ID, err)
+		}
+
+		v := reflect.ValueOf(g)
+		for field, index := range gameFields {
+			if field == "id" || field == "name" || field == "year" || field == "main_genre" {
+				continue
+			}
+			for _, value := range attributeStrings(v.FieldByIndex(index)) {
+				if _, err := insertAttr.Exec(g.ID, field, value); err != nil {
+					return fmt.Errorf("game id %d: %w", g.ID, err)
+				}
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// RunExportSQLite implements the "export-sqlite" command, writing the
+// loaded dataset to a new SQLite catalog:
+//
+//	export-sqlite games.db
+func RunExportSQLite(args []string, out io.Writer, games []Game) int {
+	fs := flag.NewFlagSet("export-sqlite", flag.ContinueOnError)
+	fs.SetOutput(out)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(out, "usage: export-sqlite <path.db>")
+		return 2
+	}
+
+	if err := WriteSQLiteCatalog(fs.Arg(0), games); err != nil {
+		fmt.Fprintf(out, "export: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "wrote %d games to %s\n", len(games), fs.Arg(0))
+	return 0
+}
@@ -0,0 +1,161 @@
+package guesser
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A dataset path can be an http(s) URL of a games.json. It is downloaded
+// at startup like a file; with -dataset-refresh it is checked again on an
+// interval, conditionally (ETag / Last-Modified), and republished when it
+// changed. A download that fails or doesn't parse keeps the current data.
+
+const (
+	remoteDatasetTimeout  = 2 * time.Minute
+	remoteDatasetMaxBytes = 256 << 20
+)
+
+var remoteDatasetClient = &http.Client{Timeout: remoteDatasetTimeout}
+
+// remoteDataset is the last good download of a URL, for conditional
+// requests.
+type remoteDataset struct {
+	mu           sync.Mutex // one download at a time
+	etag         string
+	lastModified string
+	games        []Game
+}
+
+var (
+	remoteDatasetsMu sync.Mutex
+	remoteDatasets   = map[string]*remoteDataset{}
+)
+
+func isDatasetURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// URLSource is a games.json served over HTTP.
+type URLSource struct {
+	URL string
+}
+
+func (s URLSource) Games() ([]Game, error) {
+	games, _, err := fetchRemoteGames(s.URL)
+	return games, err
+}
+
+func (URLSource) Close() error { return nil }
+
+// fetchRemoteGames downloads url unless the server says the last good
+// copy is still current. changed is false when that copy is returned.
+func fetchRemoteGames(url string) (games []Game, changed bool, err error) {
+	remoteDatasetsMu.Lock()
+	rd, ok := remoteDatasets[url]
+	if !ok {
+		rd = &remoteDataset{}
+		remoteDatasets[url] = rd
+	}
+	remoteDatasetsMu.Unlock()
+
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if rd.games != nil {
+		if rd.etag != "" {
+			req.Header.Set("If-None-Match", rd.etag)
+		}
+		if rd.lastModified != "" {
+			req.Header.Set("If-Modified-Since", rd.lastModified)
+		}
+	}
+
+	resp, err := remoteDatasetClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && rd.games != nil:
+		return rd.games, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("%s: HTTP %d", url, resp.StatusCode)
+	}
+
+	games, err = ReadGames(http.MaxBytesReader(nil, resp.Body, remoteDatasetMaxBytes))
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			return nil, false, fmt.Errorf("%s: larger than %d bytes", url, remoteDatasetMaxBytes)
+		}
+		return nil, false, fmt.Errorf("%s: %w", url, err)
+	}
+
+	rd.etag = resp.Header.Get("ETag")
+	rd.lastModified = resp.Header.Get("Last-Modified")
+	rd.games = games
+	return games, true, nil
+}
+
+// RefreshRemoteDatasets checks the default catalog and every extra
+// dataset that comes from a URL, and republishes the ones that changed.
+// Unchanged datasets are left out of the results.
+func RefreshRemoteDatasets() []DatasetReload {
+	datasets.reloadMu.Lock()
+	defer datasets.reloadMu.Unlock()
+
+	var results []DatasetReload
+	for _, ds := range datasets.reloadable() {
+		if !isDatasetURL(ds.Config.Path) {
+			continue
+		}
+		games, changed, err := fetchRemoteGames(ds.Config.Path)
+		if err == nil && !changed {
+			continue
+		}
+
+		result := DatasetReload{ID: ds.Config.ID}
+		snap := ds.Data.Current()
+		if err == nil {
+			var published *Snapshot
+			if published, err = publishDataset(ds.Data, ds.Config.ID, games, ds.templates); err == nil {
+				snap = published
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		if snap != nil {
+			result.Version = snap.Version
+			result.GameCount = len(snap.Index.Games)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// WatchRemoteDatasets runs RefreshRemoteDatasets every interval, forever.
+func WatchRemoteDatasets(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, res := range RefreshRemoteDatasets() {
+			if res.Error != "" {
+				slog.Error("refresh dataset failed", "dataset", res.ID, "err", res.Error, "keepingVersion", res.Version)
+				continue
+			}
+			slog.Info("refreshed dataset", "dataset", res.ID, "games", res.GameCount, "version", res.Version)
+		}
+	}
+}
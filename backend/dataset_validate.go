@@ -0,0 +1,262 @@
+package guesser
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// "validate" checks a games.json for CI. Unlike loading, which stops at
+// the first bad record, it reads everything and reports every problem:
+//
+//	guesser validate [-strict] [games.json]
+//
+// The report is JSON on stdout; the exit code is 1 when there are errors
+// (or, with -strict, warnings).
+
+// gameFieldValues are the values the dataset builders produce for fields
+// with a fixed vocabulary. Fields not listed (genres, developer, ...)
+// take any value.
+var gameFieldValues = map[string][]string{
+	"platforms":          {"PC", "PlayStation", "Xbox", "Nintendo Switch", "Mobile"},
+	"perspective":        {"First Person", "Third Person", "Isometric", "Top-down", "Side", "Unknown"},
+	"camera":             {"First Person", "Third Person", "Isometric", "Top-down", "Side", "Unknown"},
+	"world_type":         {"Open World", "Metroidvania", "Level-based", "Hub-based", "Linear / Mixed"},
+	"theme":              {"Horror", "Post-Apocalyptic", "Sci-Fi", "Fantasy", "Historical", "Modern / Other"},
+	"tone":               {"Dark", "Wholesome", "Comedic", "Emotional", "Cute", "Neutral"},
+	"difficulty":         {"Souls-like", "Hard", "Easy", "Normal / Unknown"},
+	"replayability":      {"Roguelike", "High", "Medium / Low / Unknown"},
+	"developer_region":   {"Japan", "Europe", "North America", "Unknown / Various"},
+	"esrb":               {"E", "E10+", "T", "M", "Unknown"},
+	"age_rating":         {"3+", "7+", "12+", "16+", "Unknown"},
+	"violence_level":     {"Low", "Medium", "High", "Unknown / Varies"},
+	"visual_style":       {"Pixel Art", "Retro", "Anime", "Realistic", "Cartoon", "Stylized", "Low Poly", "Minimalist", "Unspecified"},
+	"combat_style":       {"Melee", "Guns", "Magic", "Stealth", "Tactical", "Unspecified"},
+	"structure_features": {"Crafting", "Survival", "Skill Tree", "Loot", "Base Building", "Procedural Generation", "Branching Story", "None / Standard"},
+	"mood":               {"Atmospheric", "Psychological", "Mysterious", "Thrilling", "Story-Driven", "Relaxing", "Neutral"},
+	"setting": {"Space / Sci-Fi", "Underwater", "Island", "Urban", "Medieval", "Post-Apocalyptic",
+		"Desert / Wasteland", "Wilderness", "Unspecified / Mixed"},
+	"monetization": {"Free to Play", "Microtransactions", "DLC-heavy", "Seasonal / Live Service", "Paid / Standard"},
+	"multiplayer_mode": {"Singleplayer", "MMO", "Battle Royale", "Competitive Online", "Local Co-op", "Online Co-op",
+		"Multiplayer / Mixed", "Unknown"},
+	"vr_support":         {"VR-only", "VR-optional", "None"},
+	"controller_support": {"Full", "Partial", "None", "Unknown"},
+	"score_bucket":       {"90+", "80-89", "70-79", "60-69", "<60", "Unknown"},
+	"playtime_bucket":    {"<5h", "5-20h", "20-60h", "60h+", "Unknown"},
+	"price_bucket":       {"Free", "Budget", "Standard", "Premium", "Unknown"},
+}
+
+// optionalGameFields may be missing or empty: they came after the first
+// datasets, or only some builders fill them.
+var optionalGameFields = map[string]bool{
+	"aliases":            true,
+	"image_url":          true,
+	"vr_support":         true,
+	"controller_support": true,
+	"playtime_bucket":    true,
+	"price_bucket":       true,
+	"popularity":         true,
+}
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// DatasetIssue is one problem with one game. Index is the game's 1-based
+// position in the file; Problem is a stable code for tools to match on.
+type DatasetIssue struct {
+	Index    int    `json:"index"`
+	GameID   int    `json:"gameId,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Severity string `json:"severity"`
+	Problem  string `json:"problem"`
+	Message  string `json:"message"`
+}
+
+type DatasetReport struct {
+	Path     string         `json:"path"`
+	Games    int            `json:"games"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Issues   []DatasetIssue `json:"issues"`
+}
+
+func (r *DatasetReport) add(issue DatasetIssue) {
+	if issue.Severity == SeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// ValidateDataset checks every record of a games.json. It only returns
+// an error when the file can't be read as a JSON array at all.
+func ValidateDataset(path string) (DatasetReport, error) {
+	report := DatasetReport{Path: path, Issues: []DatasetIssue{}}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return report, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReaderSize(f, 1<<16))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return report, errors.New("expected a JSON array of games")
+	}
+
+	ids := map[int]int{}
+	names := map[string]int{}
+	for n := 1; dec.More(); n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return report, fmt.Errorf("game #%d: %w", n, err)
+		}
+		report.Games++
+		validateRecord(&report, n, raw, ids, names)
+	}
+	if _, err := dec.Token(); err != nil {
+		return report, fmt.Errorf("after game #%d: %w", report.Games, err)
+	}
+
+	if report.Games == 0 {
+		report.add(DatasetIssue{Severity: SeverityError, Problem: "empty_dataset", Message: ErrEmptyDataset.Error()})
+	}
+	return report, nil
+}
+
+func validateRecord(report *DatasetReport, n int, raw json.RawMessage, ids map[int]int, names map[string]int) {
+	issue := func(g *Game, field, severity, problem, format string, args ...any) {
+		id := 0
+		if g != nil {
+			id = g.ID
+		}
+		report.add(DatasetIssue{Index: n, GameID: id, Field: field, Severity: severity, Problem: problem,
+			Message: fmt.Sprintf(format, args...)})
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		issue(nil, "", SeverityError, "invalid_record", "not a JSON object: %v", err)
+		return
+	}
+	var g Game
+	if err := json.Unmarshal(raw, &g); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			issue(&g, typeErr.Field, SeverityError, "invalid_type", "%s should be %s, not %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		} else {
+			issue(&g, "", SeverityError, "invalid_record", "%v", err)
+		}
+		return
+	}
+
+	for _, key := range sortedKeys(keys) {
+		if _, ok := gameFields[key]; !ok {
+			issue(&g, key, SeverityWarning, "unknown_field", "%s is not a game field", key)
+		}
+	}
+
+	if g.ID <= 0 {
+		issue(&g, "id", SeverityError, "invalid_id", "invalid id %d", g.ID)
+	} else if first, ok := ids[g.ID]; ok {
+		issue(&g, "id", SeverityError, "duplicate_id", "id %d is already used by game #%d", g.ID, first)
+	} else {
+		ids[g.ID] = n
+	}
+
+	if g.Year < minGameYear || g.Year > maxGameYear {
+		issue(&g, "year", SeverityError, "year_out_of_range", "year %d is outside %d-%d", g.Year, minGameYear, maxGameYear)
+	}
+
+	// Same-named games (remakes, reboots) are allowed, but a typed guess
+	// can't tell them apart.
+	if title := normalizeTitle(g.Name); title != "" {
+		if first, ok := names[title]; ok {
+			issue(&g, "name", SeverityWarning, "duplicate_name", "%q has the same name as game #%d", g.Name, first)
+		} else {
+			names[title] = n
+		}
+	}
+
+	v := reflect.ValueOf(g)
+	for _, field := range sortedKeys(gameFields) {
+		fv := v.FieldByIndex(gameFields[field])
+		_, present := keys[field]
+		if !present {
+			if !optionalGameFields[field] {
+				issue(&g, field, SeverityError, "missing_field", "%s is missing", field)
+			}
+			continue
+		}
+
+		switch fv.Kind() {
+		case reflect.String:
+			if fv.String() == "" && !optionalGameFields[field] {
+				issue(&g, field, SeverityError, "empty_value", "%s is empty", field)
+			}
+		case reflect.Slice:
+			if fv.Len() == 0 && !optionalGameFields[field] {
+				issue(&g, field, SeverityError, "empty_list", "%s has no values", field)
+			}
+		}
+
+		allowed, closed := gameFieldValues[field]
+		if !closed {
+			continue
+		}
+		for _, value := range attributeStrings(fv) {
+			if !slices.Contains(allowed, value) {
+				issue(&g, field, SeverityError, "unknown_value", "%s has unknown value %q", field, value)
+			}
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RunValidateDataset implements the "validate" command.
+func RunValidateDataset(args []string, out io.Writer, defaultPath string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(out)
+	strict := fs.Bool("strict", false, "fail on warnings too")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	path := defaultPath
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	report, err := ValidateDataset(path)
+	if err != nil {
+		fmt.Fprintf(out, "validate %s: %v\n", path, err)
+		return 1
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	if report.Errors > 0 || (*strict && report.Warnings > 0) {
+		return 1
+	}
+	return 0
+}
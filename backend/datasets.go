@@ -0,0 +1,307 @@
+package guesser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// defaultDatasetID names the catalog loaded from -dataset. Sessions that
+// don't pick a dataset play against it.
+const defaultDatasetID = "default"
+
+// DatasetConfig describes an extra games list sessions can choose, e.g.
+// "indie" or "retro" next to the full catalog.
+type DatasetConfig struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Path        string `json:"path"`
+}
+
+// Dataset is a loaded DatasetConfig.
+type Dataset struct {
+	Config    DatasetConfig
+	Data      *SnapshotHolder
+	templates []QuestionTemplate
+}
+
+// reload re-reads the dataset's file and publishes it as a new snapshot.
+// On error the current snapshot stays.
+func (ds *Dataset) reload() (*Snapshot, error) {
+	games, err := LoadGames(ds.Config.Path)
+	if err != nil {
+		return nil, err
+	}
+	return publishDataset(ds.Data, ds.Config.ID, games, ds.templates)
+}
+
+type datasetRegistry struct {
+	mu       sync.RWMutex
+	main     *Dataset   // the -dataset catalog, for reloads
+	datasets []*Dataset // in config order
+	// tenants holds each tenant's catalog, for restoring sessions.
+	tenants map[string]*SnapshotHolder
+
+	reloadMu sync.Mutex // one reload at a time
+}
+
+// global registry of extra datasets for the default catalog
+var datasets = &datasetRegistry{}
+
+func (d *datasetRegistry) get(id string) (*Dataset, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, ds := range d.datasets {
+		if ds.Config.ID == id {
+			return ds, true
+		}
+	}
+	return nil, false
+}
+
+func (d *datasetRegistry) list() []*Dataset {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return append([]*Dataset(nil), d.datasets...)
+}
+
+// registerTenant records the catalog MountTenants loaded for tenant.
+func (d *datasetRegistry) registerTenant(tenant string, data *SnapshotHolder) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.tenants == nil {
+		d.tenants = make(map[string]*SnapshotHolder)
+	}
+	d.tenants[tenant] = data
+}
+
+// snapshotFor returns the current snapshot sessions of tenant on datasetID
+// play against, if it is (still) loaded.
+func (d *datasetRegistry) snapshotFor(tenant, datasetID string) (*Snapshot, bool) {
+	var holder *SnapshotHolder
+	switch {
+	case tenant != "":
+		d.mu.RLock()
+		holder = d.tenants[tenant]
+		d.mu.RUnlock()
+	case datasetID == "" || datasetID == defaultDatasetID:
+		d.mu.RLock()
+		if d.main != nil {
+			holder = d.main.Data
+		}
+		d.mu.RUnlock()
+	default:
+		if ds, ok := d.get(datasetID); ok {
+			holder = ds.Data
+		}
+	}
+
+	if holder == nil || holder.Current() == nil {
+		return nil, false
+	}
+	return holder.Current(), true
+}
+
+// LoadDatasetConfigs reads a JSON array of DatasetConfig.
+func LoadDatasetConfigs(path string) ([]DatasetConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []DatasetConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	seen := map[string]bool{defaultDatasetID: true}
+	for i, c := range configs {
+		switch {
+		case c.ID == "":
+			return nil, fmt.Errorf("%s: dataset #%d has no id", path, i)
+		case seen[c.ID]:
+			return nil, fmt.Errorf("%s: duplicate dataset id %q", path, c.ID)
+		case c.Path == "":
+			return nil, fmt.Errorf("%s: dataset %q has no path", path, c.ID)
+		}
+		seen[c.ID] = true
+	}
+
+	return configs, nil
+}
+
+// ConfigureDatasets loads and indexes every configured dataset and makes
+// them selectable by datasetId. Call it before serving.
+func ConfigureDatasets(configs []DatasetConfig, templates []QuestionTemplate) error {
+	loaded := make([]*Dataset, 0, len(configs))
+	for _, c := range configs {
+		games, err := LoadGames(c.Path)
+		if err != nil {
+			return fmt.Errorf("dataset %s: %w", c.ID, err)
+		}
+
+		ds := &Dataset{Config: c, Data: &SnapshotHolder{}, templates: templates}
+		if _, err := publishDataset(ds.Data, c.ID, games, templates); err != nil {
+			return fmt.Errorf("dataset %s: %w", c.ID, err)
+		}
+		loaded = append(loaded, ds)
+	}
+
+	datasets.mu.Lock()
+	datasets.datasets = loaded
+	datasets.mu.Unlock()
+	return nil
+}
+
+// ConfigureMainDataset registers the default catalog, published into data
+// from path, so ReloadDatasets can refresh it too.
+func ConfigureMainDataset(path string, data *SnapshotHolder, templates []QuestionTemplate) {
+	datasets.mu.Lock()
+	datasets.main = &Dataset{
+		Config:    DatasetConfig{ID: defaultDatasetID, Name: "All games", Path: path},
+		Data:      data,
+		templates: templates,
+	}
+	datasets.mu.Unlock()
+}
+
+// DatasetReload is the outcome of reloading one dataset.
+type DatasetReload struct {
+	ID        string `json:"id"`
+	Version   uint64 `json:"version"`
+	GameCount int    `json:"gameCount"`
+	// Error is set when the file could not be loaded; the dataset then
+	// keeps serving Version.
+	Error string `json:"error,omitempty"`
+}
+
+// reloadable returns the default catalog, if registered, and the extra
+// datasets.
+func (d *datasetRegistry) reloadable() []*Dataset {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	all := make([]*Dataset, 0, len(d.datasets)+1)
+	if d.main != nil {
+		all = append(all, d.main)
+	}
+	return append(all, d.datasets...)
+}
+
+// ReloadDatasets re-reads the default catalog and every extra dataset
+// from disk (or their URLs). New sessions get the new data; running sessions stay on the
+// snapshot they started with. Tenant catalogs are not reloaded.
+func ReloadDatasets() []DatasetReload {
+	datasets.reloadMu.Lock()
+	defer datasets.reloadMu.Unlock()
+
+	all := datasets.reloadable()
+	results := make([]DatasetReload, 0, len(all))
+	for _, ds := range all {
+		result := DatasetReload{ID: ds.Config.ID}
+		snap, err := ds.reload()
+		if err != nil {
+			result.Error = err.Error()
+			snap = ds.Data.Current()
+		}
+		if snap != nil {
+			result.Version = snap.Version
+			result.GameCount = len(snap.Index.Games)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// selectDataset returns the snapshot holder new sessions should use for
+// datasetID: data itself for "" or "default", otherwise a configured
+// dataset. Tenants only have their own catalog.
+func selectDataset(r *http.Request, data *SnapshotHolder, datasetID string) (*SnapshotHolder, bool) {
+	if datasetID == "" || datasetID == defaultDatasetID {
+		return data, true
+	}
+	if tenantID(r) != "" {
+		return nil, false
+	}
+
+	ds, ok := datasets.get(datasetID)
+	if !ok {
+		return nil, false
+	}
+	return ds.Data, true
+}
+
+type DatasetInfo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	GameCount   int    `json:"gameCount"`
+}
+
+type DatasetsResponse struct {
+	Datasets []DatasetInfo `json:"datasets"`
+}
+
+// ---------------------------------
+// /api/v1/datasets   (GET)
+// ---------------------------------
+
+func DatasetsHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		resp := DatasetsResponse{Datasets: []DatasetInfo{{
+			ID:        defaultDatasetID,
+			Name:      "All games",
+			GameCount: len(data.Current().Index.Games),
+		}}}
+
+		if tenantID(r) == "" {
+			for _, ds := range datasets.list() {
+				resp.Datasets = append(resp.Datasets, DatasetInfo{
+					ID:          ds.Config.ID,
+					Name:        ds.Config.Name,
+					Description: ds.Config.Description,
+					GameCount:   len(ds.Data.Current().Index.Games),
+				})
+			}
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// ---------------------------------
+// /api/v1/admin/datasets/reload   (POST)
+// ---------------------------------
+
+func ReloadDatasetsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+		if !isAdmin(r) {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "admin credentials required")
+			return
+		}
+
+		results := ReloadDatasets()
+		status := http.StatusOK
+		for _, res := range results {
+			if res.Error != "" {
+				status = http.StatusInternalServerError
+			}
+		}
+		writeJSON(w, status, results)
+	})
+}
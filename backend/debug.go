@@ -0,0 +1,67 @@
+package guesser
+
+import "math"
+
+// debugMode adds engine internals to API responses. Never enable it in
+// production: it reveals the secret.
+var debugMode bool
+
+// EnableDebug turns debug fields in responses on or off.
+func EnableDebug(enabled bool) {
+	debugMode = enabled
+}
+
+// DebugSplit is how the last question divided the candidates it was asked
+// against.
+type DebugSplit struct {
+	Yes int `json:"yes"`
+	No  int `json:"no"`
+	// InformationBits is the entropy of the split: 1 bit for 50/50.
+	InformationBits float64 `json:"informationBits"`
+}
+
+// DebugInfo explains why the engine behaves as it does.
+type DebugInfo struct {
+	SecretID   int    `json:"secretId"`
+	SecretName string `json:"secretName"`
+	// EntropyBits is the remaining uncertainty: log2 of the candidate count.
+	EntropyBits float64     `json:"entropyBits"`
+	LastSplit   *DebugSplit `json:"lastSplit,omitempty"`
+}
+
+// binaryEntropy is the information, in bits, gained by asking a question
+// that yes of total candidates would answer "yes" to.
+func binaryEntropy(yes, total int) float64 {
+	if total == 0 || yes == 0 || yes == total {
+		return 0
+	}
+	p := float64(yes) / float64(total)
+	return -p*math.Log2(p) - (1-p)*math.Log2(1-p)
+}
+
+func candidateEntropy(count int) float64 {
+	if count <= 1 {
+		return 0
+	}
+	return math.Log2(float64(count))
+}
+
+// buildDebugInfo returns nil unless debug mode is on, so callers can
+// assign it straight to an omitempty field.
+func buildDebugInfo(state SessionState, idx GameIndex, split *DebugSplit) *DebugInfo {
+	if !debugMode {
+		return nil
+	}
+
+	var secretName string
+	if secret, ok := idx.Games[state.SecretID]; ok {
+		secretName = secret.Name
+	}
+
+	return &DebugInfo{
+		SecretID:    state.SecretID,
+		SecretName:  secretName,
+		EntropyBits: candidateEntropy(len(state.RemainingIDs)),
+		LastSplit:   split,
+	}
+}
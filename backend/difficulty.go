@@ -0,0 +1,88 @@
+package guesser
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// SessionDifficulty picks how obscure a session's secret may be.
+type SessionDifficulty string
+
+const (
+	// DifficultyEasy draws the secret from the pool's Rules.EasySecrets
+	// most popular games.
+	DifficultyEasy SessionDifficulty = "easy"
+	// DifficultyNormal draws it weighted by popularity (see RandomSecret).
+	DifficultyNormal SessionDifficulty = "normal"
+	// DifficultyHard draws it uniformly, so the long tail comes up as
+	// often as the hits, and leaves out Revealing templates.
+	DifficultyHard SessionDifficulty = "hard"
+)
+
+func validDifficulty(d SessionDifficulty) bool {
+	switch d {
+	case "", DifficultyEasy, DifficultyNormal, DifficultyHard:
+		return true
+	}
+	return false
+}
+
+// hasPopularity reports whether any of idx's games has a Popularity;
+// without one easy can't tell the hits from the long tail.
+func (idx GameIndex) hasPopularity() bool {
+	return len(idx.ByPopularity) > 0 && idx.Games[idx.ByPopularity[0]].Popularity > 0
+}
+
+// secretFor draws a secret from pool, which must not be empty, for a
+// session of difficulty d.
+func secretFor(rng SecretPicker, idx GameIndex, pool []int, d SessionDifficulty) int {
+	switch d {
+	case DifficultyEasy:
+		return RandomSecret(rng, idx, popularGames(idx, pool, rules.EasySecrets))
+	case DifficultyHard:
+		return pool[rng.Intn(len(pool))]
+	default:
+		return RandomSecret(rng, idx, pool)
+	}
+}
+
+// popularGames returns the n most popular games of pool. Games without a
+// Popularity aren't ranked, so when none has one it is the whole pool.
+func popularGames(idx GameIndex, pool []int, n int) []int {
+	in := make(map[int]bool, len(pool))
+	for _, id := range pool {
+		in[id] = true
+	}
+
+	top := make([]int, 0, n)
+	for _, id := range idx.ByPopularity {
+		if len(top) == n || idx.Games[id].Popularity == 0 {
+			break
+		}
+		if in[id] {
+			top = append(top, id)
+		}
+	}
+	if len(top) == 0 {
+		return pool
+	}
+	return top
+}
+
+// sessionTemplates is what state's session may ask: hard sessions leave
+// out the Revealing templates.
+func sessionTemplates(state SessionState, templates []QuestionTemplate) []QuestionTemplate {
+	if state.Difficulty != DifficultyHard {
+		return templates
+	}
+	return slices.DeleteFunc(slices.Clone(templates), func(t QuestionTemplate) bool { return t.Revealing })
+}
+
+// sessionQuestionTypes is the questionTypes list a start response sends
+// for state.
+func sessionQuestionTypes(snap *Snapshot, state SessionState) json.RawMessage {
+	if state.Difficulty != DifficultyHard {
+		return snap.QuestionTypes
+	}
+	return mustMarshal(BuildQuestionTypeDefs(sessionTemplates(state, snap.Templates)))
+}
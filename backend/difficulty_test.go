@@ -0,0 +1,68 @@
+package guesser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSecretFor(t *testing.T) {
+	idx := testIndex()
+	saved := rules
+	defer func() { rules = saved }()
+	rules.EasySecrets = 2
+
+	tests := []struct {
+		difficulty SessionDifficulty
+		allowed    []int
+	}{
+		{DifficultyEasy, []int{1, 5}}, // the two most popular
+		{DifficultyNormal, idx.AllGameIDs},
+		{DifficultyHard, idx.AllGameIDs},
+		{"", idx.AllGameIDs},
+	}
+	for _, tt := range tests {
+		rng := SeededPicker(11)
+		seen := map[int]bool{}
+		for i := 0; i < 500; i++ {
+			id := secretFor(rng, idx, idx.AllGameIDs, tt.difficulty)
+			if !slices.Contains(tt.allowed, id) {
+				t.Fatalf("%q: drew %d, want one of %v", tt.difficulty, id, tt.allowed)
+			}
+			seen[id] = true
+		}
+		if len(seen) != len(tt.allowed) {
+			t.Errorf("%q: drew %d distinct games, want all %d", tt.difficulty, len(seen), len(tt.allowed))
+		}
+
+		a, b := secretFor(SeededPicker(5), idx, idx.AllGameIDs, tt.difficulty), secretFor(SeededPicker(5), idx, idx.AllGameIDs, tt.difficulty)
+		if a != b {
+			t.Errorf("%q: same seed drew %d and %d", tt.difficulty, a, b)
+		}
+	}
+}
+
+func TestSecretForHardIsUniform(t *testing.T) {
+	idx := testIndex()
+	rng := SeededPicker(2)
+	counts := map[int]int{}
+	for i := 0; i < 5000; i++ {
+		counts[secretFor(rng, idx, idx.AllGameIDs, DifficultyHard)]++
+	}
+	for _, id := range idx.AllGameIDs {
+		if counts[id] < 800 || counts[id] > 1200 {
+			t.Errorf("game %d drawn %d times of 5000", id, counts[id])
+		}
+	}
+}
+
+func TestHasPopularity(t *testing.T) {
+	if !testIndex().hasPopularity() {
+		t.Error("rated catalog: hasPopularity() = false")
+	}
+	if NewGameIndex([]Game{{ID: 1, Name: "A"}}).hasPopularity() {
+		t.Error("unrated catalog: hasPopularity() = true")
+	}
+	if NewGameIndex(nil).hasPopularity() {
+		t.Error("empty catalog: hasPopularity() = true")
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	commandPrefix = "!guesser"
+	reactionYes   = "✅"
+	reactionNo    = "❌"
+)
+
+// bot runs at most one game per Discord channel.
+type bot struct {
+	api *apiClient
+
+	mu    sync.Mutex
+	games map[string]*apiSession // channel ID -> game
+}
+
+func newBot(api *apiClient) *bot {
+	return &bot{
+		api:   api,
+		games: make(map[string]*apiSession),
+	}
+}
+
+func (b *bot) game(channelID string) (*apiSession, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.games[channelID]
+	return g, ok
+}
+
+func (b *bot) setGame(channelID string, g *apiSession) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if g == nil {
+		delete(b.games, channelID)
+		return
+	}
+	b.games[channelID] = g
+}
+
+const helpText = "**Game Guesser** — I'm thinking of a video game.\n" +
+	"`!guesser start` new game\n" +
+	"`!guesser questions` list question types\n" +
+	"`!guesser ask <type> [option]` e.g. `!guesser ask platform_includes PC` (I react " + reactionYes + " / " + reactionNo + ")\n" +
+	"`!guesser is <game name>` final guess\n" +
+	"`!guesser stop` give up the current game"
+
+// onMessage dispatches "!guesser ..." commands.
+func (b *bot) onMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author == nil || m.Author.Bot {
+		return
+	}
+
+	fields := strings.Fields(m.Content)
+	if len(fields) == 0 || fields[0] != commandPrefix {
+		return
+	}
+
+	if len(fields) == 1 {
+		b.reply(s, m, helpText)
+		return
+	}
+
+	args := fields[2:]
+	switch fields[1] {
+	case "start":
+		b.start(s, m)
+	case "questions":
+		b.listQuestions(s, m)
+	case "ask":
+		b.ask(s, m, args)
+	case "is":
+		b.guess(s, m, strings.Join(args, " "))
+	case "stop":
+		b.setGame(m.ChannelID, nil)
+		b.reply(s, m, "Game stopped.")
+	default:
+		b.reply(s, m, helpText)
+	}
+}
+
+func (b *bot) reply(s *discordgo.Session, m *discordgo.MessageCreate, text string) {
+	if _, err := s.ChannelMessageSend(m.ChannelID, text); err != nil {
+		log.Printf("send to %s: %v", m.ChannelID, err)
+	}
+}
+
+func (b *bot) start(s *discordgo.Session, m *discordgo.MessageCreate) {
+	g, candidates, err := b.api.start(m.ChannelID)
+	if err != nil {
+		b.reply(s, m, "Could not start a game: "+err.Error())
+		return
+	}
+
+	b.setGame(m.ChannelID, g)
+	b.reply(s, m, fmt.Sprintf("I've picked one of %d games. Ask away!", candidates))
+}
+
+func (b *bot) listQuestions(s *discordgo.Session, m *discordgo.MessageCreate) {
+	g, ok := b.game(m.ChannelID)
+	if !ok {
+		b.reply(s, m, "No game running. `!guesser start` first.")
+		return
+	}
+
+	var out strings.Builder
+	for _, q := range g.QuestionTypes {
+		fmt.Fprintf(&out, "`%s` (%s)", q.ID, q.Category)
+		if len(q.Values) > 0 {
+			fmt.Fprintf(&out, ": %s", strings.Join(q.Values, ", "))
+		}
+		out.WriteString("\n")
+	}
+	b.reply(s, m, out.String())
+}
+
+// ask forwards a question and answers with a reaction on the asker's
+// message, plus a short status line.
+func (b *bot) ask(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	g, ok := b.game(m.ChannelID)
+	if !ok {
+		b.reply(s, m, "No game running. `!guesser start` first.")
+		return
+	}
+	if len(args) == 0 {
+		b.reply(s, m, "Usage: `!guesser ask <type> [option]`")
+		return
+	}
+
+	option := strings.Join(args[1:], " ")
+	resp, err := b.api.ask(g, args[0], option)
+	if err != nil {
+		b.reply(s, m, "Can't ask that: "+err.Error())
+		return
+	}
+
+	reaction := reactionNo
+	if resp.Answer {
+		reaction = reactionYes
+	}
+	if err := s.MessageReactionAdd(m.ChannelID, m.ID, reaction); err != nil {
+		log.Printf("react in %s: %v", m.ChannelID, err)
+	}
+
+	status := fmt.Sprintf("Q%d: %s — %d candidates left.", resp.QuestionNumber, resp.QuestionText, resp.CandidatesCount)
+	if resp.FinalGuessAvailable {
+		status += " Only one left: time to guess with `!guesser is <name>`."
+	}
+	b.reply(s, m, status)
+}
+
+func (b *bot) guess(s *discordgo.Session, m *discordgo.MessageCreate, name string) {
+	g, ok := b.game(m.ChannelID)
+	if !ok {
+		b.reply(s, m, "No game running. `!guesser start` first.")
+		return
+	}
+	if name == "" {
+		b.reply(s, m, "Usage: `!guesser is <game name>`")
+		return
+	}
+
+	resp, err := b.api.guess(g, name)
+	if err != nil {
+		b.reply(s, m, "Guess failed: "+err.Error())
+		return
+	}
+
+	if resp.Game == nil {
+		msg := "Nope! Keep going."
+		if left := resp.GuessesRemaining; left != nil {
+			msg = fmt.Sprintf("Nope! %d guesses left.", *left)
+			if *left == 1 {
+				msg = "Nope! 1 guess left."
+			}
+		}
+		b.reply(s, m, msg)
+		return
+	}
+
+	b.setGame(m.ChannelID, nil)
+	if resp.Correct {
+		b.reply(s, m, fmt.Sprintf("🎉 %s got it: **%s** (%d)!", m.Author.Username, resp.Game.Name, resp.Game.Year))
+		return
+	}
+	b.reply(s, m, fmt.Sprintf("Nope! It was **%s** (%d).", resp.Game.Name, resp.Game.Year))
+}
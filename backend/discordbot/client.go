@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The bot talks to a running guesser server over its public HTTP API, so
+// every rule (filtering, limits, scoring) stays in the engine.
+
+type questionTypeDef struct {
+	ID       string   `json:"id"`
+	Category string   `json:"category"`
+	Values   []string `json:"values"`
+}
+
+type startResponse struct {
+	SessionID       string            `json:"sessionId"`
+	ClientToken     string            `json:"clientToken"`
+	CandidatesCount int               `json:"candidatesCount"`
+	QuestionTypes   []questionTypeDef `json:"questionTypes"`
+}
+
+type askResponse struct {
+	Answer              bool   `json:"answer"`
+	CandidatesCount     int    `json:"candidatesCount"`
+	QuestionText        string `json:"questionText"`
+	QuestionNumber      int    `json:"questionNumber"`
+	FinalGuessAvailable bool   `json:"finalGuessAvailable"`
+}
+
+// guessResponse.Game is only sent once the guess has ended the game; a
+// wrong guess with guesses left keeps it going.
+type guessResponse struct {
+	Correct          bool      `json:"correct"`
+	GuessesRemaining *int      `json:"guessesRemaining"`
+	Game             *gameInfo `json:"game"`
+}
+
+type gameInfo struct {
+	Name string `json:"name"`
+	Year int    `json:"year"`
+}
+
+type apiClient struct {
+	baseURL string
+	// apiKey, when set, lets the server tell the bot's channels apart
+	// by the clientId it sends.
+	apiKey string
+	http   *http.Client
+}
+
+func newAPIClient(baseURL, apiKey string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// apiSession is one game as seen by the bot.
+type apiSession struct {
+	ID            string
+	Token         string
+	QuestionTypes []questionTypeDef
+}
+
+func (c *apiClient) post(path, token string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if token != "" {
+		req.Header.Set("X-Session-Token", token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(msg, &apiErr) == nil && apiErr.Error.Message != "" {
+			return errors.New(apiErr.Error.Message)
+		}
+		return fmt.Errorf("%s", strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// start begins a game for channel. The server is told the channel, so
+// with an API key it doesn't repeat the secrets the channel had recently.
+func (c *apiClient) start(channelID string) (*apiSession, int, error) {
+	var resp startResponse
+	body := map[string]string{"clientId": "discord:" + channelID}
+	if err := c.post("/api/v1/session/start", "", body, &resp); err != nil {
+		return nil, 0, err
+	}
+
+	return &apiSession{
+		ID:            resp.SessionID,
+		Token:         resp.ClientToken,
+		QuestionTypes: resp.QuestionTypes,
+	}, resp.CandidatesCount, nil
+}
+
+func (c *apiClient) ask(s *apiSession, questionTypeID, option string) (askResponse, error) {
+	var resp askResponse
+	body := map[string]string{"questionTypeId": questionTypeID, "option": option}
+	err := c.post("/api/v1/session/"+s.ID+"/ask", s.Token, body, &resp)
+	return resp, err
+}
+
+func (c *apiClient) guess(s *apiSession, name string) (guessResponse, error) {
+	var resp guessResponse
+	err := c.post("/api/v1/session/"+s.ID+"/guess", s.Token, map[string]string{"guess": name}, &resp)
+	return resp, err
+}
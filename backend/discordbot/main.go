@@ -0,0 +1,45 @@
+// Command discordbot runs Game Guesser games in Discord channels by
+// driving a guesser server's session API.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func main() {
+	apiURL := flag.String("api", "http://localhost:9000", "base URL of the guesser server")
+	flag.Parse()
+
+	token := os.Getenv("DISCORD_BOT_TOKEN")
+	if token == "" {
+		log.Fatal("DISCORD_BOT_TOKEN is not set")
+	}
+
+	dg, err := discordgo.New("Bot " + token)
+	if err != nil {
+		log.Fatalf("discord: %v", err)
+	}
+
+	// Optional: with a key, the server remembers each channel's recent
+	// secrets instead of treating the bot as a single player.
+	b := newBot(newAPIClient(*apiURL, os.Getenv("GUESSER_API_KEY")))
+	dg.AddHandler(b.onMessage)
+	dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent
+
+	if err := dg.Open(); err != nil {
+		log.Fatalf("discord: open: %v", err)
+	}
+	defer dg.Close()
+
+	log.Printf("Discord bot running against %s", *apiURL)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+}
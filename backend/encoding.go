@@ -0,0 +1,156 @@
+package guesser
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Response encodings a client can ask for with the Accept header.
+const (
+	contentJSON     = "application/json"
+	contentMsgPack  = "application/msgpack"
+	contentProtobuf = "application/x-protobuf"
+)
+
+// acceptAliases maps media types seen in the wild onto the ones we serve.
+var acceptAliases = map[string]string{
+	"application/json":        contentJSON,
+	"application/*":           contentJSON,
+	"*/*":                     contentJSON,
+	"application/msgpack":     contentMsgPack,
+	"application/x-msgpack":   contentMsgPack,
+	"application/vnd.msgpack": contentMsgPack,
+	"application/x-protobuf":  contentProtobuf,
+	"application/protobuf":    contentProtobuf,
+}
+
+// negotiateContentType picks the encoding with the highest q-value in the
+// Accept header, preferring JSON on ties and when nothing matches.
+func negotiateContentType(r *http.Request) string {
+	best, bestQ := contentJSON, 0.0
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		served, ok := acceptAliases[mediaType]
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > bestQ || (q == bestQ && served == contentJSON) {
+			best, bestQ = served, q
+		}
+	}
+
+	return best
+}
+
+// genericJSON converts value to the plain maps, slices and scalars its
+// JSON encoding decodes to, so every encoding shares the JSON field names
+// and omitempty rules.
+func genericJSON(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep IDs and counts exactly as encoded
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// resolveNumbers replaces json.Number with int64 or float64, which the
+// binary encoders understand.
+func resolveNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, value := range v {
+			v[key] = resolveNumbers(value)
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = resolveNumbers(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// writeResponse is writeJSON for session and catalog endpoints: it honours
+// ?fields= (see fieldsets.go) and encodes as MessagePack or Protobuf when
+// the Accept header asks for it. Protobuf bodies are a
+// google.protobuf.Value, so clients need no schema of ours to decode them.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, value any) {
+	w.Header().Add("Vary", "Accept")
+
+	contentType := negotiateContentType(r)
+	fields := r.URL.Query()["fields"]
+	if contentType == contentJSON && len(fields) == 0 {
+		writeJSON(w, status, value)
+		return
+	}
+
+	generic, err := genericJSON(value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "could not encode response")
+		return
+	}
+	if len(fields) > 0 {
+		generic = parseFields(fields).prune(generic)
+	}
+
+	var body []byte
+	switch contentType {
+	case contentMsgPack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.UseCompactInts(true)
+		err = enc.Encode(resolveNumbers(generic))
+		body = buf.Bytes()
+	case contentProtobuf:
+		var pv *structpb.Value
+		if pv, err = structpb.NewValue(resolveNumbers(generic)); err == nil {
+			body, err = proto.Marshal(pv)
+		}
+	default:
+		writeJSON(w, status, generic)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "could not encode response")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,109 @@
+package guesser
+
+import "net/http"
+
+// Every API error is answered with the same JSON envelope:
+//
+//	{"error": {"code": "unknown_session", "message": "unknown session"}}
+//
+// Code is one of the ErrorCode values below and never changes meaning,
+// so clients can switch on it; Message is for people and may be
+// reworded. A few errors add fields next to "error" (the valid options,
+// today's daily result).
+
+// ErrorCode says what went wrong, in a form clients can switch on.
+type ErrorCode string
+
+const (
+	// Malformed or invalid requests.
+	CodeInvalidJSON      ErrorCode = "invalid_json"
+	CodeInvalidRequest   ErrorCode = "invalid_request"
+	CodeMethodNotAllowed ErrorCode = "method_not_allowed"
+	CodeNotFound         ErrorCode = "not_found"
+
+	// Credentials.
+	CodeUnauthorized        ErrorCode = "unauthorized"
+	CodeForbidden           ErrorCode = "forbidden"
+	CodeInvalidAPIKey       ErrorCode = "invalid_api_key"
+	CodeOriginNotAllowed    ErrorCode = "origin_not_allowed"
+	CodeInvalidCSRFToken    ErrorCode = "invalid_csrf_token"
+	CodeInvalidSessionToken ErrorCode = "invalid_session_token"
+	CodeInvalidSessionState ErrorCode = "invalid_session_state"
+	CodeStaleSessionState   ErrorCode = "stale_session_state"
+	CodeRateLimited         ErrorCode = "rate_limited"
+
+	// Things that don't exist (any more).
+	CodeUnknownSession ErrorCode = "unknown_session"
+	CodeSessionExpired ErrorCode = "session_expired"
+	CodeUnknownDataset ErrorCode = "unknown_dataset"
+	CodeDatasetChanged ErrorCode = "dataset_changed"
+	CodeUnknownGame    ErrorCode = "unknown_game"
+	CodeUnknownRoom    ErrorCode = "unknown_room"
+	CodeUnknownRecap   ErrorCode = "unknown_recap"
+	CodeUnknownSeed    ErrorCode = "unknown_seed"
+
+	// Moves the game doesn't allow right now.
+	CodeInvalidQuestion      ErrorCode = "invalid_question"
+	CodeInvalidOption        ErrorCode = "invalid_option"
+	CodeAlreadyAsked         ErrorCode = "already_asked"
+	CodeWrongMode            ErrorCode = "wrong_mode"
+	CodeSessionFinished      ErrorCode = "session_finished"
+	CodeSessionNotFinished   ErrorCode = "session_not_finished"
+	CodeFinalGuessRequired   ErrorCode = "final_guess_required"
+	CodeQuestionLimitReached ErrorCode = "question_limit_reached"
+	CodeNoPendingQuestion    ErrorCode = "no_pending_question"
+	CodeNoHintsLeft          ErrorCode = "no_hints_left"
+	CodeCandidatesHidden     ErrorCode = "candidates_hidden"
+	CodeNotShareable         ErrorCode = "not_shareable"
+	CodeDailyCompleted       ErrorCode = "daily_completed"
+	CodeRoomFull             ErrorCode = "room_full"
+	CodeSteamLibrary         ErrorCode = "steam_library_unusable"
+
+	// Our side.
+	CodeInternal    ErrorCode = "internal_error"
+	CodeUpstream    ErrorCode = "upstream_error"
+	CodeUnavailable ErrorCode = "unavailable"
+)
+
+// errorCodes lists every code, for the API docs.
+var errorCodes = []ErrorCode{
+	CodeInvalidJSON, CodeInvalidRequest, CodeMethodNotAllowed, CodeNotFound,
+	CodeUnauthorized, CodeForbidden, CodeInvalidAPIKey, CodeOriginNotAllowed, CodeInvalidCSRFToken,
+	CodeInvalidSessionToken, CodeInvalidSessionState, CodeStaleSessionState, CodeRateLimited,
+	CodeUnknownSession, CodeSessionExpired, CodeUnknownDataset, CodeDatasetChanged,
+	CodeUnknownGame, CodeUnknownRoom, CodeUnknownRecap, CodeUnknownSeed,
+	CodeInvalidQuestion, CodeInvalidOption, CodeAlreadyAsked, CodeWrongMode, CodeSessionFinished, CodeSessionNotFinished,
+	CodeFinalGuessRequired, CodeQuestionLimitReached, CodeNoPendingQuestion, CodeNoHintsLeft, CodeCandidatesHidden,
+	CodeNotShareable, CodeDailyCompleted, CodeRoomFull, CodeSteamLibrary,
+	CodeInternal, CodeUpstream, CodeUnavailable,
+}
+
+type APIError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// ErrorResponse is the error envelope. Responses with extra fields embed
+// it.
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+func newErrorResponse(code ErrorCode, msg string) ErrorResponse {
+	return ErrorResponse{Error: APIError{Code: code, Message: msg}}
+}
+
+// writeError answers the request with status and the error envelope.
+func writeError(w http.ResponseWriter, status int, code ErrorCode, msg string) {
+	writeJSON(w, status, newErrorResponse(code, msg))
+}
+
+// methodNotAllowed is the 405 every handler answers for the wrong verb.
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed")
+}
+
+// apiNotFound answers API paths no route matches.
+func apiNotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, CodeNotFound, "not found")
+}
@@ -0,0 +1,60 @@
+package guesser
+
+import "strings"
+
+// fieldTree is a parsed ?fields= selection. A node without children keeps
+// its whole value.
+type fieldTree map[string]fieldTree
+
+// parseFields turns "id,name,similar.game.name" into a fieldTree. Paths
+// are JSON names from the top of the response; arrays are transparent, so
+// "candidates.name" picks the name of every candidate.
+func parseFields(raw []string) fieldTree {
+	tree := fieldTree{}
+	for _, list := range raw {
+		for _, path := range strings.Split(list, ",") {
+			path = strings.TrimSpace(path)
+			if path == "" {
+				continue
+			}
+			node := tree
+			for _, part := range strings.Split(path, ".") {
+				child, ok := node[part]
+				if !ok {
+					child = fieldTree{}
+					node[part] = child
+				}
+				node = child
+			}
+		}
+	}
+	return tree
+}
+
+// prune keeps only the selected keys of v, which must be a generic JSON
+// value as produced by json.Unmarshal into an interface{}.
+func (t fieldTree) prune(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for key, child := range t {
+			value, ok := v[key]
+			if !ok {
+				continue
+			}
+			if len(child) == 0 {
+				out[key] = value
+			} else {
+				out[key] = child.prune(value)
+			}
+		}
+		return out
+	case []any:
+		for i := range v {
+			v[i] = t.prune(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
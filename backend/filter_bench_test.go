@@ -0,0 +1,46 @@
+package guesser
+
+import (
+	"fmt"
+	"testing"
+)
+
+// BenchmarkApplyQuestion asks every template option of the shipped
+// dataset, from all candidates, with the per-game scan ("slice") and
+// with the answer-matrix bitsets, at growing catalog sizes:
+//
+//	go test -run '^$' -bench ApplyQuestion
+func BenchmarkApplyQuestion(b *testing.B) {
+	games, err := LoadGames("../dataset/games.json")
+	if err != nil {
+		b.Skipf("load dataset: %v", err)
+	}
+	_, templates := indexDataset("default", games, DefaultTemplates())
+
+	for _, scale := range []int{1, 10, 100} {
+		idx := PrecomputeIndex(fmt.Sprintf("x%d", scale), scaleGames(games, scale), templates)
+		scan := idx
+		scan.Answers = nil
+		state := NewSessionStateFromPool(idx.AllGameIDs, idx.AllGameIDs[0])
+
+		for _, bench := range []struct {
+			name string
+			idx  GameIndex
+		}{{"slice", scan}, {"bitset", idx}} {
+			b.Run(fmt.Sprintf("games=%d/%s", len(idx.AllGameIDs), bench.name), func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					for _, t := range templates {
+						switch {
+						case t.CheckString != nil:
+							for _, v := range t.Values {
+								ApplyQuestion(state, t, bench.idx, v)
+							}
+						case t.CheckBool != nil:
+							ApplyQuestion(state, t, bench.idx, "")
+						}
+					}
+				}
+			})
+		}
+	}
+}
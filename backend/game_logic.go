@@ -1,34 +1,257 @@
 package guesser
 
 import (
-	"math/rand"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // -----------------------------
 // Session creation
 // -----------------------------
 
-// NewSessionState picks a random secret game and initial candidate list.
-func NewSessionState(idx GameIndex) SessionState {
+// NewSessionState picks a random secret game with rng (see RandomSecret)
+// and initial candidate list.
+func NewSessionState(idx GameIndex, rng SecretPicker) SessionState {
 	if len(idx.AllGameIDs) == 0 {
 		// Edge case: no games at all.
 		return SessionState{
+			Mode:         ModeClassic,
 			RemainingIDs: []int{},
 			SecretID:     0,
+			Status:       StatusActive,
 		}
 	}
 
-	secretID := idx.AllGameIDs[rand.Intn(len(idx.AllGameIDs))]
+	secretID := RandomSecret(rng, idx, idx.AllGameIDs)
+	return NewSessionStateFromPool(idx.AllGameIDs, secretID)
+}
+
+// RandomSecret draws a secret from pool, which must not be empty,
+// weighted by the square root of each game's Popularity: well-known games
+// come up more often without crowding out the rest (a game rated 20,000
+// times is about 6x as likely as one rated 500 times). Games without a
+// Popularity weigh as much as the least popular game that has one, so a
+// dataset without the field is drawn uniformly.
+func RandomSecret(rng SecretPicker, idx GameIndex, pool []int) int {
+	least := 0
+	for _, id := range pool {
+		if p := idx.Games[id].Popularity; p > 0 && (least == 0 || p < least) {
+			least = p
+		}
+	}
+	if least == 0 {
+		return pool[rng.Intn(len(pool))]
+	}
+
+	weights := make([]float64, len(pool))
+	total := 0.0
+	for i, id := range pool {
+		p := max(idx.Games[id].Popularity, least)
+		weights[i] = math.Sqrt(float64(p))
+		total += weights[i]
+	}
+	r := rng.Float64() * total
+	for i, w := range weights {
+		if r -= w; r < 0 {
+			return pool[i]
+		}
+	}
+	return pool[len(pool)-1]
+}
+
+// ValidatePool checks that every ID exists in the index and returns the
+// IDs de-duplicated, in their original order.
+func ValidatePool(idx GameIndex, ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("game list is empty")
+	}
+
+	seen := make(map[int]bool, len(ids))
+	pool := make([]int, 0, len(ids))
+	var unknown []string
+
+	for _, id := range ids {
+		if _, ok := idx.Games[id]; !ok {
+			unknown = append(unknown, strconv.Itoa(id))
+			continue
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		pool = append(pool, id)
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown game IDs: %s", strings.Join(unknown, ", "))
+	}
+
+	return pool, nil
+}
+
+// PoolFilter restricts a session's candidates, and so its secret, e.g.
+// "only Nintendo Switch games from 2015 on". Zero values match
+// everything; a game matches Platforms or MainGenres with any one of the
+// listed values.
+type PoolFilter struct {
+	YearFrom   int      `json:"yearFrom"`
+	YearTo     int      `json:"yearTo"`
+	Platforms  []string `json:"platforms"`
+	MainGenres []string `json:"mainGenres"`
+}
+
+func (f PoolFilter) Matches(g *Game) bool {
+	switch {
+	case f.YearFrom != 0 && g.Year < f.YearFrom:
+		return false
+	case f.YearTo != 0 && g.Year > f.YearTo:
+		return false
+	case len(f.MainGenres) > 0 && !stringSliceContains(f.MainGenres, g.MainGenre):
+		return false
+	}
+
+	if len(f.Platforms) == 0 {
+		return true
+	}
+	for _, p := range g.Platforms {
+		if stringSliceContains(f.Platforms, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterPool keeps the IDs whose games match f, in their original order.
+// Each condition is looked up in the index's inverted indexes and the
+// sorted ID lists intersected, instead of checking every game.
+func FilterPool(idx GameIndex, ids []int, f PoolFilter) []int {
+	if idx.ByYear == nil {
+		// An index not built by NewGameIndex.
+		pool := make([]int, 0, len(ids))
+		for _, id := range ids {
+			if f.Matches(idx.Games[id]) {
+				pool = append(pool, id)
+			}
+		}
+		return pool
+	}
+
+	var lists [][]int
+	if f.YearFrom != 0 || f.YearTo != 0 {
+		years := slices.Clone(idx.YearRange(f.YearFrom, f.YearTo))
+		slices.Sort(years)
+		lists = append(lists, years)
+	}
+	if len(f.Platforms) > 0 {
+		lists = append(lists, unionOf(idx.ByPlatform, f.Platforms))
+	}
+	if len(f.MainGenres) > 0 {
+		lists = append(lists, unionOf(idx.ByMainGenre, f.MainGenres))
+	}
+	if len(lists) == 0 {
+		return slices.Clone(ids)
+	}
+	matching := intersectAll(lists)
+
+	pool := make([]int, 0, min(len(ids), len(matching)))
+	for _, id := range ids {
+		if _, ok := slices.BinarySearch(matching, id); ok {
+			pool = append(pool, id)
+		}
+	}
+	return pool
+}
+
+// unionOf is the sorted IDs indexed under any of values.
+func unionOf(index map[string][]int, values []string) []int {
+	if len(values) == 1 {
+		return index[strings.ToLower(values[0])]
+	}
+	var ids []int
+	for _, v := range values {
+		ids = append(ids, index[strings.ToLower(v)]...)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// intersectAll is the IDs in every one of the sorted lists, smallest
+// list first so the merges stay short.
+func intersectAll(lists [][]int) []int {
+	slices.SortFunc(lists, func(a, b []int) int { return len(a) - len(b) })
+	out := lists[0]
+	for _, list := range lists[1:] {
+		out = intersectSorted(out, list)
+	}
+	return out
+}
 
-	remaining := make([]int, len(idx.AllGameIDs))
-	copy(remaining, idx.AllGameIDs)
+// intersectSorted is the IDs in both sorted lists a and b.
+func intersectSorted(a, b []int) []int {
+	var out []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSessionStateFromPool starts a session whose candidates are exactly
+// pool, with secretID (which must be in pool) as the target.
+func NewSessionStateFromPool(pool []int, secretID int) SessionState {
+	remaining := make([]int, len(pool))
+	copy(remaining, pool)
 
 	return SessionState{
+		Mode:         ModeClassic,
 		RemainingIDs: remaining,
 		SecretID:     secretID,
+		Status:       statusFor(remaining),
+		PoolSize:     len(pool),
+		Timeline:     []int{len(pool)},
+		StartedAt:    time.Now(),
 	}
 }
 
+// finish ends the session with the given outcome.
+func finish(state *SessionState, outcome Outcome) {
+	state.Status = StatusFinished
+	state.Outcome = outcome
+	state.FinishedAt = time.Now()
+}
+
+// statusFor moves an active session into the final-guess state once its
+// candidate pool has collapsed to a single game.
+func statusFor(remaining []int) SessionStatus {
+	if len(remaining) == 1 {
+		return StatusFinalGuess
+	}
+	return StatusActive
+}
+
 // -----------------------------
 // Apply single question
 // -----------------------------
@@ -41,39 +264,111 @@ func ApplyQuestion(
 	idx GameIndex,
 	value string,
 ) (SessionState, bool) {
-	secretGame := idx.Games[state.SecretID]
-
-	var answer bool
-
-	if template.CheckString != nil {
-		answer = template.CheckString(secretGame, value)
-	} else if template.CheckBool != nil {
-		answer = template.CheckBool(secretGame)
-	} else {
+	if !template.HasLogic() {
 		// No logic defined: treat as false and do not change remaining IDs.
 		return state, false
 	}
 
-	filtered := make([]int, 0, len(state.RemainingIDs))
+	answer := idx.matches(template, value, state.SecretID)
+	return ApplyAnswer(state, template, idx, value, answer), answer
+}
 
-	for _, id := range state.RemainingIDs {
-		game := idx.Games[id]
+// ApplyNegatedQuestion asks the opposite of the question. Its answer is
+// the inverse of ApplyQuestion's, and the candidates kept are those that
+// would give that inverted answer to the inverted question, i.e. the same
+// ones; only the history records it as negated.
+func ApplyNegatedQuestion(
+	state SessionState,
+	template QuestionTemplate,
+	idx GameIndex,
+	value string,
+) (SessionState, bool) {
+	if !template.HasLogic() {
+		return state, false
+	}
+
+	state, answer := ApplyQuestion(state, template, idx, value)
+	last := &state.Asked[len(state.Asked)-1]
+	last.Answer = !answer
+	last.Negated = true
+	return state, !answer
+}
 
-		var match bool
+// ApplyAnswer keeps the candidates that would answer the question the way
+// the player did. ApplyQuestion uses it with the secret's answer; reverse
+// mode, where there is no secret, uses it with the player's. With a
+// tolerance a contradicting candidate is only penalized until it has
+// contradicted more than state.Tolerance answers.
+func ApplyAnswer(
+	state SessionState,
+	template QuestionTemplate,
+	idx GameIndex,
+	value string,
+	answer bool,
+) SessionState {
+	var filtered []int
 
-		if template.CheckString != nil {
-			match = template.CheckString(game, value)
-		} else if template.CheckBool != nil {
-			match = template.CheckBool(game)
+	var penalties map[int]int
+	if state.Tolerance > 0 {
+		// Copy so the caller's state is left as it was.
+		penalties = make(map[int]int, len(state.Penalties))
+		for id, n := range state.Penalties {
+			penalties[id] = n
 		}
+	}
 
-		if match == answer {
-			filtered = append(filtered, id)
+	switch {
+	case penalties != nil:
+		filtered = make([]int, 0, len(state.RemainingIDs))
+		for _, id := range state.RemainingIDs {
+			if idx.matches(template, value, id) == answer {
+				filtered = append(filtered, id)
+				continue
+			}
+			penalties[id]++
+			if penalties[id] <= state.Tolerance {
+				filtered = append(filtered, id)
+			} else {
+				delete(penalties, id)
+			}
 		}
+		state.Penalties = penalties
+	default:
+		filtered = filterCandidates(idx, template, value, answer, state.RemainingIDs)
 	}
 
 	state.RemainingIDs = filtered
-	return state, answer
+	state.Status = statusFor(filtered)
+	state.Asked = append(state.Asked, AskedQuestion{
+		QuestionTypeID:  template.ID,
+		Option:          value,
+		Answer:          answer,
+		CandidatesAfter: len(filtered),
+	})
+	state.Timeline = append(state.Timeline, len(filtered))
+	return state
+}
+
+// filterCandidates keeps the ids that answer the question answer, with the
+// answer matrix's bitsets when it covers the option.
+func filterCandidates(idx GameIndex, template QuestionTemplate, value string, answer bool, ids []int) []int {
+	if idx.Answers != nil {
+		if filtered, ok := idx.Answers.Filter(template, value, answer, ids); ok {
+			return filtered
+		}
+	}
+	return filterCandidatesScan(idx, template, value, answer, ids)
+}
+
+// filterCandidatesScan is the per-game fallback for filterCandidates.
+func filterCandidatesScan(idx GameIndex, template QuestionTemplate, value string, answer bool, ids []int) []int {
+	filtered := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if idx.matches(template, value, id) == answer {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
 }
 
 // -----------------------------
@@ -96,3 +391,189 @@ func BuildQuestionTypeDefs(templates []QuestionTemplate) []QuestionTypeDef {
 
 	return result
 }
+
+// countMatches returns how many of ids answer "yes" to the question.
+func countMatches(template QuestionTemplate, value string, ids []int, idx GameIndex) int {
+	yes := 0
+	for _, id := range ids {
+		if idx.matches(template, value, id) {
+			yes++
+		}
+	}
+	return yes
+}
+
+// splitPower scores a yes/no split: 1 when perfectly even, 0 when one-sided.
+func splitPower(yes, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	no := total - yes
+	diff := yes - no
+	if diff < 0 {
+		diff = -diff
+	}
+	return 1 - float64(diff)/float64(total)
+}
+
+func questionOption(state SessionState, template QuestionTemplate, value string, idx GameIndex) QuestionOption {
+	total := len(state.RemainingIDs)
+	yes := countMatches(template, value, state.RemainingIDs, idx)
+
+	return QuestionOption{
+		Value:    value,
+		YesCount: yes,
+		NoCount:  total - yes,
+		Power:    splitPower(yes, total),
+	}
+}
+
+// RemainingQuestions lists every template option not yet asked in the
+// session, scored against the current candidates. Pure yes/no templates
+// carry a single option with an empty value.
+func RemainingQuestions(state SessionState, templates []QuestionTemplate, idx GameIndex) []SessionQuestion {
+	result := make([]SessionQuestion, 0, len(templates))
+
+	for _, t := range templates {
+		if !t.HasLogic() {
+			continue
+		}
+
+		values := t.Values
+		if len(values) == 0 {
+			values = []string{""}
+		}
+
+		options := make([]QuestionOption, 0, len(values))
+		for _, v := range values {
+			if state.WasAsked(t.ID, v) {
+				continue
+			}
+			options = append(options, questionOption(state, t, v, idx))
+		}
+
+		if len(options) > 0 {
+			result = append(result, SessionQuestion{ID: t.ID, Category: t.Category, Options: options})
+		}
+	}
+
+	return result
+}
+
+// SplitPreview scores every option of one template against the remaining
+// candidates, asked or not, so a client can show which are worth asking.
+func SplitPreview(state SessionState, template QuestionTemplate, idx GameIndex) []QuestionOption {
+	values := template.Values
+	if len(values) == 0 {
+		values = []string{""}
+	}
+
+	options := make([]QuestionOption, 0, len(values))
+	for _, v := range values {
+		option := questionOption(state, template, v, idx)
+		option.Asked = state.WasAsked(template.ID, v)
+		options = append(options, option)
+	}
+	return options
+}
+
+// isUseful reports whether asking the question could still change state:
+// it has not been asked yet and splits the remaining candidates.
+func isUseful(state SessionState, template QuestionTemplate, value string, idx GameIndex) bool {
+	if !template.HasLogic() || state.WasAsked(template.ID, value) {
+		return false
+	}
+
+	yes := countMatches(template, value, state.RemainingIDs, idx)
+	return yes > 0 && yes < len(state.RemainingIDs)
+}
+
+// SessionQuestionTypeDefs is BuildQuestionTypeDefs narrowed to one
+// session: values that were already asked, or that every remaining
+// candidate would answer the same way, are dropped, and templates left
+// with nothing to ask are omitted entirely.
+func SessionQuestionTypeDefs(state SessionState, templates []QuestionTemplate, idx GameIndex) []QuestionTypeDef {
+	result := make([]QuestionTypeDef, 0, len(templates))
+
+	for _, t := range templates {
+		if len(t.Values) == 0 {
+			if isUseful(state, t, "", idx) {
+				result = append(result, QuestionTypeDef{ID: t.ID, Category: t.Category, Values: t.Values})
+			}
+			continue
+		}
+
+		values := make([]string, 0, len(t.Values))
+		for _, v := range t.Values {
+			if isUseful(state, t, v, idx) {
+				values = append(values, v)
+			}
+		}
+
+		if len(values) > 0 {
+			result = append(result, QuestionTypeDef{ID: t.ID, Category: t.Category, Values: values})
+		}
+	}
+
+	return result
+}
+
+// -----------------------------
+// Solver (reverse mode)
+// -----------------------------
+
+// RankQuestions scores every unasked template option by its information
+// gain over the remaining candidates, best first; ties keep template
+// order. Answers are deterministic, so the gain is just the entropy of the
+// yes/no split. Options that no longer split the candidates are left out.
+func RankQuestions(state SessionState, templates []QuestionTemplate, idx GameIndex) []RankedQuestion {
+	var ranked []RankedQuestion
+
+	total := len(state.RemainingIDs)
+	for _, t := range templates {
+		if !t.HasLogic() {
+			continue
+		}
+
+		values := t.Values
+		if t.CheckString == nil {
+			values = []string{""}
+		}
+
+		for _, v := range values {
+			if state.WasAsked(t.ID, v) {
+				continue
+			}
+			yes := countMatches(t, v, state.RemainingIDs, idx)
+			if yes == 0 || yes == total {
+				continue
+			}
+			ranked = append(ranked, RankedQuestion{
+				QuestionTypeID: t.ID,
+				Category:       t.Category,
+				Option:         v,
+				YesCount:       yes,
+				NoCount:        total - yes,
+				Gain:           binaryEntropy(yes, total),
+			})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Gain > ranked[j].Gain
+	})
+	return ranked
+}
+
+// NextQuestion picks the unasked template option with the highest
+// information gain over the remaining candidates. ok is false when no
+// option splits the candidates any more.
+func NextQuestion(state SessionState, templates []QuestionTemplate, idx GameIndex) (QuestionTemplate, string, bool) {
+	ranked := RankQuestions(state, templates, idx)
+	if len(ranked) == 0 {
+		return QuestionTemplate{}, "", false
+	}
+
+	t, ok := findTemplate(templates, ranked[0].QuestionTypeID)
+	return t, ranked[0].Option, ok
+}
@@ -0,0 +1,187 @@
+package guesser
+
+import (
+	"slices"
+	"testing"
+)
+
+// testIndex is a small catalog with popularity ratings.
+func testIndex() GameIndex {
+	return NewGameIndex([]Game{
+		{ID: 1, Name: "Alpha", Year: 2010, Platforms: []string{"PC"}, Genres: []string{"Action"}, MainGenre: "Action", Popularity: 40000},
+		{ID: 2, Name: "Bravo", Year: 2015, Platforms: []string{"PC", "Nintendo Switch"}, Genres: []string{"RPG", "Action"}, MainGenre: "RPG", Popularity: 100},
+		{ID: 3, Name: "Charlie", Year: 2018, Platforms: []string{"PlayStation"}, Genres: []string{"Puzzle"}, MainGenre: "Puzzle", Popularity: 100},
+		{ID: 4, Name: "Delta", Year: 2020, Platforms: []string{"Nintendo Switch"}, Genres: []string{"RPG"}, MainGenre: "RPG"},
+		{ID: 5, Name: "Echo", Year: 2022, Platforms: []string{"PC", "Xbox"}, Genres: []string{"Action", "Shooter"}, MainGenre: "Shooter", Popularity: 2500},
+	})
+}
+
+func TestRandomSecretSeeded(t *testing.T) {
+	idx := testIndex()
+	a, b := SeededPicker(7), SeededPicker(7)
+	for i := 0; i < 50; i++ {
+		x, y := RandomSecret(a, idx, idx.AllGameIDs), RandomSecret(b, idx, idx.AllGameIDs)
+		if x != y {
+			t.Fatalf("draw %d: same seed gave %d and %d", i, x, y)
+		}
+	}
+}
+
+func TestRandomSecretWeighting(t *testing.T) {
+	idx := testIndex()
+	rng := SeededPicker(1)
+	counts := map[int]int{}
+	for i := 0; i < 5000; i++ {
+		counts[RandomSecret(rng, idx, idx.AllGameIDs)]++
+	}
+
+	for _, id := range idx.AllGameIDs {
+		if counts[id] == 0 {
+			t.Errorf("game %d never drawn", id)
+		}
+	}
+	// sqrt(40000) is 20 times sqrt(100).
+	if counts[1] < 10*counts[2] {
+		t.Errorf("popular game drawn %d times, obscure one %d", counts[1], counts[2])
+	}
+	// Delta has no rating and weighs as much as the least popular game.
+	if counts[4] < counts[2]/2 || counts[4] > counts[2]*2 {
+		t.Errorf("unrated game drawn %d times, least popular %d", counts[4], counts[2])
+	}
+}
+
+func TestRandomSecretUniformWithoutPopularity(t *testing.T) {
+	idx := NewGameIndex([]Game{{ID: 1, Name: "A"}, {ID: 2, Name: "B"}, {ID: 3, Name: "C"}, {ID: 4, Name: "D"}})
+	rng := SeededPicker(3)
+	counts := map[int]int{}
+	for i := 0; i < 4000; i++ {
+		counts[RandomSecret(rng, idx, idx.AllGameIDs)]++
+	}
+	for _, id := range idx.AllGameIDs {
+		if counts[id] < 800 || counts[id] > 1200 {
+			t.Errorf("game %d drawn %d times of 4000", id, counts[id])
+		}
+	}
+}
+
+func TestNewSessionState(t *testing.T) {
+	idx := testIndex()
+	state := NewSessionState(idx, SeededPicker(42))
+	if !slices.Contains(idx.AllGameIDs, state.SecretID) {
+		t.Fatalf("secret %d not in the catalog", state.SecretID)
+	}
+	if !slices.Equal(state.RemainingIDs, idx.AllGameIDs) || state.PoolSize != len(idx.AllGameIDs) {
+		t.Errorf("candidates = %v, want every game", state.RemainingIDs)
+	}
+	if state.Status != StatusActive || state.Mode != ModeClassic {
+		t.Errorf("status %q, mode %q", state.Status, state.Mode)
+	}
+	if again := NewSessionState(idx, SeededPicker(42)); again.SecretID != state.SecretID {
+		t.Errorf("same seed drew %d, then %d", state.SecretID, again.SecretID)
+	}
+
+	empty := NewSessionState(NewGameIndex(nil), SeededPicker(42))
+	if empty.SecretID != 0 || len(empty.RemainingIDs) != 0 {
+		t.Errorf("empty catalog: secret %d, candidates %v", empty.SecretID, empty.RemainingIDs)
+	}
+}
+
+func TestFilterPool(t *testing.T) {
+	idx := testIndex()
+	reversed := slices.Clone(idx.AllGameIDs)
+	slices.Reverse(reversed)
+
+	filters := []PoolFilter{
+		{},
+		{YearFrom: 2015},
+		{YearTo: 2018},
+		{YearFrom: 2015, YearTo: 2020},
+		{Platforms: []string{"pc"}},
+		{Platforms: []string{"Nintendo Switch", "Xbox"}},
+		{MainGenres: []string{"rpg"}},
+		{MainGenres: []string{"RPG", "shooter"}, Platforms: []string{"PC"}},
+		{YearFrom: 2016, Platforms: []string{"PC"}, MainGenres: []string{"Action"}},
+		{Platforms: []string{"Dreamcast"}},
+	}
+	for _, f := range filters {
+		for _, ids := range [][]int{idx.AllGameIDs, reversed, {5, 2}} {
+			var want []int
+			for _, id := range ids {
+				if f.Matches(idx.Games[id]) {
+					want = append(want, id)
+				}
+			}
+			got := FilterPool(idx, ids, f)
+			if !slices.Equal(got, want) {
+				t.Errorf("FilterPool(%v, %+v) = %v, want %v", ids, f, got, want)
+			}
+		}
+	}
+}
+
+func TestIntersectSorted(t *testing.T) {
+	tests := []struct {
+		a, b, want []int
+	}{
+		{[]int{1, 3, 5, 7}, []int{2, 3, 4, 7, 9}, []int{3, 7}},
+		{[]int{1, 2}, []int{3, 4}, nil},
+		{nil, []int{1}, nil},
+		{[]int{4}, []int{4}, []int{4}},
+	}
+	for _, tt := range tests {
+		if got := intersectSorted(tt.a, tt.b); !slices.Equal(got, tt.want) {
+			t.Errorf("intersectSorted(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogOrder(t *testing.T) {
+	idx := testIndex()
+	for _, f := range []CatalogFilter{{}, {Genre: "action"}, {Genre: "RPG", Platform: "pc"}, {Platform: "nintendo switch"}, {Genre: "racing"}} {
+		var want []int
+		for _, id := range idx.ByName {
+			if f.Matches(idx.Games[id]) {
+				want = append(want, id)
+			}
+		}
+		var got []int
+		for _, id := range catalogOrder(idx, f) {
+			if f.Matches(idx.Games[id]) {
+				got = append(got, id)
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("catalogOrder(%+v) = %v, want %v", f, got, want)
+		}
+	}
+}
+
+// TestApplyQuestionBitsets checks that the answer matrix keeps the same
+// candidates as checking every game, for every option of the shipped
+// dataset.
+func TestApplyQuestionBitsets(t *testing.T) {
+	games, err := LoadGames("../dataset/games.json")
+	if err != nil {
+		t.Skipf("load dataset: %v", err)
+	}
+	idx, templates := indexDataset("default", games, DefaultTemplates())
+	scan := idx
+	scan.Answers = nil
+
+	// Start from a part of the catalog, as later questions do.
+	state := NewSessionStateFromPool(idx.AllGameIDs[:len(idx.AllGameIDs)/3], idx.AllGameIDs[0])
+	for _, tmpl := range templates {
+		values := tmpl.Values
+		if tmpl.CheckBool != nil {
+			values = []string{""}
+		}
+		for _, v := range values {
+			got, gotAnswer := ApplyQuestion(state, tmpl, idx, v)
+			want, wantAnswer := ApplyQuestion(state, tmpl, scan, v)
+			if gotAnswer != wantAnswer || !slices.Equal(got.RemainingIDs, want.RemainingIDs) {
+				t.Errorf("%s=%q: bitsets kept %d (answer %v), scan %d (answer %v)",
+					tmpl.ID, v, len(got.RemainingIDs), gotAnswer, len(want.RemainingIDs), wantAnswer)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package guesser
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type SimilarGamesResponse struct {
+	Game    GameSummary   `json:"game"`
+	Similar []SimilarGame `json:"similar"`
+}
+
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+)
+
+// queryInt reads a positive integer query parameter, falling back to def
+// when it is missing and clamping it to max.
+func queryInt(r *http.Request, name string, def, max int) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, true
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	if n > max {
+		n = max
+	}
+	return n, true
+}
+
+// ---------------------------------
+// /api/v1/games/{gameID}[/...]
+//   - GET (no action)  full game details
+//   - GET /similar?limit=N
+//
+// Both accept ?fields= to trim the response.
+// ---------------------------------
+
+func GamesHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["gameID"])
+		if err != nil {
+			apiNotFound(w, r)
+			return
+		}
+
+		idx := data.Current().Index
+		game, ok := idx.Games[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, CodeUnknownGame, "unknown game")
+			return
+		}
+
+		switch vars["action"] {
+		case "":
+			handleGameDetails(w, r, game)
+		case "similar":
+			handleSimilarGames(w, r, game, idx)
+		default:
+			apiNotFound(w, r)
+		}
+	})
+}
+
+func handleGameDetails(w http.ResponseWriter, r *http.Request, game *Game) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, game)
+}
+
+func handleSimilarGames(w http.ResponseWriter, r *http.Request, game *Game, idx GameIndex) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	limit, ok := queryInt(r, "limit", defaultSimilarLimit, maxSimilarLimit)
+	if !ok {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "limit must be a positive integer")
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, SimilarGamesResponse{
+		Game:    summarize(game),
+		Similar: MostSimilar(idx, game.ID, limit),
+	})
+}
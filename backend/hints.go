@@ -0,0 +1,200 @@
+package guesser
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// A stuck player can buy hints about the secret, each stronger than the
+// last and each taking Rules.HintCost off the score of a win. Hints a
+// game can't give (no developer, no cover, or no image cache to blur it
+// with) are skipped.
+
+// HintKind is one rung of the hint ladder.
+type HintKind string
+
+const (
+	HintDecade      HintKind = "decade"
+	HintFirstLetter HintKind = "first_letter"
+	HintDeveloper   HintKind = "developer"
+	// HintCover is the cover art at its current reveal stage (see
+	// coverStage), which the hint itself sharpens.
+	HintCover HintKind = "cover"
+)
+
+// hintLadder is the order hints are given in, weakest first.
+var hintLadder = []HintKind{HintDecade, HintFirstLetter, HintDeveloper, HintCover}
+
+// Hint is a hint the player has taken. ImageData is a data: URL, so the
+// cover's real address isn't given away before the game ends; GET
+// /session/{id}/cover keeps sharpening it from there.
+type Hint struct {
+	Kind      HintKind `json:"kind"`
+	Text      string   `json:"text"`
+	ImageData string   `json:"imageData,omitempty"`
+}
+
+type HintResponse struct {
+	Hint Hint `json:"hint"`
+	// HintsUsed and ScorePenalty count every hint so far, this one included.
+	HintsUsed      int `json:"hintsUsed"`
+	HintsRemaining int `json:"hintsRemaining"`
+	ScorePenalty   int `json:"scorePenalty"`
+}
+
+// hintAvailable reports whether kind can be given for g.
+func hintAvailable(kind HintKind, g *Game) bool {
+	switch kind {
+	case HintDeveloper:
+		return g.Developer != ""
+	case HintCover:
+		return coverAvailable(g)
+	}
+	return true
+}
+
+// nextHint is the next hint state may take for g, if any is left.
+func nextHint(state SessionState, g *Game) (HintKind, bool) {
+	for _, kind := range hintLadder {
+		if !slices.Contains(state.Hints, kind) && hintAvailable(kind, g) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+func hintsRemaining(state SessionState, g *Game) int {
+	n := 0
+	for _, kind := range hintLadder {
+		if !slices.Contains(state.Hints, kind) && hintAvailable(kind, g) {
+			n++
+		}
+	}
+	return n
+}
+
+// hintText words kind for g.
+func hintText(kind HintKind, g *Game) string {
+	switch kind {
+	case HintDecade:
+		return fmt.Sprintf("It came out in the %ds.", g.Year/10*10)
+	case HintFirstLetter:
+		for _, r := range g.Name {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return fmt.Sprintf("Its name starts with %q.", string(unicode.ToUpper(r)))
+			}
+		}
+		return "Its name has no letters in it."
+	case HintDeveloper:
+		text := "It was made by " + g.Developer + "."
+		if g.Developer == "Indie / Other" {
+			text = "It was made by an independent or smaller studio."
+		}
+		if g.DeveloperRegion != "" && !strings.HasPrefix(g.DeveloperRegion, "Unknown") {
+			text = strings.TrimSuffix(text, ".") + ", from " + g.DeveloperRegion + "."
+		}
+		return text
+	case HintCover:
+		return "Here is its cover, pixelated."
+	}
+	return ""
+}
+
+// coverHint returns state's cover stage (see coverStage) as a data: URL.
+func coverHint(ctx context.Context, state SessionState, g *Game) (string, error) {
+	f, err := openCoverStage(ctx, g, coverStage(state))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// obscureCover shrinks img to detail pixels wide and scales that back up
+// to width with scaler, so only its shapes and colours survive.
+func obscureCover(img image.Image, detail, width int, scaler xdraw.Scaler) image.Image {
+	b := img.Bounds()
+	small := image.NewNRGBA(image.Rect(0, 0, detail, max(1, b.Dy()*detail/b.Dx())))
+	xdraw.ApproxBiLinear.Scale(small, small.Bounds(), img, b, xdraw.Src, nil)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, max(1, b.Dy()*width/b.Dx())))
+	scaler.Scale(dst, dst.Bounds(), small, small.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// takenHints words the hints state has taken, for restoring the UI.
+func takenHints(state SessionState, g *Game) []Hint {
+	if g == nil {
+		return nil
+	}
+	hints := make([]Hint, len(state.Hints))
+	for i, kind := range state.Hints {
+		hints[i] = Hint{Kind: kind, Text: hintText(kind, g)}
+	}
+	return hints
+}
+
+// hintPenalty is what the hints taken so far cost a win.
+func hintPenalty(state SessionState) int {
+	return rules.HintCost * len(state.Hints)
+}
+
+func handleHint(w http.ResponseWriter, r *http.Request, session *Session, idx GameIndex) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+
+	if session.State.Mode == ModeReverse {
+		writeError(w, http.StatusConflict, CodeWrongMode, "in reverse mode the secret is yours: there is nothing to hint at")
+		return
+	}
+	if session.State.Status == StatusFinished {
+		writeError(w, http.StatusConflict, CodeSessionFinished, finishedMessage(session.State))
+		return
+	}
+
+	secret, ok := idx.Games[session.State.SecretID]
+	if !ok {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "secret game not found")
+		return
+	}
+	kind, ok := nextHint(session.State, secret)
+	if !ok {
+		writeError(w, http.StatusConflict, CodeNoHintsLeft, "every hint has been given")
+		return
+	}
+
+	state := session.State
+	state.Hints = append(slices.Clone(state.Hints), kind)
+	hint := Hint{Kind: kind, Text: hintText(kind, secret)}
+	if kind == HintCover {
+		data, err := coverHint(r.Context(), state, secret)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, CodeUpstream, "could not fetch the cover")
+			return
+		}
+		hint.ImageData = data
+	}
+
+	session.State = state
+	writeResponse(w, r, http.StatusOK, HintResponse{
+		Hint:           hint,
+		HintsUsed:      len(session.State.Hints),
+		HintsRemaining: hintsRemaining(session.State, secret),
+		ScorePenalty:   hintPenalty(session.State),
+	})
+}
@@ -2,117 +2,551 @@ package guesser
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"strings"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
 )
 
 // ---------------------------------
 // Shared helper
 // ---------------------------------
 
-func writeJSON(w http.ResponseWriter, status int, value any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(value)
+// decodeOptionalJSON is json decoding that treats an empty body as "no
+// options" rather than an error.
+func decodeOptionalJSON(r *http.Request, value any) error {
+	err := json.NewDecoder(r.Body).Decode(value)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
 }
 
 // ---------------------------------
 // Request / response types
 // ---------------------------------
 
+type StartSessionRequest struct {
+	// GameIDs optionally restricts the candidate pool (and the secret) to
+	// these games, e.g. "only games my friends own".
+	GameIDs []int `json:"gameIds"`
+	// Filter narrows the pool (GameIDs or the whole dataset) by year,
+	// platform and main genre.
+	Filter PoolFilter `json:"filter"`
+	// Mode is "classic" (default) or "hotcold".
+	Mode SessionMode `json:"mode"`
+	// ForceSecretID pins the secret for demos and bug reproduction.
+	// Requires the admin bearer token.
+	ForceSecretID int `json:"forceSecretId"`
+	// DatasetID picks one of GET /api/v1/datasets; empty means "default".
+	DatasetID string `json:"datasetId"`
+	// Seed replays a shared challenge code: same data, pool, secret and
+	// mode. It can't be combined with the other options.
+	Seed string `json:"seed"`
+	// Tolerance (reverse mode only) lets each candidate survive this many
+	// contradicting answers, so one mistaken answer doesn't lose the game.
+	Tolerance int `json:"tolerance"`
+	// Difficulty is "easy" (a well-known secret; only on datasets with
+	// popularity data), "normal" (default) or "hard" (any game, and no
+	// Revealing questions).
+	Difficulty SessionDifficulty `json:"difficulty"`
+	// ClientID identifies the player to API-key clients that start games
+	// for several, such as the Discord bot, so recent secrets aren't
+	// repeated. It is ignored without a key: browsers are told apart by
+	// cookie.
+	ClientID string `json:"clientId"`
+}
+
 type StartSessionResponse struct {
 	SessionID       string            `json:"sessionId"`
+	ClientToken     string            `json:"clientToken"`
+	DatasetID       string            `json:"datasetId"`
 	DatasetSize     int               `json:"datasetSize"`
 	CandidatesCount int               `json:"candidatesCount"`
-	QuestionTypes   []QuestionTypeDef `json:"questionTypes"`
+	QuestionTypes   json.RawMessage   `json:"questionTypes"` // pre-serialized []QuestionTypeDef
+	Mode            SessionMode       `json:"mode"`
+	Difficulty      SessionDifficulty `json:"difficulty,omitempty"`
+	Debug           *DebugInfo        `json:"debug,omitempty"`
 }
 
 type AskRequest struct {
 	QuestionTypeID string `json:"questionTypeId"`
 	Option         string `json:"option"`
+	// Negate asks the opposite question ("is it NOT an RPG?"). It keeps
+	// the same candidates, but the answer is flipped to match.
+	Negate bool `json:"negate,omitempty"`
+}
+
+// AlreadyAskedResponse is the 409 for a question the session has already
+// asked, negated or not, with the answer it got then.
+type AlreadyAskedResponse struct {
+	ErrorResponse
+	Previous AskedQuestion `json:"previous"`
+}
+
+// InvalidOptionResponse is the 422 for an option the question type
+// doesn't offer. ValidOptions is empty for yes/no questions, which take
+// no option.
+type InvalidOptionResponse struct {
+	ErrorResponse
+	ValidOptions []string `json:"validOptions"`
 }
 
 type AskResponse struct {
 	Answer          bool `json:"answer"`
 	CandidatesCount int  `json:"candidatesCount"`
+	EliminatedCount int  `json:"eliminatedCount"`
+	// QuestionText echoes the question as the player would read it.
+	QuestionText   string        `json:"questionText"`
+	QuestionNumber int           `json:"questionNumber"`
+	Status         SessionStatus `json:"status"`
+	// QuestionsRemaining counts down to the server's question limit;
+	// omitted when there is none.
+	QuestionsRemaining *int `json:"questionsRemaining,omitempty"`
+	// FinalGuessAvailable is set once a single candidate remains; the
+	// session then rejects further questions until the player guesses.
+	FinalGuessAvailable bool `json:"finalGuessAvailable"`
+	// QuestionTypes is the refreshed per-session question list, without
+	// options that were already asked or can no longer split the pool.
+	QuestionTypes []QuestionTypeDef `json:"questionTypes"`
+	Debug         *DebugInfo        `json:"debug,omitempty"`
+}
+
+type CandidatesResponse struct {
+	CandidatesCount int           `json:"candidatesCount"`
+	Candidates      []GameSummary `json:"candidates"`
+}
+
+type RemainingQuestionsResponse struct {
+	CandidatesCount int               `json:"candidatesCount"`
+	Questions       []SessionQuestion `json:"questions"`
+}
+
+type SplitPreviewResponse struct {
+	QuestionTypeID  string           `json:"questionTypeId"`
+	Category        string           `json:"category"`
+	CandidatesCount int              `json:"candidatesCount"`
+	Options         []QuestionOption `json:"options"`
+}
+
+type SuggestQuestionResponse struct {
+	CandidatesCount int              `json:"candidatesCount"`
+	Suggestions     []RankedQuestion `json:"suggestions"`
+}
+
+const (
+	defaultSuggestQuestions = 3
+	maxSuggestQuestions     = 10
+)
+
+type TimelineResponse struct {
+	Timeline []int  `json:"timeline"`
+	Text     string `json:"text"`
+}
+
+// SessionStateResponse is everything the frontend needs to rebuild the
+// game screen after a refresh. The secret is only included once the
+// session is finished.
+type SessionStateResponse struct {
+	SessionID       string            `json:"sessionId"`
+	Mode            SessionMode       `json:"mode"`
+	Status          SessionStatus     `json:"status"`
+	Outcome         Outcome           `json:"outcome,omitempty"`
+	DatasetSize     int               `json:"datasetSize"`
+	PoolSize        int               `json:"poolSize"`
+	CandidatesCount int               `json:"candidatesCount"`
+	Questions       []RecapQuestion   `json:"questions"`
+	Guesses         []GuessRecord     `json:"guesses"`
+	Timeline        []int             `json:"timeline"`
+	QuestionTypes   []QuestionTypeDef `json:"questionTypes"`
+	Pending         *PendingQuestion  `json:"pending,omitempty"`
+	StartedAt       time.Time         `json:"startedAt"`
+	FinishedAt      *time.Time        `json:"finishedAt,omitempty"`
+	Secret          *GameSummary      `json:"secret,omitempty"`
+
+	// QuestionsRemaining and GuessesRemaining are omitted when there is
+	// no limit.
+	QuestionsRemaining *int `json:"questionsRemaining,omitempty"`
+	GuessesRemaining   *int `json:"guessesRemaining,omitempty"`
+
+	// Hints are the hints taken, without the blurred cover's image data.
+	Hints []Hint `json:"hints,omitempty"`
+}
+
+type GiveUpResponse struct {
+	Game     GameSummary `json:"game"`
+	ImageURL string      `json:"imageUrl,omitempty"`
+	Recap    Recap       `json:"recap"`
 }
 
 type GuessRequest struct {
 	Guess string `json:"guess"`
+	// GameID may be sent instead of Guess when the client knows the ID,
+	// e.g. from autocomplete. Hot/cold guesses must resolve to a game.
+	GameID int `json:"gameId"`
 }
 
 type GuessResponse struct {
-	Correct bool        `json:"correct"`
-	Game    GameSummary `json:"game"`
+	Correct bool `json:"correct"`
+	// GuessesRemaining is omitted when there is no guess limit.
+	GuessesRemaining *int `json:"guessesRemaining,omitempty"`
+	// Score rates the game by questions used and wrong guesses (see
+	// Score); 0 unless it was won.
+	Score int `json:"score"`
+	// Game, Recap and Summary are filled in once the guess has ended the
+	// session.
+	Game    *GameSummary    `json:"game,omitempty"`
+	Recap   *Recap          `json:"recap,omitempty"`
+	Summary *SessionSummary `json:"summary,omitempty"`
 }
 
 // global in-memory session store
 var store = newSessionStore()
 
+// sessionTokenHeader carries the client token issued at session start;
+// every /api/v1/session/{id}/... call must send it back.
+const sessionTokenHeader = "X-Session-Token"
+
 // ---------------------------------
-// /api/session/start   (POST)
+// /api/v1/session/start   (POST)
 // ---------------------------------
 
-func StartSessionHandler(idx GameIndex, templates []QuestionTemplate) http.Handler {
+func StartSessionHandler(data *SnapshotHolder) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			methodNotAllowed(w)
+			return
+		}
+
+		var req StartSessionRequest
+		if err := decodeOptionalJSON(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidJSON, "bad json")
 			return
 		}
 
-		state := NewSessionState(idx)
-		session := store.create(state)
+		if req.Seed != "" {
+			startChallenge(w, r, data, req)
+			return
+		}
 
-		resp := StartSessionResponse{
-			SessionID:       session.ID,
-			DatasetSize:     len(idx.Games),
-			CandidatesCount: len(state.RemainingIDs),
-			QuestionTypes:   BuildQuestionTypeDefs(templates),
+		client := recentClient(w, r, req)
+		snap, state, datasetID, reqErr := newSessionState(r, data, req, recentSecrets.recent(client))
+		if reqErr != nil {
+			reqErr.write(w)
+			return
 		}
+		recentSecrets.add(client, state.SecretID)
+
+		session := store.create(tenantID(r), snap, state)
+		session.DatasetID = datasetID
+
+		resp := newStartResponse(snap, state, datasetID)
+		resp.SessionID = session.ID
+		resp.ClientToken = session.Token
 
-		writeJSON(w, http.StatusOK, resp)
+		writeResponse(w, r, http.StatusOK, resp)
 	})
 }
 
+// requestError is a client error found while validating a request, for
+// helpers shared by several handlers.
+type requestError struct {
+	status int
+	code   ErrorCode
+	msg    string
+}
+
+func badRequest(msg string) *requestError {
+	return &requestError{http.StatusBadRequest, CodeInvalidRequest, msg}
+}
+
+func (e *requestError) write(w http.ResponseWriter) {
+	writeError(w, e.status, e.code, e.msg)
+}
+
+// newSessionState validates a start request (dataset, pool, filter, mode,
+// tolerance) and sets up the new game, without storing it anywhere. The
+// secret is not one of recent unless the pool has nothing else.
+func newSessionState(r *http.Request, data *SnapshotHolder, req StartSessionRequest, recent []int) (*Snapshot, SessionState, string, *requestError) {
+	if !keyAllowsDataset(r, req.DatasetID) {
+		return nil, SessionState{}, "", &requestError{http.StatusForbidden, CodeForbidden, "this API key may not use that dataset"}
+	}
+	holder, ok := selectDataset(r, data, req.DatasetID)
+	if !ok {
+		return nil, SessionState{}, "", &requestError{http.StatusBadRequest, CodeUnknownDataset, "unknown dataset"}
+	}
+	snap := holder.Current()
+	idx := snap.Index
+	datasetID := req.DatasetID
+	if datasetID == "" {
+		datasetID = defaultDatasetID
+	}
+
+	if req.ForceSecretID != 0 && !isAdmin(r) {
+		return nil, SessionState{}, "", &requestError{http.StatusForbidden, CodeForbidden, "forceSecretId requires admin credentials"}
+	}
+
+	pool := idx.AllGameIDs
+	if req.GameIDs != nil {
+		validated, err := ValidatePool(idx, req.GameIDs)
+		if err != nil {
+			return nil, SessionState{}, "", badRequest(err.Error())
+		}
+		pool = validated
+	}
+
+	f := req.Filter
+	if f.YearFrom != 0 && f.YearTo != 0 && f.YearFrom > f.YearTo {
+		return nil, SessionState{}, "", badRequest("filter.yearFrom must not be after filter.yearTo")
+	}
+	pool = FilterPool(idx, pool, f)
+	if len(pool) == 0 {
+		return nil, SessionState{}, "", badRequest("no games match the filter")
+	}
+
+	if !validDifficulty(req.Difficulty) {
+		return nil, SessionState{}, "", badRequest("difficulty must be easy, normal or hard")
+	}
+	if req.Difficulty == DifficultyEasy && !idx.hasPopularity() {
+		return nil, SessionState{}, "", badRequest("this dataset has no popularity data, so no easy difficulty")
+	}
+	secretID := secretFor(secretPicker, idx, withoutRecent(pool, recent), req.Difficulty)
+	if req.ForceSecretID != 0 {
+		if !containsID(pool, req.ForceSecretID) {
+			return nil, SessionState{}, "", badRequest("forceSecretId is not in the candidate pool")
+		}
+		secretID = req.ForceSecretID
+	}
+
+	state := NewSessionStateFromPool(pool, secretID)
+	state.Difficulty = req.Difficulty
+
+	if req.Tolerance != 0 {
+		if req.Mode != ModeReverse {
+			return nil, SessionState{}, "", badRequest("tolerance is only available in reverse mode")
+		}
+		if req.Tolerance < 0 || req.Tolerance > maxTolerance {
+			return nil, SessionState{}, "", badRequest(fmt.Sprintf("tolerance must be between 0 and %d", maxTolerance))
+		}
+		state.Tolerance = req.Tolerance
+	}
+
+	switch req.Mode {
+	case "", ModeClassic:
+	case ModeHotCold:
+		state.Mode = ModeHotCold
+	case ModeReverse:
+		// The player holds the secret; the server only keeps candidates.
+		if req.ForceSecretID != 0 {
+			return nil, SessionState{}, "", badRequest("reverse sessions have no secret to force")
+		}
+		if req.Difficulty != "" {
+			return nil, SessionState{}, "", badRequest("reverse sessions have no difficulty")
+		}
+		state.Mode = ModeReverse
+		state.SecretID = 0
+	default:
+		return nil, SessionState{}, "", badRequest("unknown mode")
+	}
+
+	return snap, state, datasetID, nil
+}
+
+// newStartResponse describes a new game; the caller fills in how the
+// client addresses it.
+func newStartResponse(snap *Snapshot, state SessionState, datasetID string) StartSessionResponse {
+	idx := snap.Index
+	resp := StartSessionResponse{
+		DatasetID:       datasetID,
+		DatasetSize:     len(idx.Games),
+		CandidatesCount: len(state.RemainingIDs),
+		QuestionTypes:   sessionQuestionTypes(snap, state),
+		Mode:            state.Mode,
+		Difficulty:      state.Difficulty,
+		Debug:           buildDebugInfo(state, idx, nil),
+	}
+	if state.Mode == ModeHotCold || state.Mode == ModeReverse {
+		resp.QuestionTypes = json.RawMessage("[]")
+	}
+	return resp
+}
+
 // ---------------------------------
-// /api/session/{sessionID}/...
+// /api/v1/session/{sessionID}[/...]
+//   - GET  (no action)      full state, for restoring the UI
 //   - POST /ask
 //   - POST /guess
+//   - POST /giveup
+//   - POST /challenge       (finished games: share as a seed code)
+//   - GET  /next-question   (reverse mode)
+//   - POST /answer          (reverse mode)
+//   - GET  /candidates
+//   - GET  /questions
+//   - GET  /split?questionTypeId=ID
+//   - GET  /suggest-question?limit=N
+//   - GET  /timeline
+//   - POST /hint            (the next, stronger hint; costs score)
+//   - GET  /cover           (the secret's cover, sharpening as the game goes)
+//   - GET  /share?format=F  (finished games: spoiler-free emoji grid or PNG)
+//   - GET  /image-url?image=cover|share  (a short-lived <img> link to either)
 // ---------------------------------
 
-func SessionHandler(idx GameIndex, templates []QuestionTemplate) http.Handler {
+func SessionHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Strip the prefix "/api/session/"
-		path := strings.TrimPrefix(r.URL.Path, "/api/session/")
-		if path == "" {
-			http.NotFound(w, r)
+		vars := mux.Vars(r)
+		token := r.Header.Get(sessionTokenHeader)
+
+		var session *Session
+		var ok bool
+		if token == "" && signedImageRequest(r, vars["sessionID"], vars["action"]) {
+			// An <img> src from image-url.
+			session, ok = lookupSession(w, r, vars["sessionID"])
+		} else {
+			session, ok = authorizeSession(w, r, vars["sessionID"], token)
+		}
+		if !ok {
 			return
 		}
-
-		parts := strings.Split(path, "/")
-		if len(parts) != 2 {
-			http.NotFound(w, r)
+		if vars["action"] == "image-url" {
+			// Not in serveSessionAction: stateless sessions have nothing
+			// to sign a link for.
+			handleImageURL(w, r, session)
 			return
 		}
 
-		sessionID := parts[0]
-		action := parts[1]
+		session.mu.Lock()
+		defer session.mu.Unlock()
+		session.touch(time.Now())
 
-		session, ok := store.get(sessionID)
-		if !ok {
-			http.Error(w, "unknown session", http.StatusNotFound)
-			return
-		}
+		serveSessionAction(w, r, session, vars["action"])
+	})
+}
 
-		switch action {
-		case "ask":
-			handleAsk(w, r, session, idx, templates)
-		case "guess":
-			handleGuess(w, r, session, idx)
-		default:
-			http.NotFound(w, r)
+// authorizeSession looks up the session for this tenant and checks its
+// client token, answering the request itself when either fails.
+func authorizeSession(w http.ResponseWriter, r *http.Request, sessionID, token string) (*Session, bool) {
+	session, ok := lookupSession(w, r, sessionID)
+	if !ok {
+		return nil, false
+	}
+	if !session.Authorized(token) {
+		writeError(w, http.StatusForbidden, CodeInvalidSessionToken, "missing or invalid session token")
+		return nil, false
+	}
+	return session, true
+}
+
+// lookupSession is authorizeSession without the token check.
+func lookupSession(w http.ResponseWriter, r *http.Request, sessionID string) (*Session, bool) {
+	session, err := store.get(sessionID)
+	if errors.Is(err, ErrSessionExpired) {
+		writeError(w, http.StatusGone, CodeSessionExpired, "session expired")
+		return nil, false
+	}
+	if err != nil || session.Tenant != tenantID(r) {
+		writeError(w, http.StatusNotFound, CodeUnknownSession, "unknown session")
+		return nil, false
+	}
+	return session, true
+}
+
+// serveSessionAction runs one /api/v1/session/{id}/{action} request. The
+// caller holds session.mu.
+func serveSessionAction(w http.ResponseWriter, r *http.Request, session *Session, action string) {
+	// Play against the data the session started with, even if the
+	// dataset has been reloaded since.
+	idx, templates := session.Snapshot.Index, sessionTemplates(session.State, session.Snapshot.Templates)
+
+	switch action {
+	case "":
+		handleSessionState(w, r, session, idx, templates)
+	case "ask":
+		handleAsk(w, r, session, idx, templates)
+	case "guess":
+		handleGuess(w, r, session, idx, templates)
+	case "giveup":
+		handleGiveUp(w, r, session, idx, templates)
+	case "challenge":
+		handleChallenge(w, r, session)
+	case "next-question":
+		handleNextQuestion(w, r, session, idx, templates)
+	case "answer":
+		handleAnswer(w, r, session, idx, templates)
+	case "candidates":
+		handleCandidates(w, r, session, idx)
+	case "questions":
+		handleRemainingQuestions(w, r, session, idx, templates)
+	case "split":
+		handleSplitPreview(w, r, session, idx, templates)
+	case "suggest-question":
+		handleSuggestQuestion(w, r, session, idx, templates)
+	case "timeline":
+		handleTimeline(w, r, session)
+	case "hint":
+		handleHint(w, r, session, idx)
+	case "cover":
+		handleCover(w, r, session, idx)
+	case "share":
+		handleShare(w, r, session)
+	default:
+		apiNotFound(w, r)
+	}
+}
+
+func handleSessionState(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	idx GameIndex,
+	templates []QuestionTemplate,
+) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	state := session.State
+	resp := SessionStateResponse{
+		SessionID:       session.ID,
+		Mode:            state.Mode,
+		Status:          state.Status,
+		Outcome:         state.Outcome,
+		DatasetSize:     len(idx.Games),
+		PoolSize:        state.PoolSize,
+		CandidatesCount: len(state.RemainingIDs),
+		Questions:       questionHistory(state, templates),
+		Guesses:         state.Guesses,
+		Timeline:        candidateTimeline(state),
+		QuestionTypes:   []QuestionTypeDef{},
+		Hints:           takenHints(state, idx.Games[state.SecretID]),
+		Pending:         state.Pending,
+		StartedAt:       state.StartedAt,
+	}
+	if state.Mode == ModeClassic {
+		resp.QuestionsRemaining = questionsRemaining(state)
+		resp.GuessesRemaining = guessesRemaining(state)
+	}
+	if resp.Guesses == nil {
+		resp.Guesses = []GuessRecord{}
+	}
+	if state.Mode == ModeClassic && state.Status != StatusFinished {
+		resp.QuestionTypes = SessionQuestionTypeDefs(state, templates, idx)
+	}
+	if state.Status == StatusFinished {
+		finishedAt := state.FinishedAt
+		resp.FinishedAt = &finishedAt
+		if secret, ok := idx.Games[state.SecretID]; ok {
+			summary := summarize(secret)
+			resp.Secret = &summary
 		}
-	})
+	}
+
+	writeResponse(w, r, http.StatusOK, resp)
 }
 
 func handleAsk(
@@ -123,13 +557,37 @@ func handleAsk(
 	templates []QuestionTemplate,
 ) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w)
+		return
+	}
+
+	switch session.State.Mode {
+	case ModeHotCold:
+		writeError(w, http.StatusConflict, CodeWrongMode, "hot/cold sessions have no questions: guess instead")
+		return
+	case ModeReverse:
+		writeError(w, http.StatusConflict, CodeWrongMode, "in reverse mode the server asks: use next-question")
+		return
+	}
+
+	switch session.State.Status {
+	case StatusFinalGuess:
+		writeError(w, http.StatusConflict, CodeFinalGuessRequired, "only one candidate left: make your final guess")
+		return
+	case StatusFinished:
+		writeError(w, http.StatusConflict, CodeSessionFinished, finishedMessage(session.State))
+		return
+	}
+
+	if left := questionsRemaining(session.State); left != nil && *left == 0 {
+		msg := fmt.Sprintf("question limit of %d reached: make your guess", rules.MaxQuestions)
+		writeError(w, http.StatusConflict, CodeQuestionLimitReached, msg)
 		return
 	}
 
 	var req AskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "bad json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "bad json")
 		return
 	}
 
@@ -146,19 +604,72 @@ func handleAsk(
 	}
 
 	if !found {
-		http.Error(w, "unknown questionTypeId", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidQuestion, "unknown questionTypeId")
+		return
+	}
+
+	if !slices.Contains(tmpl.Values, req.Option) && !(len(tmpl.Values) == 0 && req.Option == "") {
+		msg := fmt.Sprintf("%q is not an option of %s", req.Option, tmpl.ID)
+		if len(tmpl.Values) == 0 {
+			msg = tmpl.ID + " is a yes/no question and takes no option"
+		}
+		writeJSON(w, http.StatusUnprocessableEntity, InvalidOptionResponse{
+			ErrorResponse: newErrorResponse(CodeInvalidOption, msg),
+			ValidOptions:  append([]string{}, tmpl.Values...),
+		})
+		return
+	}
+
+	if prev, ok := session.State.askedQuestion(tmpl.ID, req.Option); ok {
+		writeJSON(w, http.StatusConflict, AlreadyAskedResponse{
+			ErrorResponse: newErrorResponse(CodeAlreadyAsked, "that question was already asked"),
+			Previous:      prev,
+		})
 		return
 	}
 
-	newState, answer := ApplyQuestion(session.State, tmpl, idx, req.Option)
+	before := len(session.State.RemainingIDs)
+	apply := ApplyQuestion
+	if req.Negate {
+		apply = ApplyNegatedQuestion
+	}
+	newState, answer := apply(session.State, tmpl, idx, req.Option)
 	session.State = newState
 
+	text := tmpl.Text(req.Option)
+	if req.Negate {
+		text = tmpl.NegatedText(req.Option)
+	}
+
 	resp := AskResponse{
-		Answer:          answer,
-		CandidatesCount: len(newState.RemainingIDs),
+		Answer:              answer,
+		CandidatesCount:     len(newState.RemainingIDs),
+		EliminatedCount:     before - len(newState.RemainingIDs),
+		QuestionText:        text,
+		QuestionNumber:      len(newState.Asked),
+		Status:              newState.Status,
+		FinalGuessAvailable: newState.Status == StatusFinalGuess,
+		QuestionTypes:       SessionQuestionTypeDefs(newState, templates, idx),
+		QuestionsRemaining:  questionsRemaining(newState),
+	}
+	if left := resp.QuestionsRemaining; left != nil && *left == 0 {
+		resp.QuestionTypes = []QuestionTypeDef{}
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	if debugMode {
+		yes := len(newState.RemainingIDs)
+		if !answer {
+			yes = before - yes
+		}
+		resp.Debug = buildDebugInfo(newState, idx, &DebugSplit{
+			Yes:             yes,
+			No:              before - yes,
+			InformationBits: binaryEntropy(yes, before),
+		})
+	}
+
+	notifyRoomProgress(session)
+	writeResponse(w, r, http.StatusOK, resp)
 }
 
 func handleGuess(
@@ -166,34 +677,309 @@ func handleGuess(
 	r *http.Request,
 	session *Session,
 	idx GameIndex,
+	templates []QuestionTemplate,
 ) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w)
+		return
+	}
+
+	if session.State.Mode == ModeReverse {
+		writeError(w, http.StatusConflict, CodeWrongMode, "in reverse mode the server guesses: use next-question")
 		return
 	}
 
 	var req GuessRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "bad json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "bad json")
 		return
 	}
 
 	secret, ok := idx.Games[session.State.SecretID]
 	if !ok {
-		http.Error(w, "secret game not found", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "secret game not found")
 		return
 	}
 
-	correct := strings.EqualFold(req.Guess, secret.Name)
+	if session.State.Status == StatusFinished {
+		writeError(w, http.StatusConflict, CodeSessionFinished, finishedMessage(session.State))
+		return
+	}
 
-	resp := GuessResponse{
+	if session.State.Mode == ModeHotCold {
+		handleProximityGuess(w, r, req, session, idx, templates, secret)
+		return
+	}
+
+	correct := req.GameID == secret.ID || MatchesTitle(idx, req.Guess, secret, rules.GuessSimilarityThreshold)
+	session.State.Guesses = append(session.State.Guesses, GuessRecord{
+		Guess:   req.Guess,
+		GameID:  req.GameID,
 		Correct: correct,
-		Game: GameSummary{
-			ID:   secret.ID,
-			Name: secret.Name,
-			Year: secret.Year,
-		},
+	})
+
+	resp := GuessResponse{
+		Correct:          correct,
+		GuessesRemaining: guessesRemaining(session.State),
+	}
+
+	// A wrong guess rules that game out; the game goes on while guesses
+	// remain.
+	left := resp.GuessesRemaining
+	if !correct && (left == nil || *left > 0) {
+		wrongID := req.GameID
+		if g, ok := findGameByName(idx, req.Guess); wrongID == 0 && ok {
+			wrongID = g.ID
+		}
+		remaining := make([]int, 0, len(session.State.RemainingIDs))
+		for _, id := range session.State.RemainingIDs {
+			if id != wrongID {
+				remaining = append(remaining, id)
+			}
+		}
+		session.State.RemainingIDs = remaining
+		session.State.Status = statusFor(remaining)
+
+		notifyRoomProgress(session)
+		writeResponse(w, r, http.StatusOK, resp)
+		return
+	}
+
+	outcome := OutcomeLost
+	if correct {
+		outcome = OutcomeWon
+	}
+	recap := completeSession(session, outcome, idx, templates)
+
+	summary := summarize(secret)
+	resp.Game = &summary
+	resp.Score = recap.Score
+	resp.Recap = &recap
+	resp.Summary = sessionSummary(session)
+	writeResponse(w, r, http.StatusOK, resp)
+}
+
+// finishedMessage explains why a finished session refuses more moves.
+func finishedMessage(state SessionState) string {
+	switch state.Outcome {
+	case OutcomeGaveUp:
+		return "session is finished: you gave up and the secret was revealed; start a new session"
+	case OutcomeWon:
+		return "session is finished: the game was already solved"
+	default:
+		return "session is finished"
+	}
+}
+
+// handleGiveUp ends the session and reveals the secret.
+func handleGiveUp(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	idx GameIndex,
+	templates []QuestionTemplate,
+) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+
+	if session.State.Mode == ModeReverse {
+		writeError(w, http.StatusConflict, CodeWrongMode, "in reverse mode the secret is yours: there is nothing to reveal")
+		return
+	}
+	if session.State.Status == StatusFinished {
+		writeError(w, http.StatusConflict, CodeSessionFinished, finishedMessage(session.State))
+		return
+	}
+
+	secret, ok := idx.Games[session.State.SecretID]
+	if !ok {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "secret game not found")
+		return
+	}
+
+	recap := completeSession(session, OutcomeGaveUp, idx, templates)
+	writeResponse(w, r, http.StatusOK, GiveUpResponse{
+		Game:     summarize(secret),
+		ImageURL: coverURL(r, session.DatasetID, secret),
+		Recap:    recap,
+	})
+}
+
+// completeSession ends session with outcome, stores its shareable recap
+// and notifies subscribers. Every path that finishes a game goes through
+// here.
+func completeSession(session *Session, outcome Outcome, idx GameIndex, templates []QuestionTemplate) Recap {
+	finish(&session.State, outcome)
+	recap := shareRecap(session.State, idx, templates)
+	notifyGameFinished(session, recap)
+	notifyRoomProgress(session)
+	recordDailyResult(session, recap)
+	stats.record(session)
+	return recap
+}
+
+// handleCandidates lists the remaining candidates, but only once the pool
+// is small enough that naming them no longer spoils the game.
+func handleCandidates(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	idx GameIndex,
+) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	remaining := session.State.RemainingIDs
+	if len(remaining) > rules.CandidateRevealThreshold {
+		msg := fmt.Sprintf("%d candidates remain; names are revealed at %d or fewer",
+			len(remaining), rules.CandidateRevealThreshold)
+		writeError(w, http.StatusForbidden, CodeCandidatesHidden, msg)
+		return
+	}
+
+	candidates := make([]GameSummary, 0, len(remaining))
+	for _, id := range remaining {
+		candidates = append(candidates, summarize(idx.Games[id]))
 	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
 
-	writeJSON(w, http.StatusOK, resp)
+	writeResponse(w, r, http.StatusOK, CandidatesResponse{
+		CandidatesCount: len(remaining),
+		Candidates:      candidates,
+	})
+}
+
+// handleRemainingQuestions returns the unasked options with their current
+// split, so the UI can disable spent or pointless choices.
+// questionsOpen reports whether the player can still ask questions in
+// session, writing the error when they can't.
+func questionsOpen(w http.ResponseWriter, session *Session) bool {
+	switch session.State.Mode {
+	case ModeHotCold:
+		writeError(w, http.StatusConflict, CodeWrongMode, "hot/cold sessions have no questions: guess instead")
+		return false
+	case ModeReverse:
+		writeError(w, http.StatusConflict, CodeWrongMode, "in reverse mode the server asks: use next-question")
+		return false
+	}
+
+	if session.State.Status == StatusFinished {
+		writeError(w, http.StatusConflict, CodeSessionFinished, finishedMessage(session.State))
+		return false
+	}
+	return true
+}
+
+func handleRemainingQuestions(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	idx GameIndex,
+	templates []QuestionTemplate,
+) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	if !questionsOpen(w, session) {
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, RemainingQuestionsResponse{
+		CandidatesCount: len(session.State.RemainingIDs),
+		Questions:       RemainingQuestions(session.State, templates, idx),
+	})
+}
+
+// handleSplitPreview shows, for one question type, how each option would
+// split the remaining candidates.
+func handleSplitPreview(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	idx GameIndex,
+	templates []QuestionTemplate,
+) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	if !questionsOpen(w, session) {
+		return
+	}
+
+	id := r.URL.Query().Get("questionTypeId")
+	i := slices.IndexFunc(templates, func(t QuestionTemplate) bool { return t.ID == id })
+	if i < 0 || !templates[i].HasLogic() {
+		writeError(w, http.StatusBadRequest, CodeInvalidQuestion, "unknown questionTypeId")
+		return
+	}
+
+	tmpl := templates[i]
+	writeResponse(w, r, http.StatusOK, SplitPreviewResponse{
+		QuestionTypeID:  tmpl.ID,
+		Category:        tmpl.Category,
+		CandidatesCount: len(session.State.RemainingIDs),
+		Options:         SplitPreview(session.State, tmpl, idx),
+	})
+}
+
+// handleSuggestQuestion is the in-game assist: the most informative
+// questions the player could ask next.
+func handleSuggestQuestion(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	idx GameIndex,
+	templates []QuestionTemplate,
+) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	if !questionsOpen(w, session) {
+		return
+	}
+
+	limit, ok := queryInt(r, "limit", defaultSuggestQuestions, maxSuggestQuestions)
+	if !ok {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "limit must be a positive integer")
+		return
+	}
+
+	ranked := RankQuestions(session.State, templates, idx)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	if ranked == nil {
+		ranked = []RankedQuestion{}
+	}
+
+	writeResponse(w, r, http.StatusOK, SuggestQuestionResponse{
+		CandidatesCount: len(session.State.RemainingIDs),
+		Suggestions:     ranked,
+	})
+}
+
+// handleTimeline returns the candidate-count series for graphing.
+func handleTimeline(w http.ResponseWriter, r *http.Request, session *Session) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	timeline := candidateTimeline(session.State)
+	writeResponse(w, r, http.StatusOK, TimelineResponse{
+		Timeline: timeline,
+		Text:     TimelineText(timeline),
+	})
 }
@@ -0,0 +1,424 @@
+package guesser
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// With -image-cache-dir, cover art is served from /api/v1/images/{gameID}
+// instead of the image host: each image is downloaded once into the
+// directory, which is kept under -image-cache-size by evicting the least
+// recently served images. Responses then point imageUrl at the proxy.
+//
+// ?w= and ?format= ask for a smaller copy (for the guess reveal and
+// autocomplete), or another format; those are made from the cached
+// original on first request and cached alongside it. Covers are photos,
+// so a resized JPEG is the lightest option: WebP originals are decoded,
+// but nothing is encoded as WebP.
+
+const (
+	// imageMaxBytes caps one image, whatever the cache size.
+	imageMaxBytes     = 10 << 20
+	imageFetchTimeout = 10 * time.Second
+	// An image never changes under its key (see imageKey).
+	imageCacheControl = "public, max-age=604800"
+
+	// Converted copies are never wider than this, asked for or not.
+	coverMaxWidth = 1024
+	// coverMaxPixels refuses to decode images that would take more
+	// memory than any cover needs.
+	coverMaxPixels = 40_000_000
+)
+
+// coverFormats are the ?format= values, by what they're encoded with.
+var coverFormats = map[string]func(io.Writer, image.Image) error{
+	"jpeg": func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, &jpeg.Options{Quality: 85}) },
+	"png":  png.Encode,
+}
+
+var errNotAnImage = errors.New("not an image")
+
+type imageCache struct {
+	dir      string
+	maxBytes int64
+	client   *http.Client
+
+	mu       sync.Mutex
+	entries  map[string]*imageEntry
+	size     int64
+	inflight map[string]*imageFetch
+}
+
+type imageEntry struct {
+	size int64
+	used time.Time
+}
+
+// imageFetch lets concurrent requests for one image share its download.
+type imageFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// images is nil until ConfigureImageCache is called.
+var images *imageCache
+
+// ConfigureImageCache enables the image proxy, caching up to maxBytes of
+// images in dir. Images already in dir are kept, oldest first out.
+func ConfigureImageCache(dir string, maxBytes int64) error {
+	if maxBytes <= 0 {
+		return errors.New("image cache size must be positive")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	c := &imageCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		client:   &http.Client{Timeout: imageFetchTimeout},
+		entries:  map[string]*imageEntry{},
+		inflight: map[string]*imageFetch{},
+	}
+	for _, f := range files {
+		if !f.Type().IsRegular() {
+			continue
+		}
+		// Left over from a download cut short.
+		if strings.HasSuffix(f.Name(), ".tmp") {
+			os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		c.entries[f.Name()] = &imageEntry{size: info.Size(), used: info.ModTime()}
+		c.size += info.Size()
+	}
+	c.evictLocked("")
+
+	images = c
+	return nil
+}
+
+// imageKey names a game's cached image. It includes a hash of the URL, so
+// a dataset that changes the URL gets the new image.
+func imageKey(gameID int, imageURL string) string {
+	sum := sha256.Sum256([]byte(imageURL))
+	return strconv.Itoa(gameID) + "-" + hex.EncodeToString(sum[:8])
+}
+
+// open returns the cached image for key, downloading imageURL first if
+// it isn't cached yet.
+func (c *imageCache) open(ctx context.Context, key, imageURL string) (*os.File, error) {
+	err := c.fill(ctx, key, func(dst io.Writer) error { return c.download(dst, imageURL) })
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Join(c.dir, key))
+}
+
+// openVariant returns the cached copy of key's image at most width wide
+// (0 = as wide as it is) in format, making it first if needed.
+func (c *imageCache) openVariant(ctx context.Context, key, imageURL string, width int, format string) (*os.File, error) {
+	if width == 0 {
+		width = coverMaxWidth
+	}
+	variant := fmt.Sprintf("%s-w%d.%s", key, width, format)
+	err := c.fill(ctx, variant, func(dst io.Writer) error {
+		src, err := c.open(context.Background(), key, imageURL)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		img, err := decodeCover(src)
+		if err != nil {
+			return err
+		}
+		return coverFormats[format](dst, resizeCover(img, width))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Join(c.dir, variant))
+}
+
+// fill makes sure key is cached, writing it with produce if it isn't.
+// Concurrent callers for one key share a single produce.
+func (c *imageCache) fill(ctx context.Context, key string, produce func(io.Writer) error) error {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		e.used = time.Now()
+		c.mu.Unlock()
+		return nil
+	}
+	if f, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	f := &imageFetch{done: make(chan struct{})}
+	c.inflight[key] = f
+	c.mu.Unlock()
+
+	// Not ctx: the work is shared, so one caller giving up must not fail
+	// the others.
+	size, err := c.store(key, produce)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if err == nil {
+		c.entries[key] = &imageEntry{size: size, used: time.Now()}
+		c.size += size
+		c.evictLocked(key)
+	}
+	c.mu.Unlock()
+
+	f.err = err
+	close(f.done)
+	return err
+}
+
+// store writes key through a temporary file, so a failed produce leaves
+// nothing behind, and returns its size.
+func (c *imageCache) store(key string, produce func(io.Writer) error) (int64, error) {
+	tmp, err := os.CreateTemp(c.dir, key+"-*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	limit := min(int64(imageMaxBytes), c.maxBytes)
+	lw := &limitedWriter{w: tmp, n: limit}
+	err = produce(lw)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	return limit - lw.n, os.Rename(tmp.Name(), filepath.Join(c.dir, key))
+}
+
+// download copies imageURL's body to dst, refusing anything that doesn't
+// sniff as an image.
+func (c *imageCache) download(dst io.Writer, imageURL string) error {
+	resp, err := c.client.Get(imageURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: HTTP %d", imageURL, resp.StatusCode)
+	}
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(resp.Body, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	if !strings.HasPrefix(http.DetectContentType(sniff[:n]), "image/") {
+		return fmt.Errorf("%s: %w", imageURL, errNotAnImage)
+	}
+	_, err = io.Copy(dst, io.MultiReader(bytes.NewReader(sniff[:n]), resp.Body))
+	return err
+}
+
+var errImageTooBig = fmt.Errorf("image larger than %d bytes", imageMaxBytes)
+
+// limitedWriter fails writes past n bytes.
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.n {
+		return 0, errImageTooBig
+	}
+	n, err := l.w.Write(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+func decodeCover(r io.ReadSeeker) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > coverMaxPixels {
+		return nil, fmt.Errorf("%dx%d image is too large to convert", cfg.Width, cfg.Height)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// resizeCover scales img down to width, keeping its aspect ratio. It is
+// never scaled up.
+func resizeCover(img image.Image, width int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= width {
+		return img
+	}
+	height := max(1, b.Dy()*width/b.Dx())
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, xdraw.Src, nil)
+	return dst
+}
+
+// evictLocked removes the least recently served images, except keep,
+// until the cache fits. c.mu must be held.
+func (c *imageCache) evictLocked(keep string) {
+	for c.size > c.maxBytes {
+		oldest := ""
+		for k, e := range c.entries {
+			if k != keep && (oldest == "" || e.used.Before(c.entries[oldest].used)) {
+				oldest = k
+			}
+		}
+		if oldest == "" {
+			return
+		}
+		os.Remove(filepath.Join(c.dir, oldest))
+		c.size -= c.entries[oldest].size
+		delete(c.entries, oldest)
+	}
+}
+
+// coverURL is what responses give as g's imageUrl: the proxy when the
+// image cache is on, under the same tenant prefix and dataset as r's
+// session, else the image host's URL.
+func coverURL(r *http.Request, datasetID string, g *Game) string {
+	if images == nil || g.ImageURL == "" {
+		return g.ImageURL
+	}
+
+	prefix := ""
+	if i := strings.Index(r.URL.Path, "/api/"); i > 0 {
+		prefix = r.URL.Path[:i]
+	}
+	u := prefix + apiPrefix + "/images/" + strconv.Itoa(g.ID)
+	if datasetID != "" && datasetID != defaultDatasetID {
+		u += "?dataset=" + url.QueryEscape(datasetID)
+	}
+	return u
+}
+
+// ---------------------------------
+// /api/v1/images/{gameID}   (GET, ?dataset=, ?w=, ?format=)
+//
+// The game's cover art, through the image cache: as the image host has
+// it, or at most w pixels wide and as jpeg (default) or png.
+// ---------------------------------
+
+func ImageHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			methodNotAllowed(w)
+			return
+		}
+		if images == nil {
+			writeError(w, http.StatusNotFound, CodeNotFound, "the image cache is not enabled")
+			return
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["gameID"])
+		if err != nil {
+			apiNotFound(w, r)
+			return
+		}
+		datasetID := r.URL.Query().Get("dataset")
+		if !allowKeyDataset(w, r, datasetID) {
+			return
+		}
+		holder, ok := selectDataset(r, data, datasetID)
+		if !ok {
+			writeError(w, http.StatusNotFound, CodeUnknownDataset, "unknown dataset")
+			return
+		}
+		game, ok := holder.Current().Index.Games[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, CodeUnknownGame, "unknown game")
+			return
+		}
+		if game.ImageURL == "" {
+			writeError(w, http.StatusNotFound, CodeNotFound, "the game has no image")
+			return
+		}
+
+		q := r.URL.Query()
+		width := 0
+		if q.Has("w") {
+			if width, ok = queryInt(r, "w", 0, coverMaxWidth); !ok {
+				writeError(w, http.StatusBadRequest, CodeInvalidRequest, "w must be a positive number of pixels")
+				return
+			}
+		}
+		format := q.Get("format")
+		if _, known := coverFormats[format]; format != "" && !known {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "format must be jpeg or png")
+			return
+		}
+		if width != 0 && format == "" {
+			format = "jpeg"
+		}
+
+		key := imageKey(id, game.ImageURL)
+		var f *os.File
+		if format == "" {
+			f, err = images.open(r.Context(), key, game.ImageURL)
+		} else {
+			f, err = images.openVariant(r.Context(), key, game.ImageURL, width, format)
+		}
+		if err != nil {
+			slog.Warn("image fetch failed", "game", id, "err", err)
+			writeError(w, http.StatusBadGateway, CodeUpstream, "could not fetch the image")
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "could not read the image")
+			return
+		}
+
+		w.Header().Set("Cache-Control", imageCacheControl)
+		w.Header().Set("ETag", `"`+filepath.Base(f.Name())+`"`)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, "", info.ModTime(), f)
+	})
+}
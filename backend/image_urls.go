@@ -0,0 +1,93 @@
+package guesser
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// An <img> tag can't send X-Session-Token, and the token doesn't belong
+// in a URL: it would end up in logs, history and Referer headers, and it
+// plays the whole game. GET /api/v1/session/{id}/image-url hands out a
+// link instead that fetches one image of one session, the cover or the
+// share card, for imageURLTTL.
+
+// imageURLTTL is how long a signed image link works.
+const imageURLTTL = 5 * time.Minute
+
+// imageURLKey signs image links. Sessions live on the instance that
+// started them, so each process can make up its own.
+var imageURLKey = []byte(randomToken(32))
+
+// signedImages are the actions a link can be signed for, and the query
+// each is served with.
+var signedImages = map[string]url.Values{
+	"cover": {},
+	"share": {"format": {"png"}},
+}
+
+type ImageURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func imageSignature(sessionID, image string, expires int64) string {
+	mac := hmac.New(sha256.New, imageURLKey)
+	fmt.Fprintf(mac, "%s\x00%s\x00%d", sessionID, image, expires)
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// signedImageRequest reports whether r carries an unexpired link signed
+// for this session's image, asked for with the query it was signed with.
+func signedImageRequest(r *http.Request, sessionID, image string) bool {
+	query, ok := signedImages[image]
+	if !ok || r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	q := r.URL.Query()
+	for name := range query {
+		if q.Get(name) != query.Get(name) {
+			return false
+		}
+	}
+
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(q.Get("sig")), []byte(imageSignature(sessionID, image, expires)))
+}
+
+// handleImageURL is GET /api/v1/session/{id}/image-url?image=cover|share.
+func handleImageURL(w http.ResponseWriter, r *http.Request, session *Session) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	image := r.URL.Query().Get("image")
+	query, ok := signedImages[image]
+	if !ok {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "image must be cover or share")
+		return
+	}
+
+	expiresAt := time.Now().Add(imageURLTTL).Truncate(time.Second)
+	q := url.Values{}
+	for name, values := range query {
+		q[name] = values
+	}
+	q.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	q.Set("sig", imageSignature(session.ID, image, expiresAt.Unix()))
+
+	// Relative, so it works behind any host name.
+	writeJSON(w, http.StatusOK, ImageURLResponse{
+		URL:       "/api/v1/session/" + session.ID + "/" + image + "?" + q.Encode(),
+		ExpiresAt: expiresAt,
+	})
+}
@@ -0,0 +1,59 @@
+package guesser
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// maxPooledBuffer keeps the occasional huge response (a full candidate
+// list, say) from pinning its buffer in the pool forever.
+const maxPooledBuffer = 64 << 10
+
+// jsonBuffer pairs a buffer with an encoder that writes into it. The
+// encoder is never reconfigured, so it is safe to reuse along with the
+// buffer.
+type jsonBuffer struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var jsonBuffers = sync.Pool{
+	New: func() any {
+		b := &jsonBuffer{}
+		b.enc = json.NewEncoder(&b.buf)
+		return b
+	},
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	b := jsonBuffers.Get().(*jsonBuffer)
+	defer func() {
+		if b.buf.Cap() <= maxPooledBuffer {
+			b.buf.Reset()
+			jsonBuffers.Put(b)
+		}
+	}()
+
+	if err := b.enc.Encode(value); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "could not encode response")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(b.buf.Len()))
+	w.WriteHeader(status)
+	_, _ = w.Write(b.buf.Bytes())
+}
+
+// mustMarshal pre-serializes payloads that never change, such as a
+// snapshot's question definitions, so each response just copies bytes.
+func mustMarshal(value any) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,117 @@
+package guesser
+
+import (
+	"bufio"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ConfigureLogging sends all logs, including the standard log package's,
+// to stderr as JSON lines at level ("debug", "info", "warn" or "error")
+// and above.
+func ConfigureLogging(level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: l})))
+	return nil
+}
+
+// statusRecorder remembers what a handler answered, for the access log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// Hijack lets WebSocket upgrades through; the connection then belongs to
+// the handler and is logged as 101.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	s.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter { return s.ResponseWriter }
+
+// sessionIDFromPath picks the session ID out of /api/v1/session/{id}/...
+// (or the unversioned path) and /ws/session/{id}, behind any tenant
+// prefix.
+func sessionIDFromPath(path string) string {
+	rest, ok := "", false
+	if route, api := apiRoute(path); api {
+		rest, ok = strings.CutPrefix(route, "session/")
+	} else if i := strings.Index(path, "/ws/session/"); i >= 0 {
+		rest, ok = path[i+len("/ws/session/"):], true
+	}
+	if !ok {
+		return ""
+	}
+	id, _, _ := strings.Cut(rest, "/")
+	if id == "start" {
+		return ""
+	}
+	return id
+}
+
+// RequestLogMiddleware writes one access log line per request: info for
+// most, warn for 4xx and error for 5xx. WebSockets are logged when they
+// close, so their latency is the connection's lifetime.
+func RequestLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", rec.bytes),
+			slog.Float64("latencyMs", float64(time.Since(started).Microseconds())/1000),
+			slog.String("remote", clientIP(r)),
+		}
+		if id := sessionIDFromPath(r.URL.Path); id != "" {
+			attrs = append(attrs, slog.String("session", id))
+		}
+		slog.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
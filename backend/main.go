@@ -1,20 +1,258 @@
 package main
 
 import (
-    "log"
-    "net/http"
-    "github.com/gorilla/mux"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gorilla/mux"
 )
 
 func main() {
-    router := mux.NewRouter()
+	cfg, err := LoadConfig(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	if err := ConfigureLogging(cfg.LogLevel); err != nil {
+		log.Fatalf("log-level: %v", err)
+	}
+
+	if cfg.Debug {
+		slog.Warn("debug mode is on; responses reveal secrets")
+	}
+	EnableDebug(cfg.Debug)
+	if cfg.SecretSeed != 0 {
+		SetSecretPicker(SeededPicker(cfg.SecretSeed))
+	}
+
+	SetRules(cfg.Rules)
+	ConfigureTemplateValues(cfg.DeriveValues)
+	ConfigureTemplateChecks(cfg.StrictTemplates)
+	ConfigureIPRateLimits(cfg.RateLimits)
+	ConfigureSessionTTL(cfg.SessionTTL)
+	if err := ConfigureDatasetCollisions(cfg.Collisions); err != nil {
+		log.Fatalf("dataset-collisions: %v", err)
+	}
+
+	// validate reads the dataset itself, to report every bad record
+	// rather than stop at the first.
+	if len(cfg.Args) > 0 && cfg.Args[0] == "validate" {
+		os.Exit(RunValidateDataset(cfg.Args[1:], os.Stdout, cfg.Dataset))
+	}
+	// import writes a dataset rather than reading one.
+	if len(cfg.Args) > 0 && cfg.Args[0] == "import" {
+		os.Exit(RunImport(cfg.Args[1:], os.Stdout))
+	}
+
+	// Refuse to start on a missing or empty dataset: every session would
+	// otherwise get SecretID 0 and fail on the first guess.
+	games, err := LoadGames(cfg.Dataset)
+	if err != nil {
+		log.Fatalf("load dataset: %v", err)
+	}
+	slog.Info("loaded dataset", "games", len(games), "path", cfg.Dataset)
+
+	templates := DefaultTemplates()
+	if cfg.Templates != "" {
+		extra, err := LoadTemplateConfigs(cfg.Templates)
+		if err != nil {
+			log.Fatalf("load templates: %v", err)
+		}
+		templates = MergeTemplates(templates, extra)
+		slog.Info("loaded question templates", "count", len(extra), "path", cfg.Templates)
+	}
+
+	// Offline tools: check or evaluate the templates against the dataset,
+	// benchmark memory, or export the dataset, then exit.
+	if len(cfg.Args) > 0 {
+		idx, templates := indexDataset("default", games, templates)
+		switch cfg.Args[0] {
+		case "lint-templates":
+			os.Exit(RunLintTemplates(os.Stdout, idx, templates))
+		case "eval-questions":
+			os.Exit(RunQuestionEval(cfg.Args[1:], os.Stdout, idx, templates))
+		case "bench-memory":
+			os.Exit(RunMemoryBench(cfg.Args[1:], os.Stdout, games))
+		case "export-sqlite":
+			os.Exit(RunExportSQLite(cfg.Args[1:], os.Stdout, games))
+		case "export":
+			os.Exit(RunExport(cfg.Args[1:], os.Stdout, games))
+		default:
+			log.Fatalf("unknown command %q", cfg.Args[0])
+		}
+	}
+
+	// Precompute in the background; the readiness gate answers 503 on the
+	// API until the first snapshot is published.
+	data := &SnapshotHolder{}
+	ConfigureMainDataset(cfg.Dataset, data, templates)
+	published := make(chan struct{})
+	go func() {
+		if _, err := publishDataset(data, "default", games, templates); err != nil {
+			log.Fatalf("dataset: %v", err)
+		}
+		close(published)
+
+		if cfg.DatasetRefresh > 0 {
+			go WatchRemoteDatasets(cfg.DatasetRefresh)
+		}
+
+		// SIGHUP (or POST /api/v1/admin/datasets/reload) reloads the datasets
+		// for new sessions; running sessions keep the snapshot they
+		// started with. A bad file keeps the current one.
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			for _, res := range ReloadDatasets() {
+				if res.Error != "" {
+					slog.Error("reload dataset failed", "dataset", res.ID, "err", res.Error, "keepingVersion", res.Version)
+					continue
+				}
+				slog.Info("reloaded dataset", "dataset", res.ID, "games", res.GameCount, "version", res.Version)
+			}
+		}
+	}()
+
+	if cfg.Datasets != "" {
+		configs, err := LoadDatasetConfigs(cfg.Datasets)
+		if err != nil {
+			log.Fatalf("load datasets: %v", err)
+		}
+		if err := ConfigureDatasets(configs, templates); err != nil {
+			log.Fatalf("load datasets: %v", err)
+		}
+		slog.Info("loaded extra datasets", "count", len(configs))
+	}
+
+	if len(cfg.TrustedProxies) > 0 {
+		if err := SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			log.Fatalf("trusted-proxies: %v", err)
+		}
+	}
+
+	if cfg.PubSubURL != "" {
+		if err := ConfigurePubSub(cfg.PubSubURL); err != nil {
+			log.Fatalf("pubsub-url: %v", err)
+		}
+		slog.Info("cluster mode: sharing room events over pub/sub")
+	}
+
+	ConfigureSteam(os.Getenv("STEAM_API_KEY"))
+	ConfigureAdmin(os.Getenv("ADMIN_TOKEN"))
+
+	// Shared by every replica, so they agree on the daily challenge.
+	if key := os.Getenv("DAILY_SECRET"); key != "" {
+		if err := ConfigureDailySecret(key); err != nil {
+			log.Fatalf("DAILY_SECRET: %v", err)
+		}
+	} else {
+		slog.Warn("DAILY_SECRET is not set; the daily challenge changes on restart and differs between replicas")
+	}
+
+	// Shared by every replica; enables /api/v1/stateless/.
+	if key := os.Getenv("STATELESS_SESSION_KEY"); key != "" {
+		if err := ConfigureStatelessSessions(key); err != nil {
+			log.Fatalf("STATELESS_SESSION_KEY: %v", err)
+		}
+	}
+
+	if cfg.APIKeys != "" {
+		if err := LoadAPIKeys(cfg.APIKeys); err != nil {
+			log.Fatalf("load API keys: %v", err)
+		}
+	}
+
+	if len(cfg.WebhookURLs) > 0 {
+		if err := ConfigureWebhooks(cfg.WebhookURLs, os.Getenv("WEBHOOK_SECRET")); err != nil {
+			log.Fatalf("WEBHOOK_SECRET: %v", err)
+		}
+	}
+
+	if cfg.ImageCacheDir != "" {
+		if err := ConfigureImageCache(cfg.ImageCacheDir, int64(cfg.ImageCacheMB)<<20); err != nil {
+			log.Fatalf("image cache: %v", err)
+		}
+	}
+
+	router := mux.NewRouter()
+	router.Use(RequestLogMiddleware)
+	router.Use(CORSMiddleware(cfg.CORS))
+	router.Use(ReadinessMiddleware(data))
+	router.Use(APIKeyMiddleware)
+	router.Use(IPRateLimitMiddleware)
+	router.Use(CSRFMiddleware)
+
+	// Tenant catalogs match by host or path prefix before the default API.
+	if cfg.Tenants != "" {
+		tenants, err := LoadTenants(cfg.Tenants)
+		if err != nil {
+			log.Fatalf("load tenants: %v", err)
+		}
+		if err := MountTenants(router, tenants, templates); err != nil {
+			log.Fatalf("mount tenants: %v", err)
+		}
+		slog.Info("mounted tenant catalogs", "count", len(tenants))
+	}
+
+	// API routes
+	router.Handle("/readyz", ReadyHandler(data))
+	RegisterAPIRoutes(router, data, nil)
+
+	// The frontend: embedded at build time, or from -static-dir.
+	if cfg.StaticDir == "" && !HasEmbeddedFrontend() {
+		slog.Warn("no frontend embedded in this build; run npm run build in frontend/ or pass -static-dir")
+	}
+	router.PathPrefix("/").Handler(StaticHandler(cfg.StaticDir))
+
+	// Restored sessions need their datasets indexed, so with
+	// -sessions-file we only start listening once they are.
+	if cfg.SessionsFile != "" {
+		<-published
+		restored, dropped, err := RestoreSessions(cfg.SessionsFile)
+		if err != nil {
+			log.Fatalf("restore sessions: %v", err)
+		}
+		if restored > 0 || dropped > 0 {
+			slog.Info("restored sessions", "count", restored, "dropped", dropped, "path", cfg.SessionsFile)
+		}
+	}
+
+	srv := &http.Server{Addr: cfg.Listen, Handler: router}
+	go func() {
+		slog.Info("backend running", "addr", cfg.Listen)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
 
-    // API routes
-    RegisterAPIRoutes(router)
+	// SIGTERM (or Ctrl-C): stop accepting connections, let in-flight
+	// requests finish, then save sessions for the next start.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+	<-stop
+	slog.Info("shutting down")
 
-    // Serve frontend during dev:
-    router.PathPrefix("/").Handler(http.FileServer(http.Dir("../dist")))
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Warn("shutdown: requests still running", "err", err)
+	}
 
-    log.Println("Dev backend running at http://localhost:9000")
-    http.ListenAndServe(":9000", router)
+	if cfg.SessionsFile != "" {
+		n, err := SaveSessions(cfg.SessionsFile)
+		if err != nil {
+			log.Fatalf("save sessions: %v", err)
+		}
+		slog.Info("saved sessions", "count", n, "path", cfg.SessionsFile)
+	}
 }
@@ -0,0 +1,117 @@
+package guesser
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// scaleGames repeats games factor times under fresh IDs, to see how
+// filtering behaves on catalogs larger than the one on disk.
+func scaleGames(games []Game, factor int) []Game {
+	maxID := 0
+	for _, g := range games {
+		maxID = max(maxID, g.ID)
+	}
+
+	out := make([]Game, 0, len(games)*factor)
+	for copyN := 0; copyN < factor; copyN++ {
+		for _, g := range games {
+			g.ID += copyN * (maxID + 1)
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// RunMemoryBench implements the "bench-memory" command: how much heap a
+// loaded and indexed catalog takes at growing sizes, with and without
+// string interning. Catalogs go through JSON like a real dataset, so
+// every game starts with its own strings.
+//
+//	bench-memory [-scale 1,10,100]
+func RunMemoryBench(args []string, out io.Writer, games []Game) int {
+	fs := flag.NewFlagSet("bench-memory", flag.ContinueOnError)
+	fs.SetOutput(out)
+	scaleList := fs.String("scale", "1,10,100", "comma-separated dataset multipliers")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var scales []int
+	for _, s := range strings.Split(*scaleList, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n <= 0 {
+			fmt.Fprintf(out, "invalid -scale %q\n", s)
+			return 2
+		}
+		scales = append(scales, n)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "GAMES\tPLAIN\tINTERNED\tSAVED")
+	for _, scale := range scales {
+		raw, err := json.Marshal(scaleGames(games, scale))
+		if err != nil {
+			fmt.Fprintf(out, "encode: %v\n", err)
+			return 1
+		}
+
+		plain, n, err := indexHeap(raw, false)
+		if err != nil {
+			fmt.Fprintf(out, "decode: %v\n", err)
+			return 1
+		}
+		interned, _, _ := indexHeap(raw, true)
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%.0f%%\n", n, megabytes(plain), megabytes(interned),
+			100*(1-float64(interned)/float64(max(plain, 1))))
+	}
+	tw.Flush()
+
+	return 0
+}
+
+// indexHeap decodes raw and indexes it, and reports how much the heap grew
+// while the index is alive.
+func indexHeap(raw []byte, intern bool) (uint64, int, error) {
+	before := heapInUse()
+
+	var games []Game
+	if err := json.Unmarshal(raw, &games); err != nil {
+		return 0, 0, err
+	}
+	if intern {
+		internStrings(games)
+	}
+	benchIndex = NewGameIndex(games)
+	games = nil
+
+	grown := heapInUse() - before
+	// raw was counted in before; it must not be freed before it's
+	// counted again.
+	runtime.KeepAlive(raw)
+	n := len(benchIndex.AllGameIDs)
+	benchIndex = GameIndex{}
+	return grown, n, nil
+}
+
+// benchIndex keeps the index being measured reachable through the GCs.
+var benchIndex GameIndex
+
+func heapInUse() uint64 {
+	// Twice: sync.Pool caches (json's buffers) only go on the second GC.
+	runtime.GC()
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+func megabytes(n uint64) string {
+	return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+}
@@ -0,0 +1,550 @@
+package guesser
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The OpenAPI document is generated from the same request/response types
+// the handlers encode, so it can't drift from the wire format: the table
+// below names each operation's types, and reflection over their json tags
+// does the rest. Add new endpoints to apiOperations.
+
+// apiOperation describes one method on one path.
+type apiOperation struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	// Auth is "", "session" (X-Session-Token), "stateless"
+	// (X-Session-State) or "admin" (bearer token).
+	Auth  string
+	Query []apiParam
+	// Negotiated operations answer through writeResponse: they also speak
+	// msgpack and protobuf and accept ?fields=.
+	Negotiated bool
+
+	// Request is the JSON body's zero value, nil for none.
+	Request any
+	// Response is the 200 body: a zero value, apiOneOf, apiText,
+	// apiBinary or apiWebSocket; nil means 204.
+	Response any
+	// Errors lists error bodies that carry more than the ErrorResponse
+	// envelope, by status; every other error is a plain ErrorResponse.
+	Errors map[int]any
+}
+
+type apiParam struct {
+	Name        string
+	Type        string // "string" or "integer"
+	Description string
+}
+
+// apiOneOf is a response whose shape depends on the session, e.g. classic
+// and hot/cold guesses.
+type apiOneOf []any
+
+// apiText is a plain-text response.
+type apiText struct{}
+
+// apiBinary is a non-JSON response in one of the given media types.
+type apiBinary []string
+
+// apiWebSocket marks an operation that upgrades to a WebSocket; Response
+// then describes the server's messages.
+type apiWebSocket struct{ Messages apiOneOf }
+
+var limitParam = apiParam{"limit", "integer", "maximum number of results"}
+
+// signedImageParams authorize a link from image-url instead of the
+// session token.
+var signedImageParams = []apiParam{
+	{"expires", "integer", "from image-url"},
+	{"sig", "string", "from image-url"},
+}
+
+var apiOperations = []apiOperation{
+	{Method: "GET", Path: "/readyz", Tag: "meta", Summary: "Readiness probe; 503 until the dataset is indexed",
+		Response: apiText{}},
+	{Method: "GET", Path: "/api/v1/branding", Tag: "meta", Summary: "The tenant's display strings", Response: map[string]string{}},
+	{Method: "GET", Path: "/api/v1/datasets", Tag: "meta", Summary: "Datasets sessions can pick", Response: DatasetsResponse{}},
+	{Method: "GET", Path: "/api/v1/dataset", Tag: "meta", Summary: "Size, version and content hash of a dataset; ETag/If-None-Match aware",
+		Query: []apiParam{{"dataset", "string", "dataset ID (default: the default dataset)"}}, Response: DatasetMeta{}},
+
+	{Method: "POST", Path: "/api/v1/session/start", Tag: "session", Summary: "Start a session",
+		Negotiated: true, Request: StartSessionRequest{}, Response: StartSessionResponse{}},
+	{Method: "POST", Path: "/api/v1/daily/start", Tag: "session", Summary: "Start or resume today's daily challenge",
+		Negotiated: true, Response: DailyStartResponse{},
+		Errors: map[int]any{http.StatusConflict: DailyCompletedResponse{}}},
+	{Method: "POST", Path: "/api/v1/steam/start", Tag: "session", Summary: "Start a session on a Steam library",
+		Negotiated: true, Request: SteamStartRequest{}, Response: SteamStartResponse{}},
+	{Method: "POST", Path: "/api/v1/stateless/start", Tag: "stateless", Summary: "Start a session kept in an encrypted token (X-Session-State)",
+		Negotiated: true, Request: StartSessionRequest{}, Response: StartSessionResponse{}},
+	{Method: "GET", Path: "/api/v1/stateless/session", Tag: "stateless",
+		Summary: "Stateless session state; /api/v1/stateless/session/{action} mirrors every /api/v1/session/{sessionId}/{action}",
+		Auth:    "stateless", Negotiated: true, Response: SessionStateResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}", Tag: "session", Summary: "Full session state, for restoring the UI",
+		Auth: "session", Negotiated: true, Response: SessionStateResponse{}},
+	{Method: "POST", Path: "/api/v1/session/{sessionId}/ask", Tag: "session", Summary: "Ask a question (classic mode)",
+		Auth: "session", Negotiated: true, Request: AskRequest{}, Response: AskResponse{},
+		Errors: map[int]any{http.StatusConflict: AlreadyAskedResponse{}, http.StatusUnprocessableEntity: InvalidOptionResponse{}}},
+	{Method: "POST", Path: "/api/v1/session/{sessionId}/guess", Tag: "session", Summary: "Guess the secret",
+		Auth: "session", Negotiated: true, Request: GuessRequest{},
+		Response: apiOneOf{GuessResponse{}, ProximityGuessResponse{}}},
+	{Method: "POST", Path: "/api/v1/session/{sessionId}/giveup", Tag: "session", Summary: "Give up and reveal the secret",
+		Auth: "session", Negotiated: true, Response: GiveUpResponse{}},
+	{Method: "POST", Path: "/api/v1/session/{sessionId}/challenge", Tag: "session", Summary: "Share a finished game as a seed code, valid for a week",
+		Auth: "session", Response: ChallengeResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/next-question", Tag: "session", Summary: "The solver's pending question (reverse mode)",
+		Auth: "session", Negotiated: true, Response: NextQuestionResponse{}},
+	{Method: "POST", Path: "/api/v1/session/{sessionId}/answer", Tag: "session", Summary: "Answer the solver (reverse mode)",
+		Auth: "session", Negotiated: true, Request: AnswerRequest{}, Response: AnswerResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/candidates", Tag: "session", Summary: "Remaining candidates, once few enough are left",
+		Auth: "session", Negotiated: true, Response: CandidatesResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/questions", Tag: "session", Summary: "Questions that still split the candidates",
+		Auth: "session", Negotiated: true, Response: RemainingQuestionsResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/split", Tag: "session", Summary: "How each option of one question would split the candidates",
+		Auth: "session", Negotiated: true, Query: []apiParam{{"questionTypeId", "string", "the question type to preview"}},
+		Response: SplitPreviewResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/suggest-question", Tag: "session", Summary: "The most informative questions to ask next",
+		Auth: "session", Negotiated: true, Query: []apiParam{limitParam}, Response: SuggestQuestionResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/timeline", Tag: "session", Summary: "Candidate count after each question",
+		Auth: "session", Negotiated: true, Response: TimelineResponse{}},
+	{Method: "POST", Path: "/api/v1/session/{sessionId}/hint", Tag: "session", Summary: "Take the next hint: decade, first letter, developer, then a blurred cover",
+		Auth: "session", Negotiated: true, Response: HintResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/cover", Tag: "session",
+		Summary: "The secret's cover, pixelated less with every question, guess and hint, and whole once the game is over",
+		Auth:    "session", Query: signedImageParams, Response: apiBinary{"image/jpeg"}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/share", Tag: "session",
+		Summary: "A finished game as an emoji grid to paste, without the answer",
+		Auth:    "session", Negotiated: true,
+		Query: []apiParam{
+			{"format", "string", "json (default), or png for the grid as a card image"},
+			signedImageParams[0], signedImageParams[1],
+		},
+		Response: ShareResponse{}},
+	{Method: "GET", Path: "/api/v1/session/{sessionId}/image-url", Tag: "session",
+		Summary: "A link to the cover or the share card that works without the session token for five minutes, for <img> tags",
+		Auth:    "session", Query: []apiParam{{"image", "string", "cover, or share for the PNG card"}},
+		Response: ImageURLResponse{}},
+	{Method: "GET", Path: "/ws/session/{sessionId}", Tag: "session", Summary: "WebSocket carrying session actions, timer ticks and room progress",
+		Auth: "session", Query: []apiParam{{"token", "string", "session token, for clients that can't set headers"}},
+		Request:  SessionSocketRequest{},
+		Response: apiWebSocket{apiOneOf{SessionSocketMessage{}, RoomUpdate{}}}},
+
+	{Method: "GET", Path: "/api/v1/stats", Tag: "games", Summary: "Totals over every finished game: win rate, most guessed and hardest secrets",
+		Negotiated: true, Query: []apiParam{{"dataset", "string", "dataset ID (default: the default dataset)"}, limitParam},
+		Response: StatsResponse{}},
+	{Method: "GET", Path: "/api/v1/recap/{token}/card", Tag: "session", Summary: "Shareable recap card image",
+		Query:    []apiParam{{"format", "string", "png (default) or svg"}},
+		Response: apiBinary{"image/png", "image/svg+xml"}},
+
+	{Method: "GET", Path: "/api/v1/games", Tag: "games", Summary: "Browse the catalog", Negotiated: true,
+		Query: []apiParam{
+			{"genre", "string", "only games with this genre"},
+			{"platform", "string", "only games on this platform"},
+			{"yearFrom", "integer", "earliest release year"},
+			{"yearTo", "integer", "latest release year"},
+			{"q", "string", "title substring"},
+			limitParam,
+			{"cursor", "string", "nextCursor from the previous page"},
+		},
+		Response: CatalogResponse{}},
+	{Method: "GET", Path: "/api/v1/games/suggest", Tag: "games", Summary: "Title autocomplete, tolerant of typos", Negotiated: true,
+		Query:    []apiParam{{"q", "string", "what the player has typed"}, limitParam},
+		Response: SuggestResponse{}},
+	{Method: "GET", Path: "/api/v1/games/{gameId}", Tag: "games", Summary: "Full game details",
+		Negotiated: true, Response: Game{}},
+	{Method: "GET", Path: "/api/v1/games/{gameId}/similar", Tag: "games", Summary: "The most similar games",
+		Negotiated: true, Query: []apiParam{limitParam}, Response: SimilarGamesResponse{}},
+	{Method: "GET", Path: "/api/v1/images/{gameId}", Tag: "games", Summary: "Cover art, through the server's image cache",
+		Query: []apiParam{
+			{"dataset", "string", "dataset ID (default: the default dataset)"},
+			{"w", "integer", "scale down to at most this many pixels wide (max 1024)"},
+			{"format", "string", "jpeg (default with w) or png; without w or format, the original"},
+		},
+		Response: apiBinary{"image/jpeg", "image/png", "image/webp"}},
+
+	{Method: "POST", Path: "/api/v1/room/create", Tag: "rooms", Summary: "Create a party or race room",
+		Request: CreateRoomRequest{}, Response: CreateRoomResponse{}},
+	{Method: "GET", Path: "/api/v1/room/{roomId}", Tag: "rooms", Summary: "Every player's progress",
+		Response: RoomStatusResponse{}},
+	{Method: "POST", Path: "/api/v1/room/{roomId}/join", Tag: "rooms", Summary: "Join a room and get a session",
+		Negotiated: true, Request: JoinRoomRequest{}, Response: StartSessionResponse{}},
+	{Method: "GET", Path: "/api/v1/room/{roomId}/events", Tag: "rooms", Summary: "WebSocket of progress updates",
+		Response: apiWebSocket{apiOneOf{RoomUpdate{}}}},
+	{Method: "GET", Path: "/api/v1/ladder", Tag: "rooms", Summary: "Players ranked by the Elo rating of their two-player races; only API-key clients' players are rated",
+		Negotiated: true, Query: []apiParam{limitParam}, Response: LadderResponse{}},
+
+	{Method: "GET", Path: "/api/v1/admin/webhooks/deliveries", Tag: "admin", Summary: "Recent webhook deliveries",
+		Auth: "admin", Response: []WebhookDelivery{}},
+	{Method: "GET", Path: "/api/v1/admin/api-keys", Tag: "admin", Summary: "Usage per API key",
+		Auth: "admin", Response: []APIKeyUsage{}},
+	{Method: "GET", Path: "/api/v1/admin/templates", Tag: "admin", Summary: "How well each question option splits a dataset",
+		Auth: "admin", Query: []apiParam{{"dataset", "string", "dataset ID (default: the default dataset)"}}, Response: TemplateReport{}},
+	{Method: "POST", Path: "/api/v1/admin/datasets/reload", Tag: "admin", Summary: "Reload every dataset from disk",
+		Auth: "admin", Response: []DatasetReload{},
+		Errors: map[int]any{http.StatusInternalServerError: []DatasetReload{}}},
+}
+
+// apiEnums lists the values of string types the handlers validate.
+var apiEnums = map[reflect.Type][]string{
+	reflect.TypeOf(SessionMode("")):       {string(ModeClassic), string(ModeHotCold), string(ModeReverse)},
+	reflect.TypeOf(SessionStatus("")):     {string(StatusActive), string(StatusFinalGuess), string(StatusFinished)},
+	reflect.TypeOf(Outcome("")):           {string(OutcomeWon), string(OutcomeLost), string(OutcomeGaveUp)},
+	reflect.TypeOf(RoomMode("")):          {string(RoomModeParty), string(RoomModeRace)},
+	reflect.TypeOf(SessionDifficulty("")): {string(DifficultyEasy), string(DifficultyNormal), string(DifficultyHard)},
+	reflect.TypeOf(HintKind("")):          enumStrings(hintLadder),
+	reflect.TypeOf(ErrorCode("")):         enumStrings(errorCodes),
+}
+
+func enumStrings[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
+
+// apiFieldTypes overrides fields whose Go type doesn't say what they hold,
+// keyed by "Struct.Field".
+var apiFieldTypes = map[string]reflect.Type{
+	"StartSessionResponse.QuestionTypes": reflect.TypeOf([]QuestionTypeDef{}),
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaBuilder turns Go types into JSON Schema, collecting every named
+// struct under components/schemas.
+type schemaBuilder struct {
+	components map[string]any
+	// inputs are structs reachable from request bodies. Their fields are
+	// all optional; output fields without omitempty are always present.
+	inputs map[reflect.Type]bool
+}
+
+func (b *schemaBuilder) markInputs(t reflect.Type) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || b.inputs[t] {
+		return
+	}
+	b.inputs[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		b.markInputs(t.Field(i).Type)
+	}
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return map[string]any{}
+	}
+	if values, ok := apiEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]any{"type": "string", "format": "date-time"}
+		}
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		if _, ok := b.components[t.Name()]; !ok {
+			b.components[t.Name()] = nil // placeholder, for recursive types
+			b.components[t.Name()] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]any{}
+}
+
+// object describes a struct the way encoding/json writes it: embedded
+// structs are flattened and json:"-" fields are skipped.
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	b.addFields(t, t, properties, &required)
+
+	obj := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		obj["required"] = required
+	}
+	return obj
+}
+
+func (b *schemaBuilder) addFields(owner, t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			b.addFields(owner, f.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		ft := f.Type
+		if override, ok := apiFieldTypes[t.Name()+"."+f.Name]; ok {
+			ft = override
+		}
+		properties[name] = b.schema(ft)
+		if !b.inputs[owner] && !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// content is a content map for body, in every encoding the operation
+// speaks.
+func (b *schemaBuilder) content(body any, negotiated bool) map[string]any {
+	var schema map[string]any
+	if alts, ok := body.(apiOneOf); ok {
+		var oneOf []any
+		for _, alt := range alts {
+			oneOf = append(oneOf, b.schema(reflect.TypeOf(alt)))
+		}
+		schema = map[string]any{"oneOf": oneOf}
+	} else {
+		schema = b.schema(reflect.TypeOf(body))
+	}
+
+	content := map[string]any{contentJSON: map[string]any{"schema": schema}}
+	if negotiated {
+		content[contentMsgPack] = map[string]any{"schema": schema}
+		content[contentProtobuf] = map[string]any{"schema": schema}
+	}
+	return content
+}
+
+func (b *schemaBuilder) operation(op apiOperation) map[string]any {
+	var params []any
+	for _, name := range pathParams(op.Path) {
+		params = append(params, map[string]any{
+			"name": name, "in": "path", "required": true,
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, p := range op.Query {
+		params = append(params, map[string]any{
+			"name": p.Name, "in": "query", "description": p.Description,
+			"schema": map[string]any{"type": p.Type},
+		})
+	}
+	if op.Negotiated {
+		params = append(params, map[string]any{
+			"name": "fields", "in": "query",
+			"description": "comma-separated fields to keep, e.g. candidatesCount,debug.secret",
+			"schema":      map[string]any{"type": "string"},
+		})
+	}
+
+	responses := map[string]any{"default": map[string]any{
+		"description": "error",
+		"content":     b.content(ErrorResponse{}, false),
+	}}
+	switch resp := op.Response.(type) {
+	case nil:
+		responses["204"] = map[string]any{"description": "no content"}
+	case apiText:
+		responses["200"] = map[string]any{
+			"description": "OK",
+			"content":     map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}},
+		}
+	case apiBinary:
+		content := map[string]any{}
+		for _, mediaType := range resp {
+			content[mediaType] = map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}
+		}
+		responses["200"] = map[string]any{"description": "OK", "content": content}
+	case apiWebSocket:
+		responses["101"] = map[string]any{
+			"description": "switching to WebSocket; the server sends these messages",
+			"content":     b.content(resp.Messages, false),
+		}
+	default:
+		responses["200"] = map[string]any{"description": "OK", "content": b.content(resp, op.Negotiated)}
+	}
+	for status, body := range op.Errors {
+		responses[strconv.Itoa(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content":     b.content(body, false),
+		}
+	}
+
+	out := map[string]any{
+		"tags":        []string{op.Tag},
+		"summary":     op.Summary,
+		"operationId": operationID(op),
+		"responses":   responses,
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+	if op.Request != nil {
+		body := map[string]any{"content": b.content(op.Request, false)}
+		if _, ws := op.Response.(apiWebSocket); ws {
+			body["description"] = "messages the client sends over the socket"
+		}
+		out["requestBody"] = body
+	}
+	switch op.Auth {
+	case "session":
+		out["security"] = []any{map[string]any{"sessionToken": []string{}}}
+	case "stateless":
+		out["security"] = []any{map[string]any{"sessionState": []string{}}}
+	case "admin":
+		out["security"] = []any{map[string]any{"adminToken": []string{}}}
+	}
+	return out
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			names = append(names, seg[1:len(seg)-1])
+		}
+	}
+	return names
+}
+
+// operationID derives a stable ID such as "postSessionAsk" from the route.
+func operationID(op apiOperation) string {
+	id := strings.ToLower(op.Method)
+	for _, seg := range strings.Split(op.Path, "/") {
+		if seg == "" || seg == "api" || strings.HasPrefix(seg, "{") {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '.' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+// BuildOpenAPI returns the OpenAPI 3 document for apiOperations.
+func BuildOpenAPI() map[string]any {
+	b := &schemaBuilder{components: map[string]any{}, inputs: map[reflect.Type]bool{}}
+	for _, op := range apiOperations {
+		if op.Request != nil {
+			b.markInputs(reflect.TypeOf(op.Request))
+		}
+	}
+
+	paths := map[string]any{}
+	for _, op := range apiOperations {
+		item, _ := paths[op.Path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = b.operation(op)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Game Guesser API",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": b.components,
+			"securitySchemes": map[string]any{
+				"sessionToken": map[string]any{"type": "apiKey", "in": "header", "name": sessionTokenHeader},
+				"sessionState": map[string]any{"type": "apiKey", "in": "header", "name": statelessHeader},
+				"adminToken":   map[string]any{"type": "http", "scheme": "bearer"},
+				"apiKey":       map[string]any{"type": "apiKey", "in": "header", "name": apiKeyHeader},
+			},
+		},
+	}
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+)
+
+// ---------------------------------
+// /api/v1/openapi.json   (GET)
+// ---------------------------------
+
+func OpenAPIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		openAPIOnce.Do(func() {
+			openAPIJSON, _ = json.MarshalIndent(BuildOpenAPI(), "", "  ")
+		})
+		w.Header().Set("Content-Type", contentJSON)
+		w.Write(openAPIJSON)
+	})
+}
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at the spec, so
+// the docs need no assets of their own.
+const swaggerUIPage = `<!doctype html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Game Guesser API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// ---------------------------------
+// /api/v1/docs   (GET, Swagger UI)
+// ---------------------------------
+
+func SwaggerUIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(swaggerUIPage))
+	})
+}
@@ -0,0 +1,340 @@
+package guesser
+
+import (
+	"fmt"
+	"log/slog"
+	"math/bits"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ---------------------------------
+// Bitsets over game positions
+// ---------------------------------
+
+// bitset has one bit per game, by the game's position in AllGameIDs.
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// and returns b ∩ o, or b \ o when not is set.
+func (b bitset) and(o bitset, not bool) bitset {
+	out := make(bitset, len(b))
+	for i := range b {
+		if not {
+			out[i] = b[i] &^ o[i]
+		} else {
+			out[i] = b[i] & o[i]
+		}
+	}
+	return out
+}
+
+func (b bitset) count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// ---------------------------------
+// Answer matrix
+// ---------------------------------
+
+// AnswerMatrix caches every template option's answer for every game, so
+// filtering and scoring are bit tests instead of calls into the check
+// functions.
+type AnswerMatrix struct {
+	ids       []int // game ID by position, i.e. AllGameIDs
+	positions map[int]int
+	rows      map[string]map[string]bitset // template ID -> value -> yes-set
+}
+
+// Answer looks up the cached answer. ok is false for options the matrix
+// was not built with (e.g. a value outside the template's Values), which
+// callers then evaluate directly.
+func (m *AnswerMatrix) Answer(t QuestionTemplate, value string, gameID int) (answer, ok bool) {
+	row, ok := m.row(t, value)
+	if !ok {
+		return false, false
+	}
+	pos, ok := m.positions[gameID]
+	if !ok {
+		return false, false
+	}
+	return row.has(pos), true
+}
+
+func (m *AnswerMatrix) row(t QuestionTemplate, value string) (bitset, bool) {
+	if t.CheckString == nil {
+		value = ""
+	}
+	row, ok := m.rows[t.ID][value]
+	return row, ok
+}
+
+// Filter keeps the ids whose answer is answer with a single AND (or
+// AND NOT) against the option's row. The result is in index order. ok is
+// false when the matrix lacks the option or one of the ids.
+func (m *AnswerMatrix) Filter(t QuestionTemplate, value string, answer bool, ids []int) ([]int, bool) {
+	row, ok := m.row(t, value)
+	if !ok {
+		return nil, false
+	}
+
+	set := newBitset(len(m.ids))
+	for _, id := range ids {
+		pos, ok := m.positions[id]
+		if !ok {
+			return nil, false
+		}
+		set.set(pos)
+	}
+	set = set.and(row, !answer)
+
+	out := make([]int, 0, set.count())
+	for w, word := range set {
+		for word != 0 {
+			out = append(out, m.ids[w*64+bits.TrailingZeros64(word)])
+			word &= word - 1
+		}
+	}
+	return out, true
+}
+
+// matches answers the template for one game, from the answer matrix when
+// it covers the option.
+func (idx GameIndex) matches(t QuestionTemplate, value string, gameID int) bool {
+	if idx.Answers != nil {
+		if answer, ok := idx.Answers.Answer(t, value, gameID); ok {
+			return answer
+		}
+	}
+	g, ok := idx.Games[gameID]
+	if !ok {
+		return false
+	}
+	return t.Matches(g, value)
+}
+
+// ---------------------------------
+// Attribute bitsets
+// ---------------------------------
+
+// AttributeIndex maps every (Game JSON field, value) pair to the games
+// that have it. Slice fields contribute one entry per element; zero
+// values are left out, so a field with no entries is empty everywhere.
+type AttributeIndex struct {
+	fields map[string]map[string]bitset
+}
+
+// Values returns how many games carry each value of field.
+func (a *AttributeIndex) Values(field string) map[string]int {
+	counts := make(map[string]int, len(a.fields[field]))
+	for v, set := range a.fields[field] {
+		counts[v] = set.count()
+	}
+	return counts
+}
+
+func attributeStrings(v reflect.Value) []string {
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() != "" {
+			return []string{v.String()}
+		}
+	case reflect.Bool:
+		if v.Bool() {
+			return []string{"true"}
+		}
+	case reflect.Int:
+		if v.Int() != 0 {
+			return []string{strconv.FormatInt(v.Int(), 10)}
+		}
+	case reflect.Slice:
+		var out []string
+		for i := 0; i < v.Len(); i++ {
+			out = append(out, attributeStrings(v.Index(i))...)
+		}
+		return out
+	}
+	return nil
+}
+
+// ---------------------------------
+// Startup precompute
+// ---------------------------------
+
+// precomputeProgress counts finished work units and logs the percentage
+// periodically until stopped.
+type precomputeProgress struct {
+	name  string
+	total int64
+	done  atomic.Int64
+	stop  chan struct{}
+}
+
+func startProgress(name string, total int) *precomputeProgress {
+	p := &precomputeProgress{name: name, total: int64(total), stop: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				done := p.done.Load()
+				slog.Debug("precompute progress", "dataset", p.name, "percent", 100*done/p.total, "done", done, "total", p.total)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// PrecomputeIndex builds the game index and, concurrently, its attribute
+// bitsets and answer matrix. name only labels the progress log.
+func PrecomputeIndex(name string, games []Game, templates []QuestionTemplate) GameIndex {
+	started := time.Now()
+	idx := NewGameIndex(games)
+
+	type option struct {
+		template QuestionTemplate
+		value    string
+	}
+	var options []option
+	for _, t := range templates {
+		switch {
+		case t.CheckString != nil:
+			for _, v := range t.Values {
+				options = append(options, option{t, v})
+			}
+		case t.CheckBool != nil:
+			options = append(options, option{t, ""})
+		}
+	}
+
+	progress := startProgress(name, len(games)+len(options))
+	defer close(progress.stop)
+
+	positions := make(map[int]int, len(idx.AllGameIDs))
+	for pos, id := range idx.AllGameIDs {
+		positions[id] = pos
+	}
+
+	var wg sync.WaitGroup
+
+	// Attribute bitsets: one pass over the games.
+	attrs := &AttributeIndex{fields: make(map[string]map[string]bitset)}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fields := gameFields
+		for field := range fields {
+			attrs.fields[field] = make(map[string]bitset)
+		}
+		for pos, id := range idx.AllGameIDs {
+			g := reflect.ValueOf(idx.Games[id]).Elem()
+			for field, i := range fields {
+				for _, v := range attributeStrings(g.FieldByIndex(i)) {
+					set, ok := attrs.fields[field][v]
+					if !ok {
+						set = newBitset(len(idx.AllGameIDs))
+						attrs.fields[field][v] = set
+					}
+					set.set(pos)
+				}
+			}
+			progress.done.Add(1)
+		}
+	}()
+
+	// Answer matrix: rows are independent, so spread them over the CPUs.
+	rows := make([]bitset, len(options))
+	next := atomic.Int64{}
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(options) {
+					return
+				}
+				row := newBitset(len(idx.AllGameIDs))
+				for pos, id := range idx.AllGameIDs {
+					if options[i].template.Matches(idx.Games[id], options[i].value) {
+						row.set(pos)
+					}
+				}
+				rows[i] = row
+				progress.done.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	matrix := &AnswerMatrix{ids: idx.AllGameIDs, positions: positions, rows: make(map[string]map[string]bitset)}
+	for i, o := range options {
+		if matrix.rows[o.template.ID] == nil {
+			matrix.rows[o.template.ID] = make(map[string]bitset)
+		}
+		matrix.rows[o.template.ID][o.value] = rows[i]
+	}
+
+	idx.Answers = matrix
+	idx.Attributes = attrs
+	slog.Info("precomputed dataset", "dataset", name, "games", len(games), "answerRows", len(options),
+		"took", time.Since(started).Round(time.Millisecond).String())
+	return idx
+}
+
+// ---------------------------------
+// Readiness gate
+// ---------------------------------
+
+// ReadinessMiddleware answers 503 for API requests until data has its
+// first snapshot, so traffic is not served before precompute finishes.
+// The frontend and /readyz stay reachable.
+func ReadinessMiddleware(data *SnapshotHolder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if data.Current() == nil && strings.Contains(r.URL.Path, "/api/") {
+				w.Header().Set("Retry-After", "5")
+				writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "starting up")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ReadyHandler serves /readyz for load balancer health checks.
+func ReadyHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snap := data.Current()
+		if snap == nil {
+			writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "starting up")
+			return
+		}
+		fmt.Fprintf(w, "ready: version %d, %d games\n", snap.Version, len(snap.Index.Games))
+	})
+}
@@ -0,0 +1,126 @@
+package guesser
+
+import (
+	"math"
+	"net/http"
+	"strings"
+)
+
+// SessionMode selects the rules a session is played with.
+type SessionMode string
+
+const (
+	// ModeClassic narrows candidates with yes/no questions.
+	ModeClassic SessionMode = "classic"
+	// ModeHotCold has no questions: each guess reports how close it is
+	// to the secret until the exact game is found.
+	ModeHotCold SessionMode = "hotcold"
+	// ModeReverse swaps roles: the player thinks of a game and the server
+	// asks the questions (see reverse.go).
+	ModeReverse SessionMode = "reverse"
+)
+
+// Temperature buckets a similarity score for display.
+func Temperature(similarity float64) string {
+	switch {
+	case similarity >= 1:
+		return "found"
+	case similarity >= 0.75:
+		return "burning"
+	case similarity >= 0.55:
+		return "hot"
+	case similarity >= 0.35:
+		return "warm"
+	case similarity >= 0.15:
+		return "cold"
+	default:
+		return "freezing"
+	}
+}
+
+// findGameByName looks a guess up in the index, exact name first and then
+// by normalized title or alias.
+func findGameByName(idx GameIndex, name string) (*Game, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, false
+	}
+
+	normalized := normalizeTitle(name)
+	var fallback *Game
+	found := false
+
+	for _, id := range idx.AllGameIDs {
+		g := idx.Games[id]
+		if strings.EqualFold(g.Name, name) {
+			return g, true
+		}
+		if !found && hasTitle(idx, g, normalized) {
+			fallback = g
+			found = true
+		}
+	}
+
+	return fallback, found
+}
+
+type ProximityGuessResponse struct {
+	Correct     bool        `json:"correct"`
+	Similarity  float64     `json:"similarity"`
+	Temperature string      `json:"temperature"`
+	GuessNumber int         `json:"guessNumber"`
+	Guessed     GameSummary `json:"guessed"`
+	// Game, Recap and Summary are only filled in once the secret has been
+	// found.
+	Game    *GameSummary    `json:"game,omitempty"`
+	Recap   *Recap          `json:"recap,omitempty"`
+	Summary *SessionSummary `json:"summary,omitempty"`
+}
+
+// handleProximityGuess scores a hot/cold guess against the secret.
+func handleProximityGuess(
+	w http.ResponseWriter,
+	r *http.Request,
+	req GuessRequest,
+	session *Session,
+	idx GameIndex,
+	templates []QuestionTemplate,
+	secret *Game,
+) {
+	guessed, ok := idx.Games[req.GameID]
+	if req.GameID == 0 {
+		guessed, ok = findGameByName(idx, req.Guess)
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeUnknownGame, "unknown game: hot/cold guesses must name a game in the dataset")
+		return
+	}
+
+	similarity := math.Round(Similarity(guessed, secret)*1000) / 1000
+	correct := guessed.ID == secret.ID
+
+	session.State.Guesses = append(session.State.Guesses, GuessRecord{
+		Guess:      req.Guess,
+		GameID:     guessed.ID,
+		Correct:    correct,
+		Similarity: similarity,
+	})
+
+	resp := ProximityGuessResponse{
+		Correct:     correct,
+		Similarity:  similarity,
+		Temperature: Temperature(similarity),
+		GuessNumber: len(session.State.Guesses),
+		Guessed:     summarize(guessed),
+	}
+
+	if correct {
+		summary := summarize(secret)
+		recap := completeSession(session, OutcomeWon, idx, templates)
+		resp.Game = &summary
+		resp.Recap = &recap
+		resp.Summary = sessionSummary(session)
+	}
+
+	writeResponse(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,156 @@
+package guesser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+)
+
+// PubSub fans messages out to every instance subscribed to a topic,
+// including the publisher. Delivery is at-most-once: subscribers that are
+// down miss what was published meanwhile.
+type PubSub interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe calls handler for each message published after it returns,
+	// until ctx is cancelled.
+	Subscribe(ctx context.Context, topic string, handler func(payload []byte)) error
+	Close() error
+}
+
+// NewPubSub connects to the broker named by rawURL: redis:// or rediss://
+// for Redis streams, nats:// or tls:// for NATS.
+func NewPubSub(rawURL string) (PubSub, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "redis", "rediss":
+		opts, err := redis.ParseURL(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		client := redis.NewClient(opts)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return &redisStreams{client: client}, nil
+	case "nats", "tls":
+		conn, err := nats.Connect(rawURL, nats.MaxReconnects(-1))
+		if err != nil {
+			return nil, fmt.Errorf("nats: %w", err)
+		}
+		return &natsPubSub{conn: conn}, nil
+	default:
+		return nil, fmt.Errorf("unsupported pub/sub scheme %q", u.Scheme)
+	}
+}
+
+// ---------------------------------
+// Redis streams
+// ---------------------------------
+
+// redisStreamMaxLen caps each stream; subscribers only ever read new
+// entries, so old ones are just a short replay buffer for debugging.
+const redisStreamMaxLen = 10000
+
+type redisStreams struct {
+	client *redis.Client
+}
+
+func (p *redisStreams) Publish(ctx context.Context, topic string, payload []byte) error {
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: topic,
+		MaxLen: redisStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": payload},
+	}).Err()
+}
+
+func (p *redisStreams) Subscribe(ctx context.Context, topic string, handler func([]byte)) error {
+	// Resolve "$" to a concrete ID now, so nothing published between
+	// Subscribe returning and the first XREAD is missed.
+	lastID := "0-0"
+	if entries, err := p.client.XRevRangeN(ctx, topic, "+", "-", 1).Result(); err != nil {
+		return err
+	} else if len(entries) > 0 {
+		lastID = entries[0].ID
+	}
+
+	go func() {
+		for ctx.Err() == nil {
+			streams, err := p.client.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{topic, lastID},
+				Block:   5 * time.Second,
+			}).Result()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Error("pubsub: redis XREAD", "topic", topic, "err", err)
+					time.Sleep(time.Second)
+				}
+				continue
+			}
+
+			for _, stream := range streams {
+				for _, msg := range stream.Messages {
+					lastID = msg.ID
+					if data, ok := msg.Values["data"].(string); ok {
+						handler([]byte(data))
+					}
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *redisStreams) Close() error {
+	return p.client.Close()
+}
+
+// ---------------------------------
+// NATS
+// ---------------------------------
+
+type natsPubSub struct {
+	conn *nats.Conn
+}
+
+func (p *natsPubSub) Publish(ctx context.Context, topic string, payload []byte) error {
+	return p.conn.Publish(topic, payload)
+}
+
+func (p *natsPubSub) Subscribe(ctx context.Context, topic string, handler func([]byte)) error {
+	sub, err := p.conn.Subscribe(topic, func(m *nats.Msg) {
+		handler(m.Data)
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = sub.Unsubscribe()
+	}()
+
+	return nil
+}
+
+func (p *natsPubSub) Close() error {
+	p.conn.Close()
+	return nil
+}
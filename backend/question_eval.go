@@ -0,0 +1,217 @@
+package guesser
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// maxSimulatedQuestions stops a simulated game that keeps picking useless
+// questions from running forever.
+const maxSimulatedQuestions = 40
+
+// TemplateEfficacy aggregates how one template fared across simulated
+// games.
+type TemplateEfficacy struct {
+	TemplateID string
+	Asked      int
+	Useful     int // asks that eliminated at least one candidate
+	Wasted     int // asks that eliminated nothing
+	// InformationBits sums the information gained by the template's asks.
+	InformationBits float64
+	// DominatedBy lists templates whose best option was at least as good in
+	// every sampled position, and strictly better in at least one.
+	DominatedBy []string
+}
+
+func (e TemplateEfficacy) UsefulRate() float64 {
+	if e.Asked == 0 {
+		return 0
+	}
+	return float64(e.Useful) / float64(e.Asked)
+}
+
+func (e TemplateEfficacy) BitsPerAsk() float64 {
+	if e.Asked == 0 {
+		return 0
+	}
+	return e.InformationBits / float64(e.Asked)
+}
+
+type simOption struct {
+	template int
+	value    string
+}
+
+// evalAccumulator collects one worker's results.
+type evalAccumulator struct {
+	stats []TemplateEfficacy
+	// geq[a][b]: b's best split was never worse than a's.
+	// gt[a][b]:  b's best split was strictly better at least once.
+	geq  [][]bool
+	gt   [][]bool
+	best []float64
+}
+
+func newEvalAccumulator(n int) *evalAccumulator {
+	acc := &evalAccumulator{
+		stats: make([]TemplateEfficacy, n),
+		geq:   make([][]bool, n),
+		gt:    make([][]bool, n),
+		best:  make([]float64, n),
+	}
+	for i := 0; i < n; i++ {
+		acc.geq[i] = make([]bool, n)
+		acc.gt[i] = make([]bool, n)
+		for j := range acc.geq[i] {
+			acc.geq[i][j] = true
+		}
+	}
+	return acc
+}
+
+func (acc *evalAccumulator) merge(other *evalAccumulator) {
+	for i := range acc.stats {
+		acc.stats[i].Asked += other.stats[i].Asked
+		acc.stats[i].Useful += other.stats[i].Useful
+		acc.stats[i].Wasted += other.stats[i].Wasted
+		acc.stats[i].InformationBits += other.stats[i].InformationBits
+		for j := range acc.geq[i] {
+			acc.geq[i][j] = acc.geq[i][j] && other.geq[i][j]
+			acc.gt[i][j] = acc.gt[i][j] || other.gt[i][j]
+		}
+	}
+}
+
+// simulateGame plays one game with a player who asks uniformly random
+// unasked questions, like a newcomer would.
+func simulateGame(idx GameIndex, templates []QuestionTemplate, options []simOption, rng *rand.Rand, acc *evalAccumulator) {
+	secretID := idx.AllGameIDs[rng.Intn(len(idx.AllGameIDs))]
+	state := NewSessionStateFromPool(idx.AllGameIDs, secretID)
+	order := rng.Perm(len(options))
+
+	for step := 0; step < maxSimulatedQuestions && step < len(order) && len(state.RemainingIDs) > 1; step++ {
+		// Compare templates head to head on this position.
+		for i := range acc.best {
+			acc.best[i] = 0
+		}
+		for _, o := range options {
+			yes := countMatches(templates[o.template], o.value, state.RemainingIDs, idx)
+			if p := splitPower(yes, len(state.RemainingIDs)); p > acc.best[o.template] {
+				acc.best[o.template] = p
+			}
+		}
+		for a := range templates {
+			for b := range templates {
+				if acc.best[b] < acc.best[a] {
+					acc.geq[a][b] = false
+				} else if acc.best[b] > acc.best[a] {
+					acc.gt[a][b] = true
+				}
+			}
+		}
+
+		o := options[order[step]]
+		before := len(state.RemainingIDs)
+		yes := countMatches(templates[o.template], o.value, state.RemainingIDs, idx)
+		state, _ = ApplyQuestion(state, templates[o.template], idx, o.value)
+
+		s := &acc.stats[o.template]
+		s.Asked++
+		s.InformationBits += binaryEntropy(yes, before)
+		if len(state.RemainingIDs) < before {
+			s.Useful++
+		} else {
+			s.Wasted++
+		}
+	}
+}
+
+// EvaluateQuestions simulates games across all CPUs. Game g is seeded
+// with seed+g, so a report only depends on seed and games.
+func EvaluateQuestions(idx GameIndex, templates []QuestionTemplate, games int, seed int64) []TemplateEfficacy {
+	var options []simOption
+	for i, t := range templates {
+		if !t.HasLogic() {
+			continue
+		}
+		if len(t.Values) == 0 {
+			options = append(options, simOption{template: i})
+		}
+		for _, v := range t.Values {
+			options = append(options, simOption{template: i, value: v})
+		}
+	}
+
+	total := newEvalAccumulator(len(templates))
+	if len(idx.AllGameIDs) > 0 && len(options) > 0 {
+		workers := runtime.GOMAXPROCS(0)
+		results := make([]*evalAccumulator, workers)
+		var wg sync.WaitGroup
+
+		for w := 0; w < workers; w++ {
+			results[w] = newEvalAccumulator(len(templates))
+			wg.Add(1)
+			go func(acc *evalAccumulator, first int) {
+				defer wg.Done()
+				for g := first; g < games; g += workers {
+					simulateGame(idx, templates, options, rand.New(rand.NewSource(seed+int64(g))), acc)
+				}
+			}(results[w], w)
+		}
+		wg.Wait()
+
+		for _, acc := range results {
+			total.merge(acc)
+		}
+	}
+
+	stats := total.stats
+	for a, t := range templates {
+		stats[a].TemplateID = t.ID
+		for b := range templates {
+			if a != b && total.geq[a][b] && total.gt[a][b] {
+				stats[a].DominatedBy = append(stats[a].DominatedBy, templates[b].ID)
+			}
+		}
+	}
+
+	return stats
+}
+
+// RunQuestionEval implements the "eval-questions" command:
+//
+//	eval-questions [-games N] [-seed S]
+func RunQuestionEval(args []string, out io.Writer, idx GameIndex, templates []QuestionTemplate) int {
+	fs := flag.NewFlagSet("eval-questions", flag.ContinueOnError)
+	fs.SetOutput(out)
+	games := fs.Int("games", 2000, "number of games to simulate")
+	seed := fs.Int64("seed", 1, "random seed, for reproducible reports")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	stats := EvaluateQuestions(idx, templates, *games, *seed)
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].UsefulRate() > stats[j].UsefulRate()
+	})
+
+	fmt.Fprintf(out, "%d simulated games, %d templates, %d candidates\n\n", *games, len(templates), len(idx.Games))
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TEMPLATE\tASKED\tUSEFUL\tWASTED\tUSEFUL %\tBITS/ASK\tDOMINATED BY")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f\t%.3f\t%s\n",
+			s.TemplateID, s.Asked, s.Useful, s.Wasted, 100*s.UsefulRate(), s.BitsPerAsk(),
+			strings.Join(s.DominatedBy, ", "))
+	}
+	tw.Flush()
+
+	return 0
+}
@@ -1,6 +1,9 @@
 package guesser
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 // stringSliceContains performs a case-insensitive exact match search.
 func stringSliceContains(slice []string, value string) bool {
@@ -32,6 +35,45 @@ func scoreBucketRank(bucket string) int {
 	}
 }
 
+// playtimeBucketRank orders playtime buckets like scoreBucketRank; unknown
+// playtimes rank 0 and so match neither direction.
+func playtimeBucketRank(bucket string) int {
+	switch bucket {
+	case "<5h":
+		return 1
+	case "5-20h":
+		return 2
+	case "20-60h":
+		return 3
+	case "60h+":
+		return 4
+	default:
+		return 0
+	}
+}
+
+// multiplayerModeGroups maps the multiplayer_mode template's options to
+// the dataset builder's modes. "Multiplayer / Mixed" is in no group: the
+// builder couldn't tell what kind of multiplayer it is.
+var multiplayerModeGroups = map[string][]string{
+	"PvP":         {"Competitive Online", "Battle Royale"},
+	"PvE":         {"Online Co-op", "Local Co-op"},
+	"MMO":         {"MMO"},
+	"Local Co-op": {"Local Co-op"},
+	"None":        {"Singleplayer"},
+}
+
+// multiplayerModeMatches reports whether a game's mode is in option's
+// group.
+func multiplayerModeMatches(mode, option string) bool {
+	for _, m := range multiplayerModeGroups[option] {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
 // ageRatingValue maps "3+", "7+", "12+", "16+", "18+" to numeric values.
 func ageRatingValue(age string) int {
 	switch age {
@@ -49,3 +91,18 @@ func ageRatingValue(age string) int {
 		return 0
 	}
 }
+
+// parseYearRange splits a range like "2010–2014" (en dash or hyphen) into
+// its first and last year.
+func parseYearRange(r string) (from, to int, ok bool) {
+	a, b, found := strings.Cut(strings.ReplaceAll(r, "–", "-"), "-")
+	if !found {
+		return 0, 0, false
+	}
+	from, err1 := strconv.Atoi(strings.TrimSpace(a))
+	to, err2 := strconv.Atoi(strings.TrimSpace(b))
+	if err1 != nil || err2 != nil || from > to {
+		return 0, 0, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,194 @@
+package guesser
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenBucket allows bursts of up to capacity requests, refilled at rate
+// tokens per second.
+type tokenBucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64
+	last     time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		tokens:   float64(burst),
+		capacity: float64(burst),
+		rate:     rate,
+		last:     now,
+	}
+}
+
+// take consumes a token if one is available. When it is not, it returns
+// how long until one will be.
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / b.rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// RateLimit is requests per minute with a burst allowance.
+type RateLimit struct {
+	RequestsPerMinute int `json:"requestsPerMinute"`
+	Burst             int `json:"burst"`
+}
+
+// full reports whether the bucket would be back at capacity by now, i.e.
+// forgetting it changes nothing.
+func (b *tokenBucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.capacity
+}
+
+// rateLimiter keeps one token bucket per key (client IP, API key, ...).
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow reports whether key may make another request under limit.
+// A zero RequestsPerMinute means unlimited.
+func (l *rateLimiter) allow(key string, limit RateLimit) (bool, time.Duration) {
+	if limit.RequestsPerMinute <= 0 {
+		return true, 0
+	}
+
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Client IPs come and go, so drop refilled buckets now and then.
+	if now.Sub(l.lastSweep) > time.Minute {
+		for k, b := range l.buckets {
+			if b.full(now) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(float64(limit.RequestsPerMinute)/60, burst, now)
+		l.buckets[key] = bucket
+	}
+
+	return bucket.take(now)
+}
+
+// IPRateLimits caps what one client IP may do, so nobody can open
+// thousands of sessions or brute-force the candidates with /ask.
+type IPRateLimits struct {
+	// SessionStart covers everything that creates a session or room.
+	SessionStart RateLimit
+	// Play covers ask, guess and answer, over HTTP and WebSocket.
+	Play RateLimit
+}
+
+func DefaultIPRateLimits() IPRateLimits {
+	return IPRateLimits{
+		SessionStart: RateLimit{RequestsPerMinute: 30, Burst: 10},
+		Play:         RateLimit{RequestsPerMinute: 120, Burst: 30},
+	}
+}
+
+var (
+	ipLimits  = DefaultIPRateLimits()
+	ipLimiter = newRateLimiter()
+)
+
+// ConfigureIPRateLimits replaces the per-IP limits. Call it before serving.
+func ConfigureIPRateLimits(limits IPRateLimits) {
+	ipLimits = limits
+}
+
+// playActions are the session actions that reveal something about the
+// secret, and so are limited as Play.
+var playActions = map[string]bool{"ask": true, "guess": true, "answer": true, "hint": true}
+
+// ipRateLimitClass names the limit a request falls under, or "".
+func ipRateLimitClass(r *http.Request) string {
+	if r.Method != http.MethodPost {
+		return ""
+	}
+
+	route, ok := apiRoute(r.URL.Path)
+	if !ok {
+		return ""
+	}
+	switch route {
+	case "session/start", "daily/start", "steam/start", "stateless/start", "room/create":
+		return "start"
+	}
+
+	parts := strings.Split(route, "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	switch {
+	case parts[0] == "room" && parts[2] == "join":
+		return "start"
+	case (parts[0] == "session" || parts[0] == "stateless" && parts[1] == "session") && playActions[parts[2]]:
+		return "play"
+	}
+	return ""
+}
+
+// allowIP takes a token from the client's bucket for class, answering
+// 429 with Retry-After when it is empty.
+func allowIP(w http.ResponseWriter, r *http.Request, class string) bool {
+	limit := ipLimits.Play
+	if class == "start" {
+		limit = ipLimits.SessionStart
+	}
+
+	ok, wait := ipLimiter.allow(class+"|"+clientIP(r), limit)
+	if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeError(w, http.StatusTooManyRequests, CodeRateLimited, "too many requests, slow down")
+	}
+	return ok
+}
+
+// IPRateLimitMiddleware applies IPRateLimits to session creation and
+// play. Requests with an API key are limited by the key instead, since
+// one server may relay many players.
+func IPRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := ipRateLimitClass(r)
+		if class != "" {
+			if _, keyed := apiKeyFromContext(r.Context()); !keyed && !allowIP(w, r, class) {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
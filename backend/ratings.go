@@ -0,0 +1,179 @@
+package guesser
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// A race room of exactly two players is a duel. Once both have finished,
+// their Elo ratings move: finding the secret beats not finding it, and
+// between two finders fewer questions wins; anything else is a draw.
+// Only players an API-key client names with clientId are rated, scoped to
+// the key: a gg_client cookie can be dropped for a fresh 1500-rated
+// identity at will, so browser players aren't rated at all. A key can
+// still name as many players as it likes; the ladder is only as honest
+// as the key holders. GET /api/v1/ladder ranks them. In cluster mode every instance sees every
+// room's progress and rates each duel itself.
+
+const (
+	eloStart = 1500
+	// eloK is the most one duel can move a rating.
+	eloK = 32
+
+	defaultLadderLimit = 25
+	maxLadderLimit     = 100
+)
+
+type ratingKey struct {
+	Tenant string
+	Client string
+}
+
+// PlayerRating is one player's standing. Name is the one they last
+// dueled under.
+type PlayerRating struct {
+	Name   string  `json:"name"`
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+}
+
+type ratingStore struct {
+	mu      sync.Mutex
+	players map[ratingKey]*PlayerRating
+}
+
+// global in-memory Elo ladder
+var ratings = &ratingStore{players: make(map[ratingKey]*PlayerRating)}
+
+// duelScore is a's result against b: 1 for a win, 0.5 for a draw, 0 for a
+// loss.
+func duelScore(a, b PlayerProgress) float64 {
+	switch {
+	case a.Won != b.Won:
+		if a.Won {
+			return 1
+		}
+		return 0
+	case !a.Won || a.QuestionsUsed == b.QuestionsUsed:
+		return 0.5
+	case a.QuestionsUsed < b.QuestionsUsed:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// eloExpected is the score a player rated ra is expected to make
+// against one rated rb.
+func eloExpected(ra, rb float64) float64 {
+	return 1 / (1 + math.Pow(10, (rb-ra)/400))
+}
+
+func (s *ratingStore) playerLocked(key ratingKey, name string) *PlayerRating {
+	p, ok := s.players[key]
+	if !ok {
+		p = &PlayerRating{Rating: eloStart}
+		s.players[key] = p
+	}
+	p.Name = name
+	return p
+}
+
+// recordDuel rates a finished duel between a and b of tenant.
+func (s *ratingStore) recordDuel(tenant string, a, b RoomPlayer, pa, pb PlayerProgress) {
+	if a.Client == "" || b.Client == "" || a.Client == b.Client {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ra := s.playerLocked(ratingKey{Tenant: tenant, Client: a.Client}, a.Name)
+	rb := s.playerLocked(ratingKey{Tenant: tenant, Client: b.Client}, b.Name)
+
+	score := duelScore(pa, pb)
+	delta := eloK * (score - eloExpected(ra.Rating, rb.Rating))
+	ra.Rating += delta
+	rb.Rating -= delta
+
+	ra.Games++
+	rb.Games++
+	switch score {
+	case 1:
+		ra.Wins++
+		rb.Losses++
+	case 0:
+		ra.Losses++
+		rb.Wins++
+	default:
+		ra.Draws++
+		rb.Draws++
+	}
+}
+
+type LadderEntry struct {
+	Rank int `json:"rank"`
+	PlayerRating
+}
+
+type LadderResponse struct {
+	Players []LadderEntry `json:"players"`
+}
+
+// ladder ranks tenant's players by rating, best first.
+func (s *ratingStore) ladder(tenant string, limit int) LadderResponse {
+	s.mu.Lock()
+	var players []PlayerRating
+	for key, p := range s.players {
+		if key.Tenant == tenant {
+			players = append(players, *p)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(players, func(i, j int) bool {
+		a, b := players[i], players[j]
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		if a.Games != b.Games {
+			return a.Games > b.Games
+		}
+		return a.Name < b.Name
+	})
+
+	resp := LadderResponse{Players: []LadderEntry{}}
+	for i, p := range players {
+		if i == limit {
+			break
+		}
+		p.Rating = math.Round(p.Rating*10) / 10
+		resp.Players = append(resp.Players, LadderEntry{Rank: i + 1, PlayerRating: p})
+	}
+	return resp
+}
+
+// ---------------------------------
+// /api/v1/ladder   (GET, ?limit=)
+// ---------------------------------
+
+func LadderHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		limit, ok := queryInt(r, "limit", defaultLadderLimit, maxLadderLimit)
+		if !ok {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "limit must be a positive integer")
+			return
+		}
+		writeResponse(w, r, http.StatusOK, ratings.ladder(tenantID(r), limit))
+	})
+}
@@ -0,0 +1,169 @@
+package guesser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecapQuestion is one asked question as shown on the recap screen.
+type RecapQuestion struct {
+	QuestionTypeID   string `json:"questionTypeId"`
+	Option           string `json:"option"`
+	Text             string `json:"text"`
+	Answer           bool   `json:"answer"`
+	Negated          bool   `json:"negated,omitempty"`
+	CandidatesBefore int    `json:"candidatesBefore"`
+	CandidatesAfter  int    `json:"candidatesAfter"`
+	Eliminated       int    `json:"eliminated"`
+}
+
+// Recap is everything the end-of-game screen shows, so the frontend does
+// not need extra calls once a session finishes.
+type Recap struct {
+	Outcome         Outcome         `json:"outcome"`
+	Mode            SessionMode     `json:"mode"`
+	Questions       []RecapQuestion `json:"questions"`
+	Guesses         []GuessRecord   `json:"guesses"`
+	Timeline        []int           `json:"timeline"`
+	DurationSeconds float64         `json:"durationSeconds"`
+	Score           int             `json:"score"`
+	Secret          Game            `json:"secret"`
+	// ShareToken addresses this recap at /api/v1/recap/{token}/card.
+	ShareToken string `json:"shareToken,omitempty"`
+}
+
+// SessionSummary sums up a game in the answer to the guess that ended it.
+type SessionSummary struct {
+	QuestionsAsked      int     `json:"questionsAsked"`
+	ElapsedSeconds      float64 `json:"elapsedSeconds"`
+	HintsUsed           int     `json:"hintsUsed"`
+	CandidatesRemaining int     `json:"candidatesRemaining"`
+	// Percentile is how many of the other games with the same secret
+	// (OtherGames of them) this one outscored, in percent. It is left out
+	// until someone else has played that secret.
+	Percentile *float64 `json:"percentile,omitempty"`
+	OtherGames int      `json:"otherGames"`
+}
+
+// sessionSummary sums up session, which completeSession has just ended.
+func sessionSummary(session *Session) *SessionSummary {
+	state := session.State
+	summary := &SessionSummary{
+		QuestionsAsked:      len(state.Asked),
+		ElapsedSeconds:      state.FinishedAt.Sub(state.StartedAt).Seconds(),
+		HintsUsed:           len(state.Hints),
+		CandidatesRemaining: len(state.RemainingIDs),
+	}
+	key := statsKeyFor(session.Tenant, session.DatasetID)
+	if pct, others, ok := stats.percentile(key, state.SecretID, Score(state)); ok {
+		pct = math.Round(pct*10) / 10
+		summary.Percentile = &pct
+		summary.OtherGames = others
+	}
+	return summary
+}
+
+// candidateTimeline returns a copy of the session's candidate-count series.
+func candidateTimeline(state SessionState) []int {
+	timeline := make([]int, len(state.Timeline))
+	copy(timeline, state.Timeline)
+	return timeline
+}
+
+// TimelineText renders a timeline as "500 → 120 → 13 → 2".
+func TimelineText(timeline []int) string {
+	parts := make([]string, len(timeline))
+	for i, n := range timeline {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, " → ")
+}
+
+// Score rates a finished session: a win starts at 1000 and loses points
+// for every question, wrong guess and hint; anything but a win scores 0.
+func Score(state SessionState) int {
+	if state.Outcome != OutcomeWon {
+		return 0
+	}
+
+	wrong := 0
+	for _, g := range state.Guesses {
+		if !g.Correct {
+			wrong++
+		}
+	}
+
+	score := 1000 - 40*len(state.Asked) - 100*wrong - hintPenalty(state)
+	if score < 100 {
+		score = 100
+	}
+	return score
+}
+
+// questionHistory renders the asked questions with their text and the
+// candidates each one eliminated.
+func questionHistory(state SessionState, templates []QuestionTemplate) []RecapQuestion {
+	byID := make(map[string]QuestionTemplate, len(templates))
+	for _, t := range templates {
+		byID[t.ID] = t
+	}
+
+	questions := make([]RecapQuestion, 0, len(state.Asked))
+	before := state.PoolSize
+	for _, q := range state.Asked {
+		text := q.QuestionTypeID
+		if t, ok := byID[q.QuestionTypeID]; ok {
+			text = t.Text(q.Option)
+			if q.Negated {
+				text = t.NegatedText(q.Option)
+			}
+		}
+
+		questions = append(questions, RecapQuestion{
+			QuestionTypeID:   q.QuestionTypeID,
+			Option:           q.Option,
+			Text:             text,
+			Answer:           q.Answer,
+			Negated:          q.Negated,
+			CandidatesBefore: before,
+			CandidatesAfter:  q.CandidatesAfter,
+			Eliminated:       before - q.CandidatesAfter,
+		})
+		before = q.CandidatesAfter
+	}
+
+	return questions
+}
+
+// BuildRecap assembles the recap for a finished session.
+func BuildRecap(state SessionState, idx GameIndex, templates []QuestionTemplate) Recap {
+	questions := questionHistory(state, templates)
+
+	end := state.FinishedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	guesses := state.Guesses
+	if guesses == nil {
+		guesses = []GuessRecord{}
+	}
+
+	var secret Game
+	if g, ok := idx.Games[state.SecretID]; ok {
+		secret = *g
+	}
+
+	return Recap{
+		Outcome:         state.Outcome,
+		Mode:            state.Mode,
+		Questions:       questions,
+		Guesses:         guesses,
+		Timeline:        candidateTimeline(state),
+		DurationSeconds: end.Sub(state.StartedAt).Seconds(),
+		Score:           Score(state),
+		Secret:          secret,
+	}
+}
@@ -0,0 +1,235 @@
+package guesser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // cover art is usually JPEG
+	"image/png"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Cards use the Open Graph recommended 1200x630 size.
+const (
+	cardWidth  = 1200
+	cardHeight = 630
+)
+
+var (
+	cardBackground = color.RGBA{0x11, 0x18, 0x27, 0xff}
+	cardAccent     = color.RGBA{0x63, 0x66, 0xf1, 0xff}
+	cardText       = color.RGBA{0xf9, 0xfa, 0xfb, 0xff}
+	cardMuted      = color.RGBA{0x9c, 0xa3, 0xaf, 0xff}
+)
+
+// cardLines is the text content shared by the PNG and SVG cards.
+type cardLines struct {
+	Headline string
+	Detail   string
+	Score    string
+	Date     string
+	Title    string // secret's name; empty unless the game was won
+}
+
+func cardContent(stored StoredRecap) cardLines {
+	recap := stored.Recap
+	lines := cardLines{
+		Score: fmt.Sprintf("Score %d", recap.Score),
+		Date:  stored.CreatedAt.UTC().Format("2 Jan 2006"),
+	}
+
+	lines.Headline = recapHeadline(recap.Outcome, len(recap.Questions))
+	if recap.Outcome == OutcomeWon {
+		lines.Title = recap.Secret.Name
+	}
+
+	lines.Detail = "Candidates: " + TimelineText(recap.Timeline)
+	return lines
+}
+
+// recapHeadline sums up how a game of questions questions ended.
+func recapHeadline(outcome Outcome, questions int) string {
+	switch outcome {
+	case OutcomeWon:
+		return fmt.Sprintf("Solved in %d questions", questions)
+	case OutcomeGaveUp:
+		return fmt.Sprintf("Gave up after %d questions", questions)
+	default:
+		return fmt.Sprintf("Stumped after %d questions", questions)
+	}
+}
+
+// RenderRecapSVG draws the result card as SVG.
+func RenderRecapSVG(stored StoredRecap) []byte {
+	lines := cardContent(stored)
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		cardWidth, cardHeight, cardWidth, cardHeight)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="#111827"/>`)
+	fmt.Fprintf(&b, `<rect width="100%%" height="12" fill="#6366f1"/>`)
+
+	textX := 70
+	if lines.Title != "" && stored.Recap.Secret.ImageURL != "" {
+		fmt.Fprintf(&b, `<image href="%s" x="70" y="115" width="400" height="400" preserveAspectRatio="xMidYMid slice"/>`,
+			html.EscapeString(stored.Recap.Secret.ImageURL))
+		textX = 520
+	}
+
+	font := `font-family="Helvetica, Arial, sans-serif"`
+	fmt.Fprintf(&b, `<text x="%d" y="150" %s font-size="36" fill="#9ca3af">Game Guesser</text>`, textX, font)
+	fmt.Fprintf(&b, `<text x="%d" y="240" %s font-size="60" font-weight="bold" fill="#f9fafb">%s</text>`,
+		textX, font, html.EscapeString(lines.Headline))
+	if lines.Title != "" {
+		fmt.Fprintf(&b, `<text x="%d" y="310" %s font-size="40" fill="#f9fafb">%s</text>`,
+			textX, font, html.EscapeString(lines.Title))
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="390" %s font-size="32" fill="#9ca3af">%s</text>`,
+		textX, font, html.EscapeString(lines.Detail))
+	fmt.Fprintf(&b, `<text x="%d" y="500" %s font-size="44" font-weight="bold" fill="#6366f1">%s</text>`,
+		textX, font, html.EscapeString(lines.Score))
+	fmt.Fprintf(&b, `<text x="%d" y="560" %s font-size="28" fill="#9ca3af">%s</text>`,
+		textX, font, html.EscapeString(lines.Date))
+	b.WriteString(`</svg>`)
+
+	return b.Bytes()
+}
+
+// drawText renders s with the built-in bitmap font, scaled up by an
+// integer factor so it stays legible at card size.
+func drawText(dst draw.Image, x, y int, s string, scale int, c color.Color) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, s).Ceil()
+	height := face.Metrics().Height.Ceil()
+	if width == 0 {
+		return
+	}
+
+	small := image.NewRGBA(image.Rect(0, 0, width, height))
+	d := font.Drawer{
+		Dst:  small,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
+	}
+	d.DrawString(s)
+
+	target := image.Rect(x, y, x+width*scale, y+height*scale)
+	xdraw.NearestNeighbor.Scale(dst, target, small, small.Bounds(), draw.Over, nil)
+}
+
+// fetchCover downloads and decodes cover art, giving up quickly so a slow
+// image host cannot stall card rendering.
+func fetchCover(ctx context.Context, url string) (image.Image, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cover art: HTTP %d", resp.StatusCode)
+	}
+
+	img, _, err := image.Decode(resp.Body)
+	return img, err
+}
+
+// RenderRecapPNG draws the result card as PNG. Cover art is included when
+// the game was won and the art can be fetched; otherwise the card is
+// text only.
+func RenderRecapPNG(ctx context.Context, stored StoredRecap) ([]byte, error) {
+	lines := cardContent(stored)
+	card := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	draw.Draw(card, card.Bounds(), image.NewUniform(cardBackground), image.Point{}, draw.Src)
+	draw.Draw(card, image.Rect(0, 0, cardWidth, 12), image.NewUniform(cardAccent), image.Point{}, draw.Src)
+
+	textX := 70
+	if lines.Title != "" && stored.Recap.Secret.ImageURL != "" {
+		if cover, err := fetchCover(ctx, stored.Recap.Secret.ImageURL); err == nil {
+			xdraw.CatmullRom.Scale(card, image.Rect(70, 115, 470, 515), cover, cover.Bounds(), draw.Src, nil)
+			textX = 520
+		}
+	}
+
+	drawText(card, textX, 110, "Game Guesser", 3, cardMuted)
+	drawText(card, textX, 180, lines.Headline, 4, cardText)
+	if lines.Title != "" {
+		drawText(card, textX, 260, truncateRunes(lines.Title, 26), 3, cardText)
+	}
+	drawText(card, textX, 340, truncateRunes(lines.Detail, 40), 2, cardMuted)
+	drawText(card, textX, 430, lines.Score, 4, cardAccent)
+	drawText(card, textX, 520, lines.Date, 2, cardMuted)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, card); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+// ---------------------------------
+// /api/v1/recap/{token}/card   (GET, ?format=png|svg)
+// ---------------------------------
+
+func RecapHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		stored, ok := recaps.get(mux.Vars(r)["token"])
+		if !ok {
+			writeError(w, http.StatusNotFound, CodeUnknownRecap, "unknown recap")
+			return
+		}
+
+		// Finished recaps never change, so previews can cache aggressively.
+		w.Header().Set("Cache-Control", "public, max-age=86400, immutable")
+
+		switch r.URL.Query().Get("format") {
+		case "svg":
+			w.Header().Set("Content-Type", "image/svg+xml")
+			_, _ = w.Write(RenderRecapSVG(stored))
+		case "", "png":
+			data, err := RenderRecapPNG(r.Context(), stored)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, CodeInternal, "could not render card")
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(data)
+		default:
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "format must be png or svg")
+		}
+	})
+}
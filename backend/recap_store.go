@@ -0,0 +1,92 @@
+package guesser
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// recapTTL is how long a share link keeps working.
+	recapTTL = 30 * 24 * time.Hour
+	// maxRecaps bounds the store; past it, expired recaps are dropped,
+	// then the oldest half.
+	maxRecaps = 100_000
+)
+
+// StoredRecap is a frozen recap that can be looked up by its share token,
+// e.g. to render a card for a link preview long after the game ended
+// (up to recapTTL).
+type StoredRecap struct {
+	Recap     Recap
+	CreatedAt time.Time
+}
+
+type recapStore struct {
+	mu     sync.RWMutex
+	recaps map[string]StoredRecap
+}
+
+func newRecapStore() *recapStore {
+	return &recapStore{
+		recaps: make(map[string]StoredRecap),
+	}
+}
+
+// save stores recap under a new share token and returns the token.
+func (s *recapStore) save(recap Recap) string {
+	token := randomToken(8)
+	recap.ShareToken = token
+
+	now := time.Now()
+	s.mu.Lock()
+	if len(s.recaps) >= maxRecaps {
+		s.forgetLocked(now)
+	}
+	s.recaps[token] = StoredRecap{Recap: recap, CreatedAt: now}
+	s.mu.Unlock()
+
+	return token
+}
+
+func (s *recapStore) get(token string) (StoredRecap, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.recaps[token]
+	if !ok || time.Since(stored.CreatedAt) > recapTTL {
+		return StoredRecap{}, false
+	}
+	return stored, true
+}
+
+func (s *recapStore) forgetLocked(now time.Time) {
+	for token, stored := range s.recaps {
+		if now.Sub(stored.CreatedAt) > recapTTL {
+			delete(s.recaps, token)
+		}
+	}
+	if len(s.recaps) < maxRecaps {
+		return
+	}
+
+	tokens := make([]string, 0, len(s.recaps))
+	for token := range s.recaps {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return s.recaps[tokens[i]].CreatedAt.Before(s.recaps[tokens[j]].CreatedAt) })
+	for _, token := range tokens[:len(tokens)/2] {
+		delete(s.recaps, token)
+	}
+}
+
+// global in-memory recap store
+var recaps = newRecapStore()
+
+// shareRecap builds the recap for a finished session and registers it for
+// sharing.
+func shareRecap(state SessionState, idx GameIndex, templates []QuestionTemplate) Recap {
+	recap := BuildRecap(state, idx, templates)
+	recap.ShareToken = recaps.save(recap)
+	return recap
+}
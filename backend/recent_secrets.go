@@ -0,0 +1,121 @@
+package guesser
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// New sessions avoid the secrets a client had in its last
+// Rules.RecentSecrets games, so two back-to-back games don't pick the same
+// title. Clients are the gg_client cookie, or the clientId a bot with an
+// API key sends for each of its users.
+
+// maxRecentClients bounds the store; past it, the least recently seen
+// half is forgotten.
+const maxRecentClients = 100_000
+
+type recentKey struct {
+	Tenant string
+	Client string
+}
+
+type recentEntry struct {
+	ids  []int // oldest first
+	used time.Time
+}
+
+type recentSecretStore struct {
+	mu      sync.Mutex
+	clients map[recentKey]*recentEntry
+}
+
+// global in-memory store of each client's recent secrets
+var recentSecrets = &recentSecretStore{clients: make(map[recentKey]*recentEntry)}
+
+// recentClient identifies the caller of a session start.
+func recentClient(w http.ResponseWriter, r *http.Request, req StartSessionRequest) recentKey {
+	return recentKey{Tenant: tenantID(r), Client: requestClient(w, r, req.ClientID)}
+}
+
+// requestClient identifies a caller by cookie, issued on first contact.
+// A caller with an API key may name its player with clientId instead,
+// scoped to the key so that no one can speak for another key's players.
+func requestClient(w http.ResponseWriter, r *http.Request, id string) string {
+	if client := ratedClient(r, id); client != "" {
+		return client
+	}
+	return "cookie:" + clientID(w, r)
+}
+
+// ratedClient is the caller's identity on the ladder, or "" for none. Only
+// an API key's clientId counts: anyone can drop a cookie and come back as
+// a fresh 1500-rated player, and losing to such throwaway players on
+// purpose would farm rating.
+func ratedClient(r *http.Request, id string) string {
+	if key, ok := apiKeyFromContext(r.Context()); ok && id != "" {
+		return "key:" + key.scope() + ":" + id
+	}
+	return ""
+}
+
+// recent returns the client's recent secrets.
+func (s *recentSecretStore) recent(key recentKey) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.clients[key]; ok {
+		return slices.Clone(e.ids)
+	}
+	return nil
+}
+
+// add records secretID as the client's latest secret.
+func (s *recentSecretStore) add(key recentKey, secretID int) {
+	if rules.RecentSecrets <= 0 || secretID == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.clients[key]
+	if !ok {
+		if len(s.clients) >= maxRecentClients {
+			s.forgetOldestLocked(len(s.clients) / 2)
+		}
+		e = &recentEntry{}
+		s.clients[key] = e
+	}
+	e.ids = append(e.ids, secretID)
+	if over := len(e.ids) - rules.RecentSecrets; over > 0 {
+		e.ids = slices.Delete(e.ids, 0, over)
+	}
+	e.used = time.Now()
+}
+
+func (s *recentSecretStore) forgetOldestLocked(n int) {
+	keys := make([]recentKey, 0, len(s.clients))
+	for k := range s.clients {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return s.clients[keys[i]].used.Before(s.clients[keys[j]].used) })
+	for _, k := range keys[:n] {
+		delete(s.clients, k)
+	}
+}
+
+// withoutRecent returns pool minus recent, or pool itself when that would
+// leave nothing to draw.
+func withoutRecent(pool, recent []int) []int {
+	if len(recent) == 0 {
+		return pool
+	}
+	fresh := slices.DeleteFunc(slices.Clone(pool), func(id int) bool { return slices.Contains(recent, id) })
+	if len(fresh) == 0 {
+		return pool
+	}
+	return fresh
+}
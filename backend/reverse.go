@@ -0,0 +1,208 @@
+package guesser
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// In reverse mode the player thinks of a game and the solver asks. The
+// session's Outcome describes the solver: won when it named the game,
+// lost when it ran out of candidates.
+
+// maxTolerance caps StartSessionRequest.Tolerance: every forgiven answer
+// keeps more candidates around and makes the solver ask longer.
+const maxTolerance = 3
+
+type NextQuestionResponse struct {
+	// Either the question fields or Guess is set, never both.
+	QuestionTypeID string       `json:"questionTypeId,omitempty"`
+	Option         string       `json:"option,omitempty"`
+	QuestionText   string       `json:"questionText,omitempty"`
+	Guess          *GameSummary `json:"guess,omitempty"`
+
+	QuestionNumber  int           `json:"questionNumber"`
+	CandidatesCount int           `json:"candidatesCount"`
+	Status          SessionStatus `json:"status"`
+}
+
+type AnswerRequest struct {
+	Answer bool `json:"answer"`
+}
+
+type AnswerResponse struct {
+	CandidatesCount int           `json:"candidatesCount"`
+	EliminatedCount int           `json:"eliminatedCount"`
+	Status          SessionStatus `json:"status"`
+	Outcome         Outcome       `json:"outcome,omitempty"`
+	// Recap is filled in once the answer has ended the session.
+	Recap *Recap `json:"recap,omitempty"`
+}
+
+func findTemplate(templates []QuestionTemplate, id string) (QuestionTemplate, bool) {
+	for _, t := range templates {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return QuestionTemplate{}, false
+}
+
+func nextQuestionResponse(state SessionState, templates []QuestionTemplate, idx GameIndex) NextQuestionResponse {
+	resp := NextQuestionResponse{
+		QuestionNumber:  len(state.Asked) + len(state.Guesses) + 1,
+		CandidatesCount: len(state.RemainingIDs),
+		Status:          state.Status,
+	}
+
+	p := state.Pending
+	if p.GuessID != 0 {
+		summary := summarize(idx.Games[p.GuessID])
+		resp.Guess = &summary
+		return resp
+	}
+
+	resp.QuestionTypeID = p.QuestionTypeID
+	resp.Option = p.Option
+	if t, ok := findTemplate(templates, p.QuestionTypeID); ok {
+		resp.QuestionText = t.Text(p.Option)
+	}
+	return resp
+}
+
+// leadingCandidate is the remaining game that has contradicted the fewest
+// answers, the first one on ties.
+func leadingCandidate(state SessionState) int {
+	best := state.RemainingIDs[0]
+	for _, id := range state.RemainingIDs[1:] {
+		if state.Penalties[id] < state.Penalties[best] {
+			best = id
+		}
+	}
+	return best
+}
+
+// handleNextQuestion returns the solver's pending question, choosing one
+// first if needed. Asking again before answering repeats the question.
+func handleNextQuestion(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	idx GameIndex,
+	templates []QuestionTemplate,
+) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+
+	state := &session.State
+	if state.Mode != ModeReverse {
+		writeError(w, http.StatusConflict, CodeWrongMode, "next-question is only available in reverse mode")
+		return
+	}
+	if state.Status == StatusFinished {
+		writeError(w, http.StatusConflict, CodeSessionFinished, finishedMessage(session.State))
+		return
+	}
+
+	if state.Pending == nil {
+		// Guess once one game is left, or when no question can split
+		// the candidates any further. With a tolerance the guess is the
+		// game that contradicted the fewest answers.
+		if t, value, ok := NextQuestion(*state, templates, idx); ok && len(state.RemainingIDs) > 1 {
+			state.Pending = &PendingQuestion{QuestionTypeID: t.ID, Option: value}
+		} else {
+			state.Pending = &PendingQuestion{GuessID: leadingCandidate(*state)}
+		}
+	}
+
+	writeResponse(w, r, http.StatusOK, nextQuestionResponse(*state, templates, idx))
+}
+
+// handleAnswer records the player's yes/no to the pending question or
+// guess.
+func handleAnswer(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	idx GameIndex,
+	templates []QuestionTemplate,
+) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+
+	state := &session.State
+	if state.Mode != ModeReverse {
+		writeError(w, http.StatusConflict, CodeWrongMode, "answer is only available in reverse mode")
+		return
+	}
+	if state.Status == StatusFinished {
+		writeError(w, http.StatusConflict, CodeSessionFinished, finishedMessage(session.State))
+		return
+	}
+	if state.Pending == nil {
+		writeError(w, http.StatusConflict, CodeNoPendingQuestion, "no pending question: call next-question first")
+		return
+	}
+
+	var req AnswerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "bad json")
+		return
+	}
+
+	before := len(state.RemainingIDs)
+	pending := *state.Pending
+	state.Pending = nil
+
+	if pending.GuessID != 0 {
+		guessed := idx.Games[pending.GuessID]
+		state.Guesses = append(state.Guesses, GuessRecord{
+			Guess:   guessed.Name,
+			GameID:  guessed.ID,
+			Correct: req.Answer,
+		})
+
+		if req.Answer {
+			state.SecretID = guessed.ID
+		} else {
+			remaining := make([]int, 0, len(state.RemainingIDs))
+			for _, id := range state.RemainingIDs {
+				if id != guessed.ID {
+					remaining = append(remaining, id)
+				}
+			}
+			state.RemainingIDs = remaining
+			state.Status = statusFor(remaining)
+		}
+	} else {
+		t, ok := findTemplate(templates, pending.QuestionTypeID)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "pending question no longer exists")
+			return
+		}
+		*state = ApplyAnswer(*state, t, idx, pending.Option, req.Answer)
+	}
+
+	resp := AnswerResponse{
+		CandidatesCount: len(state.RemainingIDs),
+		EliminatedCount: before - len(state.RemainingIDs),
+	}
+
+	switch {
+	case pending.GuessID != 0 && req.Answer:
+		recap := completeSession(session, OutcomeWon, idx, templates)
+		resp.Recap = &recap
+	case len(state.RemainingIDs) == 0:
+		// The answers rule out every game we know (or the player's game
+		// is not in the dataset).
+		recap := completeSession(session, OutcomeLost, idx, templates)
+		resp.Recap = &recap
+	}
+
+	resp.Status = state.Status
+	resp.Outcome = state.Outcome
+	writeResponse(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,306 @@
+package guesser
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Room state changes are published as RoomEvents so that, with several
+// replicas behind a load balancer, every instance keeps a mirror of every
+// room and can push updates to its own WebSocket clients. Sessions still
+// live on the instance that created them, so session traffic needs sticky
+// routing; only room membership and progress are shared.
+
+type RoomEventType string
+
+const (
+	RoomEventCreated  RoomEventType = "created"
+	RoomEventJoined   RoomEventType = "joined"
+	RoomEventProgress RoomEventType = "progress"
+	// RoomEventStatus is only sent to WebSocket clients, as the first
+	// message after they connect.
+	RoomEventStatus RoomEventType = "status"
+)
+
+const roomEventsTopic = "guesser.rooms"
+
+type RoomEvent struct {
+	Type   RoomEventType `json:"type"`
+	RoomID string        `json:"roomId"`
+	// Origin is the publishing instance, which has already applied the
+	// event locally and skips it when it comes back from the broker.
+	Origin string `json:"origin"`
+
+	// created
+	Tenant  string   `json:"tenant,omitempty"`
+	Mode    RoomMode `json:"mode,omitempty"`
+	PoolIDs []int    `json:"poolIds,omitempty"`
+
+	// joined, progress; SecretID is also set on created for race rooms
+	Player   *RoomPlayer     `json:"player,omitempty"`
+	SecretID int             `json:"secretId,omitempty"`
+	Progress *PlayerProgress `json:"progress,omitempty"`
+}
+
+// RoomUpdate is what WebSocket clients receive after every change.
+type RoomUpdate struct {
+	Type RoomEventType      `json:"type"`
+	Room RoomStatusResponse `json:"room"`
+}
+
+var (
+	// bus is nil when running as a single instance.
+	bus        PubSub
+	instanceID = randomToken(8)
+)
+
+// ConfigurePubSub enables cluster mode: room events and stateless moves
+// are exchanged with other instances through the broker at rawURL.
+func ConfigurePubSub(rawURL string) error {
+	ps, err := NewPubSub(rawURL)
+	if err != nil {
+		return err
+	}
+
+	err = ps.Subscribe(context.Background(), roomEventsTopic, func(payload []byte) {
+		var ev RoomEvent
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			slog.Warn("pubsub: bad room event", "err", err)
+			return
+		}
+		if ev.Origin != instanceID {
+			applyRoomEvent(ev)
+		}
+	})
+	if err == nil {
+		err = subscribeStatelessMoves(ps)
+	}
+	if err != nil {
+		ps.Close()
+		return err
+	}
+
+	bus = ps
+	return nil
+}
+
+// publishRoomEvent applies ev locally and forwards it to other instances.
+func publishRoomEvent(ev RoomEvent) {
+	ev.Origin = instanceID
+	applyRoomEvent(ev)
+
+	if bus == nil {
+		return
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		slog.Error("pubsub: encode room event", "err", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := bus.Publish(ctx, roomEventsTopic, payload); err != nil {
+		slog.Error("pubsub: publish room event", "err", err)
+	}
+}
+
+// applyRoomEvent updates this instance's copy of the room and notifies
+// its WebSocket clients. Every case is idempotent.
+func applyRoomEvent(ev RoomEvent) {
+	if ev.Type == RoomEventCreated {
+		rooms.mirror(ev.RoomID, ev.Tenant, ev.Mode, ev.PoolIDs, ev.SecretID)
+		return
+	}
+
+	room, ok := rooms.get(ev.RoomID)
+	if !ok || ev.Player == nil {
+		// Created before this instance subscribed; nothing to update.
+		return
+	}
+
+	switch ev.Type {
+	case RoomEventJoined:
+		room.recordPlayer(*ev.Player, ev.SecretID)
+	case RoomEventProgress:
+		if ev.Progress == nil {
+			return
+		}
+		room.updateProgress(ev.Player.SessionID, *ev.Progress)
+	default:
+		return
+	}
+
+	hub.broadcast(room.ID, RoomUpdate{Type: ev.Type, Room: room.Status()})
+}
+
+// notifyRoomProgress publishes a room player's progress after their
+// session changed. Sessions outside rooms are ignored.
+func notifyRoomProgress(session *Session) {
+	if session.RoomID == "" {
+		return
+	}
+
+	room, ok := rooms.get(session.RoomID)
+	if !ok {
+		return
+	}
+
+	name := ""
+	for _, p := range room.Players() {
+		if p.SessionID == session.ID {
+			name = p.Name
+			break
+		}
+	}
+
+	publishRoomEvent(RoomEvent{
+		Type:   RoomEventProgress,
+		RoomID: room.ID,
+		Player: &RoomPlayer{Name: name, SessionID: session.ID},
+		Progress: &PlayerProgress{
+			Name:            name,
+			QuestionsUsed:   len(session.State.Asked),
+			CandidatesCount: len(session.State.RemainingIDs),
+			Finished:        session.State.Status == StatusFinished,
+			Won:             session.State.Outcome == OutcomeWon,
+		},
+	})
+}
+
+// ---------------------------------
+// WebSocket hub
+// ---------------------------------
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPingInterval = 30 * time.Second
+	wsSendBuffer   = 16
+)
+
+type roomClient struct {
+	conn *websocket.Conn
+	send chan []byte
+}
+
+type roomHub struct {
+	mu      sync.Mutex
+	clients map[string]map[*roomClient]bool
+}
+
+var hub = &roomHub{clients: make(map[string]map[*roomClient]bool)}
+
+func (h *roomHub) add(roomID string, c *roomClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[roomID] == nil {
+		h.clients[roomID] = make(map[*roomClient]bool)
+	}
+	h.clients[roomID][c] = true
+}
+
+func (h *roomHub) remove(roomID string, c *roomClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[roomID][c]; !ok {
+		return
+	}
+	delete(h.clients[roomID], c)
+	close(c.send)
+	if len(h.clients[roomID]) == 0 {
+		delete(h.clients, roomID)
+	}
+}
+
+// broadcast queues update for every local client watching the room.
+// Clients that are too slow to keep up miss updates rather than block.
+func (h *roomHub) broadcast(roomID string, update RoomUpdate) {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients[roomID] {
+		select {
+		case c.send <- payload:
+		default:
+		}
+	}
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// handleRoomEvents upgrades to a WebSocket that receives a RoomUpdate with
+// the current status straight away and after every change.
+func handleRoomEvents(w http.ResponseWriter, r *http.Request, room *Room) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an error response.
+		return
+	}
+
+	client := &roomClient{conn: conn, send: make(chan []byte, wsSendBuffer)}
+	if initial, err := json.Marshal(RoomUpdate{Type: RoomEventStatus, Room: room.Status()}); err == nil {
+		client.send <- initial
+	}
+	hub.add(room.ID, client)
+
+	go writeRoomClient(client)
+
+	// Clients never send anything meaningful; reading just notices when
+	// they go away.
+	conn.SetReadLimit(512)
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	hub.remove(room.ID, client)
+}
+
+func writeRoomClient(c *roomClient) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, nil)
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package guesser
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ---------------------------------
+// Request / response types
+// ---------------------------------
+
+type CreateRoomRequest struct {
+	Mode RoomMode `json:"mode"`
+	// GameIDs optionally restricts the room's pool, as on session start.
+	GameIDs []int `json:"gameIds"`
+}
+
+type CreateRoomResponse struct {
+	RoomID   string   `json:"roomId"`
+	Mode     RoomMode `json:"mode"`
+	PoolSize int      `json:"poolSize"`
+}
+
+type JoinRoomRequest struct {
+	Name string `json:"name"`
+	// ClientID rates the player, for callers with an API key. Without
+	// one, nobody is rated: a cookie is too easy to throw away.
+	ClientID string `json:"clientId"`
+}
+
+type PlayerProgress struct {
+	Name            string `json:"name"`
+	QuestionsUsed   int    `json:"questionsUsed"`
+	CandidatesCount int    `json:"candidatesCount"`
+	Finished        bool   `json:"finished"`
+	Won             bool   `json:"won"`
+}
+
+// RoomStatusResponse only carries counts, never questions or answers, so
+// race opponents can't copy each other.
+type RoomStatusResponse struct {
+	RoomID  string           `json:"roomId"`
+	Mode    RoomMode         `json:"mode"`
+	Players []PlayerProgress `json:"players"`
+	// Leader is, in race rooms, the player who found the secret in the
+	// fewest questions so far (ties go to whoever found it first). It is
+	// final once every player has finished.
+	Leader string `json:"leader,omitempty"`
+}
+
+// global in-memory room store
+var rooms = newRoomStore()
+
+// ---------------------------------
+// /api/v1/room/create   (POST)
+// ---------------------------------
+
+func CreateRoomHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+
+		snap := data.Current()
+		idx := snap.Index
+
+		var req CreateRoomRequest
+		if err := decodeOptionalJSON(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidJSON, "bad json")
+			return
+		}
+
+		if req.Mode == "" {
+			req.Mode = RoomModeParty
+		}
+		if req.Mode != RoomModeParty && req.Mode != RoomModeRace {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "unknown room mode")
+			return
+		}
+
+		pool := idx.AllGameIDs
+		if req.GameIDs != nil {
+			validated, err := ValidatePool(idx, req.GameIDs)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+				return
+			}
+			pool = validated
+		}
+
+		room := rooms.create(tenantID(r), snap, req.Mode, pool)
+		publishRoomEvent(RoomEvent{
+			Type:     RoomEventCreated,
+			RoomID:   room.ID,
+			Tenant:   room.Tenant,
+			Mode:     room.Mode,
+			PoolIDs:  room.PoolIDs,
+			SecretID: room.SecretID,
+		})
+
+		writeJSON(w, http.StatusOK, CreateRoomResponse{
+			RoomID:   room.ID,
+			Mode:     room.Mode,
+			PoolSize: len(room.PoolIDs),
+		})
+	})
+}
+
+// ---------------------------------
+// /api/v1/room/{roomID}[/...]
+//   - GET  (no action)  live progress
+//   - POST /join
+//   - GET  /events      WebSocket of progress updates
+// ---------------------------------
+
+func RoomHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		room, ok := rooms.get(vars["roomID"])
+		if !ok || room.Tenant != tenantID(r) {
+			writeError(w, http.StatusNotFound, CodeUnknownRoom, "unknown room")
+			return
+		}
+
+		switch vars["action"] {
+		case "":
+			handleRoomStatus(w, r, room)
+		case "join":
+			handleJoinRoom(w, r, room, data)
+		case "events":
+			handleRoomEvents(w, r, room)
+		default:
+			apiNotFound(w, r)
+		}
+	})
+}
+
+func handleJoinRoom(
+	w http.ResponseWriter,
+	r *http.Request,
+	room *Room,
+	data *SnapshotHolder,
+) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+
+	var req JoinRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "bad json")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "name is required")
+		return
+	}
+
+	client := ratedClient(r, req.ClientID)
+	session, err := room.join(req.Name, client, store, data.Current())
+	if errors.Is(err, ErrRoomPoolExhausted) {
+		writeError(w, http.StatusConflict, CodeRoomFull, err.Error())
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	publishRoomEvent(RoomEvent{
+		Type:     RoomEventJoined,
+		RoomID:   room.ID,
+		Player:   &RoomPlayer{Name: req.Name, SessionID: session.ID, Client: client},
+		SecretID: session.State.SecretID,
+	})
+
+	resp := newStartResponse(session.Snapshot, session.State, defaultDatasetID)
+	resp.SessionID = session.ID
+	resp.ClientToken = session.Token
+
+	writeResponse(w, r, http.StatusOK, resp)
+}
+
+// handleRoomStatus reports each player's progress.
+func handleRoomStatus(w http.ResponseWriter, r *http.Request, room *Room) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, room.Status())
+}
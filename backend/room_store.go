@@ -0,0 +1,326 @@
+package guesser
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// roomIDBytes makes room IDs long enough that they can't be guessed
+	// or collide.
+	roomIDBytes = 8
+	// maxRooms bounds the store; past it, idle rooms are dropped, then
+	// the least recently used half.
+	maxRooms = 10_000
+)
+
+// RoomMode selects how secrets are shared between the players of a room.
+type RoomMode string
+
+const (
+	// RoomModeParty gives every player a different secret from the pool.
+	RoomModeParty RoomMode = "party"
+	// RoomModeRace gives every player the same secret; whoever names it in
+	// the fewest questions wins.
+	RoomModeRace RoomMode = "race"
+)
+
+// ErrRoomPoolExhausted is returned when a party room has handed out every
+// game in its pool as somebody's secret.
+var ErrRoomPoolExhausted = errors.New("no unused secrets left in this room's pool")
+
+// RoomPlayer links a display name to the session the player is using.
+// Client identifies the player across rooms, for their rating.
+type RoomPlayer struct {
+	Name      string `json:"name"`
+	SessionID string `json:"sessionId"`
+	Client    string `json:"client,omitempty"`
+}
+
+// Room groups several player sessions that draw from the same pool.
+type Room struct {
+	ID      string
+	Mode    RoomMode
+	PoolIDs []int
+	Tenant  string
+	// SecretID is the game every player of a race room has to find.
+	SecretID int
+
+	// snapshot is the dataset version the room's sessions play against.
+	// Rooms mirrored from another instance pin one on their first local
+	// join.
+	snapshot *Snapshot
+
+	mu          sync.Mutex
+	players     []RoomPlayer
+	usedSecrets map[int]bool
+	// progress is keyed by session ID. It is kept here rather than read
+	// from the session store because in cluster mode the session may live
+	// on another instance.
+	progress map[string]PlayerProgress
+	// solved lists the session IDs that found the secret, in the order
+	// their progress arrived here; it breaks ties in a race.
+	solved []string
+	// rated is set once a duel's result has moved the players' ratings.
+	rated bool
+
+	// lastActive is unix nanoseconds, read by the sweeper without r.mu.
+	lastActive atomic.Int64
+}
+
+func (r *Room) touch(now time.Time) {
+	r.lastActive.Store(now.UnixNano())
+}
+
+func (r *Room) LastActive() time.Time {
+	return time.Unix(0, r.lastActive.Load())
+}
+
+// pickSecret chooses a secret for a new player. Party rooms never hand the
+// same game to two players; race rooms hand everyone the same one.
+func (r *Room) pickSecret(idx GameIndex) (int, error) {
+	if r.Mode == RoomModeRace {
+		return r.SecretID, nil
+	}
+
+	free := make([]int, 0, len(r.PoolIDs))
+	for _, id := range r.PoolIDs {
+		if !r.usedSecrets[id] {
+			free = append(free, id)
+		}
+	}
+
+	if len(free) == 0 {
+		return 0, ErrRoomPoolExhausted
+	}
+
+	secretID := RandomSecret(secretPicker, idx, free)
+	r.usedSecrets[secretID] = true
+	return secretID, nil
+}
+
+// join creates a session for name, playing as client, inside the room.
+// latest is used only if the room has not pinned a snapshot yet.
+func (r *Room) join(name, client string, sessions *sessionStore, latest *Snapshot) (*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.snapshot == nil {
+		r.snapshot = latest
+	}
+
+	secretID, err := r.pickSecret(r.snapshot.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	session := sessions.create(r.Tenant, r.snapshot, NewSessionStateFromPool(r.PoolIDs, secretID))
+	session.RoomID = r.ID
+	r.addPlayer(RoomPlayer{Name: name, SessionID: session.ID, Client: client})
+	return session, nil
+}
+
+// addPlayer records a player; r.mu must be held. Replaying a player that
+// is already known is a no-op.
+func (r *Room) addPlayer(p RoomPlayer) {
+	if _, ok := r.progress[p.SessionID]; ok {
+		return
+	}
+	r.players = append(r.players, p)
+	r.progress[p.SessionID] = PlayerProgress{Name: p.Name, CandidatesCount: len(r.PoolIDs)}
+}
+
+// recordPlayer mirrors a player who joined on another instance, so this
+// instance never hands out the same secret.
+func (r *Room) recordPlayer(p RoomPlayer, secretID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.usedSecrets[secretID] = true
+	r.addPlayer(p)
+}
+
+// updateProgress replaces a known player's progress.
+func (r *Room) updateProgress(sessionID string, p PlayerProgress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.progress[sessionID]
+	if !ok {
+		return
+	}
+	if p.Won && !old.Won {
+		r.solved = append(r.solved, sessionID)
+	}
+	r.progress[sessionID] = p
+	r.rateDuelLocked()
+}
+
+// rateDuelLocked rates a race between two players once both have
+// finished; r.mu must be held.
+func (r *Room) rateDuelLocked() {
+	if r.Mode != RoomModeRace || len(r.players) != 2 || r.rated {
+		return
+	}
+	a, b := r.players[0], r.players[1]
+	pa, pb := r.progress[a.SessionID], r.progress[b.SessionID]
+	if !pa.Finished || !pb.Finished {
+		return
+	}
+	r.rated = true
+	ratings.recordDuel(r.Tenant, a, b, pa, pb)
+}
+
+// leader returns the name of the race player who found the secret in the
+// fewest questions so far, or "" if nobody has; r.mu must be held.
+func (r *Room) leader() string {
+	best := ""
+	for _, id := range r.solved {
+		if best == "" || r.progress[id].QuestionsUsed < r.progress[best].QuestionsUsed {
+			best = id
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return r.progress[best].Name
+}
+
+// Status reports each player's progress in join order. It never exposes
+// session IDs or secrets, so it is safe to show to everyone in the room.
+func (r *Room) Status() RoomStatusResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	progress := make([]PlayerProgress, 0, len(r.players))
+	for _, p := range r.players {
+		progress = append(progress, r.progress[p.SessionID])
+	}
+
+	resp := RoomStatusResponse{
+		RoomID:  r.ID,
+		Mode:    r.Mode,
+		Players: progress,
+	}
+	if r.Mode == RoomModeRace {
+		resp.Leader = r.leader()
+	}
+	return resp
+}
+
+// Players returns a snapshot of the room's players in join order.
+func (r *Room) Players() []RoomPlayer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	players := make([]RoomPlayer, len(r.players))
+	copy(players, r.players)
+	return players
+}
+
+type roomStore struct {
+	mu    sync.RWMutex
+	rooms map[string]*Room
+}
+
+func newRoomStore() *roomStore {
+	return &roomStore{
+		rooms: make(map[string]*Room),
+	}
+}
+
+func newRoom(id, tenant string, mode RoomMode, pool []int, secretID int) *Room {
+	room := &Room{
+		ID:          id,
+		Mode:        mode,
+		PoolIDs:     pool,
+		Tenant:      tenant,
+		SecretID:    secretID,
+		usedSecrets: make(map[int]bool),
+		progress:    make(map[string]PlayerProgress),
+	}
+	room.touch(time.Now())
+	return room
+}
+
+// create opens a room; race rooms draw their shared secret here.
+func (s *roomStore) create(tenant string, snap *Snapshot, mode RoomMode, pool []int) *Room {
+	secretID := 0
+	if mode == RoomModeRace {
+		secretID = RandomSecret(secretPicker, snap.Index, pool)
+	}
+	room := newRoom("", tenant, mode, pool, secretID)
+	room.snapshot = snap
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rooms) >= maxRooms {
+		s.forgetLocked()
+	}
+	for {
+		room.ID = randomToken(roomIDBytes)
+		if _, taken := s.rooms[room.ID]; !taken {
+			s.rooms[room.ID] = room
+			return room
+		}
+	}
+}
+
+// mirror registers a room created on another instance, unless it is
+// already known here.
+func (s *roomStore) mirror(id, tenant string, mode RoomMode, pool []int, secretID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rooms[id]; !ok {
+		if len(s.rooms) >= maxRooms {
+			s.forgetLocked()
+		}
+		s.rooms[id] = newRoom(id, tenant, mode, pool, secretID)
+	}
+}
+
+// get returns the room and counts the lookup as activity.
+func (s *roomStore) get(id string) (*Room, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	room, ok := s.rooms[id]
+	if ok {
+		room.touch(time.Now())
+	}
+	return room, ok
+}
+
+// sweep drops rooms idle for longer than ttl, and with them the dataset
+// snapshot they pin.
+func (s *roomStore) sweep(now time.Time, ttl time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evicted := 0
+	for id, room := range s.rooms {
+		if now.Sub(room.LastActive()) > ttl {
+			delete(s.rooms, id)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// forgetLocked makes room for a new room by dropping the least recently
+// used half; s.mu must be held.
+func (s *roomStore) forgetLocked() {
+	ids := make([]string, 0, len(s.rooms))
+	for id := range s.rooms {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return s.rooms[ids[i]].LastActive().Before(s.rooms[ids[j]].LastActive()) })
+	for _, id := range ids[:len(ids)/2] {
+		delete(s.rooms, id)
+	}
+}
@@ -0,0 +1,28 @@
+package guesser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoomStoreSweep(t *testing.T) {
+	s := newRoomStore()
+	snap := &Snapshot{Index: testIndex()}
+	idle := s.create("", snap, RoomModeParty, []int{1, 2})
+	live := s.create("", snap, RoomModeParty, []int{1, 2})
+	if len(idle.ID) != 2*roomIDBytes || idle.ID == live.ID {
+		t.Fatalf("room IDs %q and %q", idle.ID, live.ID)
+	}
+
+	now := time.Now()
+	idle.touch(now.Add(-2 * time.Hour))
+	if n := s.sweep(now, time.Hour); n != 1 {
+		t.Errorf("sweep evicted %d rooms, want 1", n)
+	}
+	if _, ok := s.get(idle.ID); ok {
+		t.Error("idle room still there")
+	}
+	if _, ok := s.get(live.ID); !ok {
+		t.Error("active room evicted")
+	}
+}
@@ -0,0 +1,96 @@
+package guesser
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// apiPrefix is the root of the current API version.
+const apiPrefix = "/api/v1"
+
+// RegisterAPIRoutes mounts every endpoint under /api/v1, plus the session
+// WebSocket, on router. New sessions and rooms use data's current
+// snapshot; existing ones keep the snapshot they started with. branding
+// is what /api/v1/branding serves.
+//
+// The same routes stay reachable without the version (/api/session/...)
+// for clients written before /api/v1; those answers carry a Deprecation
+// header.
+func RegisterAPIRoutes(router *mux.Router, data *SnapshotHolder, branding map[string]string) {
+	registerAPIv1(router.PathPrefix(apiPrefix).Subrouter(), data, branding)
+	router.Handle("/ws/session/{sessionID}", SessionSocketHandler())
+
+	legacy := router.PathPrefix("/api").Subrouter()
+	legacy.Use(deprecatedRouteMiddleware)
+	registerAPIv1(legacy, data, branding)
+
+	// Keep unknown API paths from falling through to the frontend.
+	router.PathPrefix("/api/").HandlerFunc(apiNotFound)
+}
+
+func registerAPIv1(api *mux.Router, data *SnapshotHolder, branding map[string]string) {
+	api.Handle("/openapi.json", OpenAPIHandler())
+	api.Handle("/docs", SwaggerUIHandler())
+	api.Handle("/branding", BrandingHandler(branding))
+	api.Handle("/datasets", DatasetsHandler(data))
+	api.Handle("/dataset", DatasetMetaHandler(data))
+
+	api.Handle("/session/start", StartSessionHandler(data))
+	api.Handle("/session/{sessionID}", SessionHandler())
+	api.Handle("/session/{sessionID}/{action}", SessionHandler())
+	api.Handle("/daily/start", DefaultDatasetOnly(DailyStartHandler(data)))
+	api.Handle("/steam/start", DefaultDatasetOnly(SteamStartHandler(data)))
+
+	api.Handle("/stateless/start", StatelessStartHandler(data))
+	api.Handle("/stateless/session", StatelessHandler(data))
+	api.Handle("/stateless/session/{action}", StatelessHandler(data))
+
+	api.Handle("/recap/{token}/card", RecapHandler())
+	api.Handle("/stats", StatsHandler(data))
+	api.Handle("/ladder", LadderHandler())
+
+	api.Handle("/games", DefaultDatasetOnly(CatalogHandler(data)))
+	api.Handle("/games/suggest", DefaultDatasetOnly(SuggestHandler(data)))
+	api.Handle("/games/{gameID:[0-9]+}", DefaultDatasetOnly(GamesHandler(data)))
+	api.Handle("/games/{gameID:[0-9]+}/{action}", DefaultDatasetOnly(GamesHandler(data)))
+	api.Handle("/images/{gameID:[0-9]+}", ImageHandler(data))
+
+	api.Handle("/admin/webhooks/deliveries", WebhookDeliveriesHandler())
+	api.Handle("/admin/api-keys", APIKeyUsageHandler())
+	api.Handle("/admin/datasets/reload", ReloadDatasetsHandler())
+	api.Handle("/admin/templates", TemplateReportHandler(data))
+
+	api.Handle("/room/create", DefaultDatasetOnly(CreateRoomHandler(data)))
+	api.Handle("/room/{roomID}", DefaultDatasetOnly(RoomHandler(data)))
+	api.Handle("/room/{roomID}/{action}", DefaultDatasetOnly(RoomHandler(data)))
+}
+
+// deprecatedRouteMiddleware marks answers on unversioned /api paths and
+// points at their /api/v1 replacement.
+func deprecatedRouteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := apiRoute(r.URL.Path); ok {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", "<"+apiPrefix+"/"+route+`>; rel="successor-version"`)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiRoute returns path below the API root, versioned or not and behind
+// any tenant prefix: "/t/retro/api/v1/session/abc/ask" gives
+// "session/abc/ask". Middlewares run before tenant routers have matched,
+// so they classify requests with this rather than with mux's route.
+func apiRoute(path string) (string, bool) {
+	i := strings.Index(path, "/api/")
+	if i < 0 {
+		return "", false
+	}
+	route := path[i+len("/api/"):]
+	if route == "v1" {
+		return "", true
+	}
+	return strings.TrimPrefix(route, "v1/"), true
+}
@@ -0,0 +1,73 @@
+package guesser
+
+// Rules holds the gameplay limits a deployment can tune. Handlers read the
+// package-level value, which main sets once at startup.
+type Rules struct {
+	// CandidateRevealThreshold is the largest candidate pool whose names
+	// may be listed to the player. 0 never reveals candidates.
+	CandidateRevealThreshold int
+
+	// GuessSimilarityThreshold is how close (0-1) a typed guess must be
+	// to the secret's name or one of its aliases to count as correct.
+	// 1 still ignores case and punctuation but allows no typos.
+	GuessSimilarityThreshold float64
+
+	// MaxQuestions caps how many questions a player may ask before they
+	// must guess. 0 is unlimited.
+	MaxQuestions int
+
+	// MaxGuesses is how many guesses a classic player gets; the secret is
+	// revealed when they are used up. 0 is unlimited.
+	MaxGuesses int
+
+	// EasySecrets is how many of the pool's most popular games an easy
+	// session draws its secret from.
+	EasySecrets int
+
+	// RecentSecrets is how many of a client's last secrets new sessions
+	// avoid. 0 doesn't track them.
+	RecentSecrets int
+
+	// HintCost is what each hint takes off the score of a win.
+	HintCost int
+}
+
+// DefaultRules returns the limits used when nothing is configured.
+func DefaultRules() Rules {
+	return Rules{
+		CandidateRevealThreshold: 10,
+		GuessSimilarityThreshold: 0.85,
+		MaxQuestions:             20,
+		MaxGuesses:               3,
+		EasySecrets:              100,
+		RecentSecrets:            10,
+		HintCost:                 150,
+	}
+}
+
+var rules = DefaultRules()
+
+// SetRules replaces the active gameplay rules. Call it before serving.
+func SetRules(r Rules) {
+	rules = r
+}
+
+// questionsRemaining is how many more questions the player may ask, or
+// nil when there is no limit.
+func questionsRemaining(state SessionState) *int {
+	if rules.MaxQuestions == 0 {
+		return nil
+	}
+	n := max(rules.MaxQuestions-len(state.Asked), 0)
+	return &n
+}
+
+// guessesRemaining is how many more guesses the player may make, or nil
+// when there is no limit.
+func guessesRemaining(state SessionState) *int {
+	if rules.MaxGuesses == 0 {
+		return nil
+	}
+	n := max(rules.MaxGuesses-len(state.Guesses), 0)
+	return &n
+}
@@ -0,0 +1,53 @@
+package guesser
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// SecretPicker is the randomness secrets are drawn with. *rand.Rand
+// satisfies it, so a rand.New(rand.NewSource(seed)) reproduces the exact
+// secrets of a test, a daily challenge or a replay.
+type SecretPicker interface {
+	Intn(n int) int
+	Float64() float64
+}
+
+// globalPicker draws from math/rand's shared source.
+type globalPicker struct{}
+
+func (globalPicker) Intn(n int) int   { return rand.Intn(n) }
+func (globalPicker) Float64() float64 { return rand.Float64() }
+
+// lockedPicker makes a picker safe for concurrent handlers.
+type lockedPicker struct {
+	mu sync.Mutex
+	p  SecretPicker
+}
+
+func (l *lockedPicker) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.p.Intn(n)
+}
+
+func (l *lockedPicker) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.p.Float64()
+}
+
+// secretPicker draws the secrets of sessions and rooms players start.
+var secretPicker SecretPicker = globalPicker{}
+
+// SetSecretPicker replaces the random source for new sessions and rooms,
+// e.g. with a seeded one for a reproducible demo. Call it before serving.
+func SetSecretPicker(p SecretPicker) {
+	secretPicker = &lockedPicker{p: p}
+}
+
+// SeededPicker returns a picker that draws the same sequence for the same
+// seed. It is not safe for concurrent use.
+func SeededPicker(seed int64) SecretPicker {
+	return rand.New(rand.NewSource(seed))
+}
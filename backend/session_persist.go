@@ -0,0 +1,311 @@
+package guesser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// On shutdown the in-memory sessions, daily results and streaks,
+// challenge codes, game stats and ratings are written to a file and read
+// back on the next start, so a deploy doesn't wipe everyone's game. Restored
+// sessions play against the dataset's current snapshot. Rooms are not
+// saved: room sessions come back, but without their room.
+
+const sessionsFileVersion = 1
+
+type sessionsFile struct {
+	Version    int                  `json:"version"`
+	SavedAt    time.Time            `json:"savedAt"`
+	Sessions   []persistedSession   `json:"sessions"`
+	Daily      []persistedDaily     `json:"daily"`
+	Challenges []persistedChallenge `json:"challenges"`
+	Stats      []persistedStats     `json:"stats,omitempty"`
+	Streaks    []persistedStreak    `json:"streaks,omitempty"`
+	Ratings    []persistedRating    `json:"ratings,omitempty"`
+}
+
+type persistedSession struct {
+	ID            string       `json:"id"`
+	Token         string       `json:"token"`
+	Tenant        string       `json:"tenant,omitempty"`
+	DatasetID     string       `json:"datasetId,omitempty"`
+	RoomID        string       `json:"roomId,omitempty"`
+	State         SessionState `json:"state"`
+	Pool          []int        `json:"pool"`
+	Daily         *dailyKey    `json:"daily,omitempty"`
+	ChallengeCode string       `json:"challengeCode,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	LastActive    time.Time    `json:"lastActive"`
+}
+
+type persistedDaily struct {
+	Key    dailyKey    `json:"key"`
+	Result DailyResult `json:"result"`
+}
+
+type persistedStreak struct {
+	Key    streakKey   `json:"key"`
+	Streak DailyStreak `json:"streak"`
+	// Previous is the run before the current one.
+	Previous *dailyRun `json:"previous,omitempty"`
+}
+
+type persistedRating struct {
+	Key    ratingKey    `json:"key"`
+	Rating PlayerRating `json:"rating"`
+}
+
+type persistedStats struct {
+	Key   statsKey      `json:"key"`
+	Stats *datasetStats `json:"stats"`
+}
+
+type persistedChallenge struct {
+	Code       string            `json:"code"`
+	Tenant     string            `json:"tenant,omitempty"`
+	DatasetID  string            `json:"datasetId,omitempty"`
+	Version    uint64            `json:"version"`
+	Pool       []int             `json:"pool"`
+	SecretID   int               `json:"secretId"`
+	Mode       SessionMode       `json:"mode"`
+	Difficulty SessionDifficulty `json:"difficulty,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+}
+
+// SaveSessions writes every live session, daily result and challenge to
+// path, replacing it atomically. Call it once requests have drained.
+func SaveSessions(path string) (int, error) {
+	file := sessionsFile{Version: sessionsFileVersion, SavedAt: time.Now()}
+
+	store.mu.RLock()
+	live := make([]*Session, 0, len(store.sessions))
+	for _, s := range store.sessions {
+		if store.ttl <= 0 || time.Since(s.LastActive()) <= store.ttl {
+			live = append(live, s)
+		}
+	}
+	store.mu.RUnlock()
+
+	for _, s := range live {
+		s.mu.Lock()
+		p := persistedSession{
+			ID:            s.ID,
+			Token:         s.Token,
+			Tenant:        s.Tenant,
+			DatasetID:     s.DatasetID,
+			RoomID:        s.RoomID,
+			State:         s.State,
+			Pool:          s.Pool,
+			ChallengeCode: s.challengeCode,
+			CreatedAt:     s.CreatedAt,
+			LastActive:    s.LastActive(),
+		}
+		if s.daily != (dailyKey{}) {
+			key := s.daily
+			p.Daily = &key
+		}
+		s.mu.Unlock()
+		file.Sessions = append(file.Sessions, p)
+	}
+
+	daily.mu.Lock()
+	for key, result := range daily.results {
+		file.Daily = append(file.Daily, persistedDaily{Key: key, Result: result})
+	}
+	for key, rec := range daily.streaks {
+		p := persistedStreak{Key: key, Streak: rec.streak()}
+		if rec.previous.Days > 0 {
+			prev := rec.previous
+			p.Previous = &prev
+		}
+		file.Streaks = append(file.Streaks, p)
+	}
+	daily.mu.Unlock()
+
+	challenges.mu.RLock()
+	for code, c := range challenges.challenges {
+		file.Challenges = append(file.Challenges, persistedChallenge{
+			Code:       code,
+			Tenant:     c.Tenant,
+			DatasetID:  c.DatasetID,
+			Version:    c.Version,
+			Pool:       c.Pool,
+			SecretID:   c.SecretID,
+			Mode:       c.Mode,
+			Difficulty: c.Difficulty,
+			CreatedAt:  c.CreatedAt,
+		})
+	}
+	challenges.mu.RUnlock()
+
+	ratings.mu.Lock()
+	for key, p := range ratings.players {
+		file.Ratings = append(file.Ratings, persistedRating{Key: key, Rating: *p})
+	}
+	ratings.mu.Unlock()
+
+	stats.mu.Lock()
+	for key, ds := range stats.datasets {
+		file.Stats = append(file.Stats, persistedStats{Key: key, Stats: ds})
+	}
+	data, err := json.Marshal(file)
+	stats.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	// Sessions hold tokens and secrets: keep the file private, and never
+	// leave a half-written one behind.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return len(file.Sessions), nil
+}
+
+// RestoreSessions loads what SaveSessions wrote to path, then removes the
+// file so a later crash can't bring back stale games. A missing file
+// restores nothing. Sessions whose dataset is gone, or no longer has
+// their games, are dropped. Call it once every dataset has a snapshot.
+func RestoreSessions(path string) (restored, dropped int, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var file sessionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return 0, 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if file.Version != sessionsFileVersion {
+		return 0, 0, fmt.Errorf("%s: unsupported version %d", path, file.Version)
+	}
+
+	for _, p := range file.Sessions {
+		snap, ok := datasets.snapshotFor(p.Tenant, p.DatasetID)
+		if !ok || !hasGames(snap.Index, p.Pool) {
+			dropped++
+			continue
+		}
+
+		session := &Session{
+			ID:            p.ID,
+			State:         p.State,
+			Token:         p.Token,
+			Tenant:        p.Tenant,
+			Snapshot:      snap,
+			Pool:          p.Pool,
+			DatasetID:     p.DatasetID,
+			RoomID:        p.RoomID,
+			challengeCode: p.ChallengeCode,
+			CreatedAt:     p.CreatedAt,
+		}
+		session.touch(p.LastActive)
+		if p.Daily != nil {
+			session.daily = *p.Daily
+		}
+
+		store.mu.Lock()
+		store.sessions[session.ID] = session
+		store.mu.Unlock()
+
+		if session.daily != (dailyKey{}) && session.State.Status != StatusFinished {
+			daily.mu.Lock()
+			daily.sessions[session.daily] = session.ID
+			daily.mu.Unlock()
+		}
+		restored++
+	}
+
+	daily.mu.Lock()
+	for _, d := range file.Daily {
+		daily.results[d.Key] = d.Result
+	}
+	for _, s := range file.Streaks {
+		rec := streakRecord{
+			current: dailyRun{Days: s.Streak.Current, Last: s.Streak.LastDate},
+			best:    s.Streak.Best,
+		}
+		if s.Previous != nil {
+			rec.previous = *s.Previous
+		}
+		daily.streaks[s.Key] = rec
+	}
+	daily.pruneLocked(time.Now().UTC().Format(dailyDateLayout))
+	daily.mu.Unlock()
+
+	for _, c := range file.Challenges {
+		if time.Since(c.CreatedAt) > challengeTTL {
+			continue
+		}
+		snap, ok := datasets.snapshotFor(c.Tenant, c.DatasetID)
+		if !ok || !hasGames(snap.Index, c.Pool) {
+			continue
+		}
+		challenges.mu.Lock()
+		challenges.challenges[c.Code] = Challenge{
+			Tenant:     c.Tenant,
+			DatasetID:  c.DatasetID,
+			Version:    c.Version,
+			Pool:       c.Pool,
+			SecretID:   c.SecretID,
+			Mode:       c.Mode,
+			Difficulty: c.Difficulty,
+			CreatedAt:  c.CreatedAt,
+		}
+		challenges.mu.Unlock()
+	}
+
+	stats.mu.Lock()
+	for _, s := range file.Stats {
+		if s.Stats.Secrets == nil {
+			s.Stats.Secrets = map[int]*gameStats{}
+		}
+		if s.Stats.Templates == nil {
+			s.Stats.Templates = map[string]int{}
+		}
+		stats.datasets[s.Key] = s.Stats
+	}
+	stats.mu.Unlock()
+
+	ratings.mu.Lock()
+	for _, p := range file.Ratings {
+		rating := p.Rating
+		ratings.players[p.Key] = &rating
+	}
+	ratings.mu.Unlock()
+
+	if err := os.Remove(path); err != nil {
+		return restored, dropped, err
+	}
+	return restored, dropped, nil
+}
+
+// hasGames reports whether every id is still in idx.
+func hasGames(idx GameIndex, ids []int) bool {
+	for _, id := range ids {
+		if _, ok := idx.Games[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
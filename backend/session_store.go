@@ -2,32 +2,109 @@ package guesser
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
+	"errors"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrUnknownSession = errors.New("unknown session")
+	// ErrSessionExpired is returned for sessions evicted after sitting
+	// idle for longer than the TTL.
+	ErrSessionExpired = errors.New("session expired")
 )
 
 // Session wraps a SessionState with an ID used by the frontend.
 type Session struct {
 	ID    string
 	State SessionState
+
+	// Token is a per-session secret handed only to the player who started
+	// the game. The ID may end up in URLs and logs; the token must not.
+	Token string
+
+	// Tenant is the catalog the session belongs to ("" for the default).
+	// Session IDs are only valid under the tenant that created them.
+	Tenant string
+
+	// Snapshot is the dataset version the session was started against.
+	Snapshot *Snapshot
+
+	// Pool is the candidate list the session started with.
+	Pool []int
+
+	// DatasetID is the dataset the session was started on ("" for the
+	// default).
+	DatasetID string
+
+	// RoomID is set when the session was started by joining a room.
+	RoomID string
+
+	// daily is set when the session is a daily challenge attempt.
+	daily dailyKey
+
+	// challengeCode is the seed code shared for this session, once asked
+	// for.
+	challengeCode string
+
+	CreatedAt time.Time
+
+	// lastActive is unix nanoseconds, read by the cleanup goroutine
+	// without holding mu.
+	lastActive atomic.Int64
+
+	// mu serializes requests against the same session.
+	mu sync.Mutex
+}
+
+// LastActive is when the session last served a request.
+func (s *Session) LastActive() time.Time {
+	return time.Unix(0, s.lastActive.Load())
+}
+
+func (s *Session) touch(now time.Time) {
+	s.lastActive.Store(now.UnixNano())
+}
+
+// Authorized reports whether token matches the session's client token.
+func (s *Session) Authorized(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
 }
 
 type sessionStore struct {
 	mu       sync.RWMutex
 	sessions map[string]*Session
+	// expired remembers evicted IDs until the given time, so clients get
+	// ErrSessionExpired rather than ErrUnknownSession.
+	expired map[string]time.Time
+	// ttl is the idle time after which a session expires; 0 keeps
+	// sessions forever.
+	ttl time.Duration
 }
 
 func newSessionStore() *sessionStore {
 	return &sessionStore{
 		sessions: make(map[string]*Session),
+		expired:  make(map[string]time.Time),
 	}
 }
 
-func (s *sessionStore) create(initial SessionState) *Session {
+func (s *sessionStore) create(tenant string, snap *Snapshot, initial SessionState) *Session {
 	session := &Session{
-		ID:    randomSessionID(),
-		State: initial,
+		ID:       randomSessionID(),
+		State:    initial,
+		Token:    randomToken(32),
+		Tenant:   tenant,
+		Snapshot: snap,
+		Pool:     append([]int(nil), initial.RemainingIDs...),
+
+		CreatedAt: time.Now(),
 	}
+	session.touch(session.CreatedAt)
 
 	s.mu.Lock()
 	s.sessions[session.ID] = session
@@ -36,16 +113,94 @@ func (s *sessionStore) create(initial SessionState) *Session {
 	return session
 }
 
-func (s *sessionStore) get(id string) (*Session, bool) {
+// get returns the session, or ErrUnknownSession / ErrSessionExpired. A
+// session past its TTL counts as expired even before the sweeper has
+// evicted it.
+func (s *sessionStore) get(id string) (*Session, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	session, ok := s.sessions[id]
-	return session, ok
+	if !ok {
+		if _, gone := s.expired[id]; gone {
+			return nil, ErrSessionExpired
+		}
+		return nil, ErrUnknownSession
+	}
+
+	if s.ttl > 0 && time.Since(session.LastActive()) > s.ttl {
+		return nil, ErrSessionExpired
+	}
+	return session, nil
+}
+
+// sweep evicts sessions idle for longer than the TTL and forgets
+// tombstones older than another TTL.
+func (s *sessionStore) sweep(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ttl <= 0 {
+		return 0
+	}
+
+	evicted := 0
+	for id, session := range s.sessions {
+		if now.Sub(session.LastActive()) > s.ttl {
+			delete(s.sessions, id)
+			s.expired[id] = now.Add(s.ttl)
+			evicted++
+		}
+	}
+	for id, until := range s.expired {
+		if now.After(until) {
+			delete(s.expired, id)
+		}
+	}
+	return evicted
+}
+
+// ConfigureSessionTTL expires sessions, and rooms, idle for longer than
+// ttl and starts the goroutine that evicts them. A ttl of 0 disables
+// expiry.
+func ConfigureSessionTTL(ttl time.Duration) {
+	store.mu.Lock()
+	store.ttl = ttl
+	store.mu.Unlock()
+
+	if ttl <= 0 {
+		return
+	}
+
+	// Sweep often enough that memory tracks the TTL, without spinning
+	// for very short or very long TTLs.
+	interval := ttl / 10
+	if interval < 10*time.Second {
+		interval = 10 * time.Second
+	} else if interval > 5*time.Minute {
+		interval = 5 * time.Minute
+	}
+
+	go func() {
+		for now := range time.Tick(interval) {
+			if n := store.sweep(now); n > 0 {
+				slog.Info("sessions: evicted idle sessions", "count", n)
+			}
+			// A room outlives its players' sessions by at most a TTL.
+			if n := rooms.sweep(now, ttl); n > 0 {
+				slog.Info("rooms: evicted idle rooms", "count", n)
+			}
+		}
+	}()
 }
 
 func randomSessionID() string {
-	buf := make([]byte, 16)
+	return randomToken(16)
+}
+
+// randomToken returns n random bytes, hex-encoded.
+func randomToken(n int) string {
+	buf := make([]byte, n)
 	_, _ = rand.Read(buf)
 	return hex.EncodeToString(buf)
 }
@@ -0,0 +1,269 @@
+package guesser
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// A session WebSocket carries the same actions as /api/v1/session/{id}/...,
+// run through the same handlers, plus pushes the client would otherwise
+// poll for: a timer tick every second while the game is running and, for
+// room sessions, the room's RoomUpdates (opponents' progress).
+
+// SessionSocketRequest is one client message, e.g.
+//
+//	{"id": "7", "action": "ask", "body": {"questionTypeId": "main_genre", "option": "RPG"}}
+type SessionSocketRequest struct {
+	// ID is echoed in the reply so clients can match them up.
+	ID     string          `json:"id,omitempty"`
+	Action string          `json:"action"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// SessionSocketMessage is a server message. Type is "result" or "error"
+// in reply to a request, or "timer"; room pushes are sent as RoomUpdate.
+type SessionSocketMessage struct {
+	Type   string          `json:"type"`
+	ID     string          `json:"id,omitempty"`
+	Action string          `json:"action,omitempty"`
+	Status int             `json:"status,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	Error  *APIError       `json:"error,omitempty"`
+	Timer  *SessionTimer   `json:"timer,omitempty"`
+}
+
+type SessionTimer struct {
+	ElapsedSeconds int           `json:"elapsedSeconds"`
+	Status         SessionStatus `json:"status"`
+}
+
+// sessionSocketActions maps socket actions to the HTTP method their
+// handler expects. "state" is the bare GET /api/v1/session/{id}.
+var sessionSocketActions = map[string]string{
+	"state":            http.MethodGet,
+	"ask":              http.MethodPost,
+	"guess":            http.MethodPost,
+	"giveup":           http.MethodPost,
+	"challenge":        http.MethodPost,
+	"next-question":    http.MethodPost,
+	"answer":           http.MethodPost,
+	"candidates":       http.MethodGet,
+	"questions":        http.MethodGet,
+	"suggest-question": http.MethodGet,
+	"timeline":         http.MethodGet,
+	"hint":             http.MethodPost,
+	"share":            http.MethodGet,
+}
+
+// capturedResponse collects what a handler writes, so socket requests
+// can reuse the HTTP handlers.
+type capturedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (c *capturedResponse) Header() http.Header { return c.header }
+
+func (c *capturedResponse) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+	}
+}
+
+func (c *capturedResponse) Write(b []byte) (int, error) {
+	c.WriteHeader(http.StatusOK)
+	return c.body.Write(b)
+}
+
+// apiError reads the error envelope a handler wrote.
+func (c *capturedResponse) apiError() *APIError {
+	var resp ErrorResponse
+	if err := json.Unmarshal(c.body.Bytes(), &resp); err != nil || resp.Error.Code == "" {
+		return &APIError{CodeInternal, strings.TrimSpace(c.body.String())}
+	}
+	return &resp.Error
+}
+
+// runSocketAction performs one request against session as if it had come
+// in over HTTP.
+func runSocketAction(r *http.Request, session *Session, req SessionSocketRequest) SessionSocketMessage {
+	msg := SessionSocketMessage{ID: req.ID, Action: req.Action}
+
+	method, ok := sessionSocketActions[req.Action]
+	if !ok {
+		msg.Type, msg.Status, msg.Error = "error", http.StatusNotFound, &APIError{CodeNotFound, "unknown action"}
+		return msg
+	}
+
+	action := req.Action
+	if action == "state" {
+		action = ""
+	}
+	inner, err := http.NewRequestWithContext(r.Context(), method,
+		apiPrefix+"/session/"+session.ID+"/"+action, bytes.NewReader(req.Body))
+	if err != nil {
+		msg.Type, msg.Status, msg.Error = "error", http.StatusBadRequest, &APIError{CodeInvalidRequest, "bad request"}
+		return msg
+	}
+
+	resp := &capturedResponse{header: http.Header{}}
+	if _, keyed := apiKeyFromContext(r.Context()); playActions[req.Action] && !keyed && !allowIP(resp, r, "play") {
+		msg.Type, msg.Status, msg.Error = "error", resp.status, resp.apiError()
+		return msg
+	}
+
+	session.mu.Lock()
+	session.touch(time.Now())
+	serveSessionAction(resp, inner, session, action)
+	session.mu.Unlock()
+
+	msg.Status = resp.status
+	if resp.status >= http.StatusBadRequest {
+		msg.Type, msg.Error = "error", resp.apiError()
+		return msg
+	}
+	msg.Type, msg.Data = "result", resp.body.Bytes()
+	return msg
+}
+
+func sessionTimer(session *Session) SessionTimer {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	state := session.State
+	end := time.Now()
+	if state.Status == StatusFinished {
+		end = state.FinishedAt
+	}
+	return SessionTimer{
+		ElapsedSeconds: int(end.Sub(state.StartedAt).Seconds()),
+		Status:         state.Status,
+	}
+}
+
+// ---------------------------------
+// /ws/session/{sessionID}?token=...   (GET, WebSocket)
+// ---------------------------------
+
+// SessionSocketHandler takes the session token from ?token= as well as
+// the usual header, since browsers can't set headers on WebSockets.
+func SessionSocketHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		token := r.Header.Get(sessionTokenHeader)
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		session, ok := authorizeSession(w, r, mux.Vars(r)["sessionID"], token)
+		if !ok {
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already written an error response.
+			return
+		}
+
+		results := make(chan []byte, wsSendBuffer)
+		done := make(chan struct{})
+		var roomUpdates chan []byte
+		if room, ok := rooms.get(session.RoomID); session.RoomID != "" && ok {
+			client := &roomClient{conn: conn, send: make(chan []byte, wsSendBuffer)}
+			hub.add(room.ID, client)
+			defer hub.remove(room.ID, client)
+			roomUpdates = client.send
+		}
+		go func() {
+			writeSessionSocket(conn, session, results, roomUpdates)
+			close(done)
+		}()
+
+		send := func(msg SessionSocketMessage) {
+			payload, err := json.Marshal(msg)
+			if err != nil {
+				return
+			}
+			select {
+			case results <- payload:
+			case <-done:
+			}
+		}
+		send(runSocketAction(r, session, SessionSocketRequest{Action: "state"}))
+
+		conn.SetReadLimit(64 << 10)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				break
+			}
+			var req SessionSocketRequest
+			if err := json.Unmarshal(data, &req); err != nil {
+				send(SessionSocketMessage{Type: "error", Status: http.StatusBadRequest, Error: &APIError{CodeInvalidJSON, "bad json"}})
+				continue
+			}
+			send(runSocketAction(r, session, req))
+		}
+		close(results)
+		<-done
+	})
+}
+
+// writeSessionSocket is the connection's only writer: replies, room
+// pushes, timer ticks and pings. It returns when results is closed or a
+// write fails.
+func writeSessionSocket(conn *websocket.Conn, session *Session, results, roomUpdates <-chan []byte) {
+	ping := time.NewTicker(wsPingInterval)
+	timer := time.NewTicker(time.Second)
+	defer func() {
+		ping.Stop()
+		timer.Stop()
+		conn.Close()
+	}()
+
+	write := func(kind int, payload []byte) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteMessage(kind, payload) == nil
+	}
+
+	for {
+		var ok bool
+		select {
+		case payload, open := <-results:
+			if !open {
+				write(websocket.CloseMessage, nil)
+				return
+			}
+			ok = write(websocket.TextMessage, payload)
+		case payload, open := <-roomUpdates:
+			if !open {
+				roomUpdates = nil
+				continue
+			}
+			ok = write(websocket.TextMessage, payload)
+		case <-timer.C:
+			t := sessionTimer(session)
+			if t.Status == StatusFinished {
+				timer.Stop()
+			}
+			payload, _ := json.Marshal(SessionSocketMessage{Type: "timer", Timer: &t})
+			ok = write(websocket.TextMessage, payload)
+		case <-ping.C:
+			ok = write(websocket.PingMessage, nil)
+		}
+		if !ok {
+			return
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package guesser
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"strings"
+)
+
+// A finished game can be shared without giving the answer away: an emoji
+// grid in the style of Wordle, one square per question (green for yes),
+// then the hints and guesses, or the same as a PNG card. Unlike the recap
+// card, neither names the secret, even after a win.
+
+const (
+	// shareRowLength is how many questions go on one row of the grid.
+	shareRowLength = 5
+	// shareCardSquares caps the squares on the PNG card; any more are
+	// counted instead.
+	shareCardSquares = 40
+)
+
+var (
+	shareYes  = color.RGBA{0x22, 0xc5, 0x5e, 0xff}
+	shareNo   = color.RGBA{0x37, 0x41, 0x51, 0xff}
+	shareHint = color.RGBA{0xf5, 0x9e, 0x0b, 0xff}
+)
+
+type ShareResponse struct {
+	// Text is ready to paste: a header, the grid and the score.
+	Text      string  `json:"text"`
+	Outcome   Outcome `json:"outcome"`
+	Questions int     `json:"questions"`
+	Hints     int     `json:"hints"`
+	Guesses   int     `json:"guesses"`
+	Score     int     `json:"score"`
+}
+
+// shareTitle is the first line of the share text and card: the daily's
+// date for a daily challenge.
+func shareTitle(session *Session) string {
+	if session.daily.Date != "" {
+		return "Game Guesser Daily " + session.daily.Date
+	}
+	return "Game Guesser"
+}
+
+// shareHeadline is recapHeadline, counting guesses in hot/cold games,
+// which have no questions.
+func shareHeadline(state SessionState) string {
+	if state.Mode != ModeHotCold {
+		return recapHeadline(state.Outcome, len(state.Asked))
+	}
+	headline := recapHeadline(state.Outcome, len(state.Guesses))
+	return strings.Replace(headline, "questions", "guesses", 1)
+}
+
+// ShareText renders state as the emoji grid.
+func ShareText(title string, state SessionState) string {
+	var b strings.Builder
+	b.WriteString(title + "\n")
+	switch state.Outcome {
+	case OutcomeWon:
+		b.WriteString("✅ ")
+	case OutcomeGaveUp:
+		b.WriteString("🏳️ ")
+	default:
+		b.WriteString("❌ ")
+	}
+	b.WriteString(shareHeadline(state) + "\n")
+
+	for i, q := range state.Asked {
+		if i > 0 && i%shareRowLength == 0 {
+			b.WriteString("\n")
+		}
+		if q.Answer {
+			b.WriteString("🟩")
+		} else {
+			b.WriteString("⬛")
+		}
+	}
+	if len(state.Asked) > 0 {
+		b.WriteString("\n")
+	}
+	if len(state.Hints) > 0 {
+		b.WriteString(strings.Repeat("💡", len(state.Hints)) + "\n")
+	}
+	if len(state.Guesses) > 0 {
+		for _, g := range state.Guesses {
+			if g.Correct {
+				b.WriteString("🎯")
+			} else {
+				b.WriteString("❌")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "Score %d", Score(state))
+	return b.String()
+}
+
+// RenderSharePNG draws the share grid as a card the size of the recap card.
+func RenderSharePNG(title string, state SessionState) ([]byte, error) {
+	card := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	draw.Draw(card, card.Bounds(), image.NewUniform(cardBackground), image.Point{}, draw.Src)
+	draw.Draw(card, image.Rect(0, 0, cardWidth, 12), image.NewUniform(cardAccent), image.Point{}, draw.Src)
+
+	drawText(card, 70, 60, title, 3, cardMuted)
+	drawText(card, 70, 130, shareHeadline(state), 4, cardText)
+
+	const size, gap = 44, 10
+	x, y := 70, 230
+	square := func(c color.Color) {
+		draw.Draw(card, image.Rect(x, y, x+size, y+size), image.NewUniform(c), image.Point{}, draw.Src)
+		x += size + gap
+		if x+size > cardWidth-70 {
+			x, y = 70, y+size+gap
+		}
+	}
+	shown := min(len(state.Asked), shareCardSquares)
+	for _, q := range state.Asked[:shown] {
+		if q.Answer {
+			square(shareYes)
+		} else {
+			square(shareNo)
+		}
+	}
+	for range state.Hints {
+		square(shareHint)
+	}
+	if more := len(state.Asked) - shown; more > 0 {
+		drawText(card, x, y+8, fmt.Sprintf("+%d", more), 2, cardMuted)
+	}
+
+	wrong := 0
+	for _, g := range state.Guesses {
+		if !g.Correct {
+			wrong++
+		}
+	}
+	drawText(card, 70, 440, fmt.Sprintf("%s, %s", plural(len(state.Hints), "hint"), plural(wrong, "wrong guess")), 2, cardMuted)
+	drawText(card, 70, 500, fmt.Sprintf("Score %d", Score(state)), 4, cardAccent)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, card); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if strings.HasSuffix(noun, "s") {
+		return fmt.Sprintf("%d %ses", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func handleShare(w http.ResponseWriter, r *http.Request, session *Session) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+	state := session.State
+	if state.Status != StatusFinished {
+		writeError(w, http.StatusConflict, CodeSessionNotFinished, "only finished games can be shared")
+		return
+	}
+
+	title := shareTitle(session)
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		writeResponse(w, r, http.StatusOK, ShareResponse{
+			Text:      ShareText(title, state),
+			Outcome:   state.Outcome,
+			Questions: len(state.Asked),
+			Hints:     len(state.Hints),
+			Guesses:   len(state.Guesses),
+			Score:     Score(state),
+		})
+	case "png":
+		data, err := RenderSharePNG(title, state)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "could not render card")
+			return
+		}
+		// A finished game never changes.
+		w.Header().Set("Cache-Control", "private, max-age=86400, immutable")
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(data)
+	default:
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "format must be json or png")
+	}
+}
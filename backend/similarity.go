@@ -0,0 +1,144 @@
+package guesser
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// similarityWeights says how much each attribute contributes to
+// Similarity. The weights sum to 1.
+var similarityWeights = struct {
+	Genres, MainGenre, Theme, Year, Perspective, Franchise, Platforms, Tone, Setting float64
+}{
+	Genres:      0.25,
+	MainGenre:   0.10,
+	Theme:       0.15,
+	Year:        0.15,
+	Perspective: 0.10,
+	Franchise:   0.10,
+	Platforms:   0.05,
+	Tone:        0.05,
+	Setting:     0.05,
+}
+
+// similarityYearSpan is the release gap at which years stop counting as
+// similar at all.
+const similarityYearSpan = 15
+
+// jaccard is |a ∩ b| / |a ∪ b|, case-insensitive. Two empty slices are
+// treated as unrelated rather than identical.
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		set[strings.ToLower(v)] = true
+	}
+
+	inter := 0
+	union := len(set)
+	seenB := make(map[string]bool, len(b))
+	for _, v := range b {
+		key := strings.ToLower(v)
+		if seenB[key] {
+			continue
+		}
+		seenB[key] = true
+		if set[key] {
+			inter++
+		} else {
+			union++
+		}
+	}
+
+	return float64(inter) / float64(union)
+}
+
+func sameKnown(a, b string) float64 {
+	if a == "" || a == "Unknown" || !strings.EqualFold(a, b) {
+		return 0
+	}
+	return 1
+}
+
+// Similarity scores two games between 0 (nothing in common) and 1 (the
+// same game, as far as our attributes can tell) using weighted attribute
+// overlap.
+func Similarity(a, b *Game) float64 {
+	if a.ID == b.ID {
+		return 1
+	}
+
+	w := similarityWeights
+	score := 0.0
+
+	score += w.Genres * jaccard(a.Genres, b.Genres)
+	score += w.MainGenre * sameKnown(a.MainGenre, b.MainGenre)
+	score += w.Theme * sameKnown(a.Theme, b.Theme)
+	score += w.Perspective * sameKnown(a.Perspective, b.Perspective)
+	score += w.Platforms * jaccard(a.Platforms, b.Platforms)
+	score += w.Tone * jaccard(a.Tone, b.Tone)
+	score += w.Setting * jaccard(a.Setting, b.Setting)
+
+	if a.Franchise != "" && a.Franchise != "Standalone / Other" && a.Franchise == b.Franchise {
+		score += w.Franchise
+	}
+
+	if a.Year > 0 && b.Year > 0 {
+		gap := a.Year - b.Year
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap < similarityYearSpan {
+			score += w.Year * (1 - float64(gap)/similarityYearSpan)
+		}
+	}
+
+	// Distinct games never reach a perfect score.
+	if score > 0.99 {
+		score = 0.99
+	}
+	return score
+}
+
+// SimilarGame pairs a game with its similarity to some reference game.
+type SimilarGame struct {
+	Game       GameSummary `json:"game"`
+	Similarity float64     `json:"similarity"`
+}
+
+// MostSimilar returns the n games closest to the game with the given ID,
+// most similar first. Ties are broken by name for stable output.
+func MostSimilar(idx GameIndex, id int, n int) []SimilarGame {
+	ref, ok := idx.Games[id]
+	if !ok || n <= 0 {
+		return []SimilarGame{}
+	}
+
+	scored := make([]SimilarGame, 0, len(idx.AllGameIDs))
+	for _, otherID := range idx.AllGameIDs {
+		if otherID == id {
+			continue
+		}
+		other := idx.Games[otherID]
+		scored = append(scored, SimilarGame{
+			Game:       summarize(other),
+			Similarity: math.Round(Similarity(ref, other)*1000) / 1000,
+		})
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].Similarity != scored[j].Similarity {
+			return scored[i].Similarity > scored[j].Similarity
+		}
+		return scored[i].Game.Name < scored[j].Game.Name
+	})
+
+	if len(scored) > n {
+		scored = scored[:n]
+	}
+	return scored
+}
@@ -0,0 +1,70 @@
+package guesser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is one immutable version of the data a session plays against.
+// Reloading never mutates a published snapshot; it builds a new one and
+// swaps it in, so sessions that pinned the old one keep a consistent view.
+type Snapshot struct {
+	Version   uint64
+	Index     GameIndex
+	Templates []QuestionTemplate
+	LoadedAt  time.Time
+
+	// QuestionTypes is BuildQuestionTypeDefs(Templates), serialized once
+	// for session start responses.
+	QuestionTypes json.RawMessage
+
+	hashOnce    sync.Once
+	contentHash string
+}
+
+// ContentHash is a SHA-256 of the games, in catalog order, and the
+// question types. Reloading the same data gives the same hash.
+func (s *Snapshot) ContentHash() string {
+	s.hashOnce.Do(func() {
+		h := sha256.New()
+		enc := json.NewEncoder(h)
+		for _, id := range s.Index.AllGameIDs {
+			enc.Encode(s.Index.Games[id])
+		}
+		h.Write(s.QuestionTypes)
+		s.contentHash = hex.EncodeToString(h.Sum(nil))
+	})
+	return s.contentHash
+}
+
+// SnapshotHolder hands out the latest snapshot to new sessions. The zero
+// value holds nothing until the first Publish.
+type SnapshotHolder struct {
+	current atomic.Pointer[Snapshot]
+	version atomic.Uint64
+}
+
+// Current returns the latest snapshot, or nil before the first Publish.
+// Callers should grab it once per request and use it throughout.
+func (h *SnapshotHolder) Current() *Snapshot {
+	return h.current.Load()
+}
+
+// Publish makes idx and templates the snapshot for every new session.
+// Neither may be modified afterwards.
+func (h *SnapshotHolder) Publish(idx GameIndex, templates []QuestionTemplate) *Snapshot {
+	snap := &Snapshot{
+		Version:   h.version.Add(1),
+		Index:     idx,
+		Templates: templates,
+		LoadedAt:  time.Now(),
+
+		QuestionTypes: mustMarshal(BuildQuestionTypeDefs(templates)),
+	}
+	h.current.Store(snap)
+	return snap
+}
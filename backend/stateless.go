@@ -0,0 +1,409 @@
+package guesser
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Stateless sessions keep the whole game in a token the client sends back
+// with every request and gets a fresh copy of with every response, so any
+// replica can serve any request without a shared store. The token is
+// encrypted and authenticated with AES-GCM, so players can neither read
+// it (the secret, the candidates left) nor edit it.
+//
+// Each token is one move of its game. Once a move has been answered its
+// token is spent: sending it again, to take back a question or a wrong
+// guess, is refused. Read-only requests answer a move without playing it
+// and hand the same move back. Replicas learn each other's moves over the cluster
+// pub/sub bus; without one, a replay is only caught by the replica that
+// answered the move. Competitive play (daily challenges, rooms) stays on
+// stored sessions.
+
+// statelessHeader carries the state token in both directions.
+const statelessHeader = "X-Session-State"
+
+const (
+	statelessMovesTopic = "guesser.stateless"
+	// maxStatelessGames bounds the moves remembered; past it, the least
+	// recently played half is forgotten.
+	maxStatelessGames = 100_000
+)
+
+var (
+	errStatelessInvalid = errors.New("invalid session state token")
+	errStatelessExpired = errors.New("session expired")
+	errStatelessStale   = errors.New("this session state has already been played; send the latest token")
+)
+
+type statelessKeys struct {
+	aead cipher.AEAD
+}
+
+// stateless is nil until ConfigureStatelessSessions is called.
+var stateless *statelessKeys
+
+// ConfigureStatelessSessions enables /api/v1/stateless/. Every replica must
+// use the same key; changing it invalidates every running game.
+func ConfigureStatelessSessions(key string) error {
+	if len(key) < 16 {
+		return errors.New("key must be at least 16 characters")
+	}
+
+	encKey := sha256.Sum256([]byte("stateless-enc:" + key))
+	block, err := aes.NewCipher(encKey[:])
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	stateless = &statelessKeys{aead: aead}
+	return nil
+}
+
+// statelessPayload is what a token holds. Move counts the moves answered
+// before this token was issued; Version is the dataset snapshot the game
+// is played against.
+type statelessPayload struct {
+	ID        string       `json:"id"`
+	Tenant    string       `json:"tenant,omitempty"`
+	DatasetID string       `json:"dataset,omitempty"`
+	Version   uint64       `json:"version"`
+	State     SessionState `json:"state"`
+	Pool      []int        `json:"pool"`
+	Move      int          `json:"move"`
+	IssuedAt  int64        `json:"iat"`
+}
+
+// encode seals session at move into a token: deflated JSON, encrypted.
+func (k *statelessKeys) encode(session *Session, move int) (string, error) {
+	raw, err := json.Marshal(statelessPayload{
+		ID:        session.ID,
+		Tenant:    session.Tenant,
+		DatasetID: session.DatasetID,
+		Version:   session.Snapshot.Version,
+		State:     session.State,
+		Pool:      session.Pool,
+		Move:      move,
+		IssuedAt:  time.Now().Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	zw, _ := flate.NewWriter(&buf, flate.BestCompression)
+	zw.Write(raw)
+	zw.Close()
+
+	nonce := make([]byte, k.aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	return base64.RawURLEncoding.EncodeToString(k.aead.Seal(nonce, nonce, buf.Bytes(), nil)), nil
+}
+
+// decode opens a token and checks its age.
+func (k *statelessKeys) decode(token string, ttl time.Duration) (statelessPayload, error) {
+	var p statelessPayload
+
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	n := k.aead.NonceSize()
+	if err != nil || len(sealed) < n {
+		return p, errStatelessInvalid
+	}
+	body, err := k.aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return p, errStatelessInvalid
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(body)), 1<<20))
+	if err != nil || json.Unmarshal(raw, &p) != nil {
+		return p, errStatelessInvalid
+	}
+	if ttl > 0 && time.Since(time.Unix(p.IssuedAt, 0)) > ttl {
+		return p, errStatelessExpired
+	}
+	return p, nil
+}
+
+type statelessMove struct {
+	next int // the first move not yet answered
+	seen time.Time
+}
+
+// statelessMoveStore remembers how far each stateless game has got.
+type statelessMoveStore struct {
+	mu    sync.Mutex
+	games map[string]*statelessMove
+}
+
+// global in-memory record of stateless moves answered
+var statelessMoves = &statelessMoveStore{games: make(map[string]*statelessMove)}
+
+// played reports whether move of game id has been answered already.
+func (s *statelessMoveStore) played(id string, move int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.games[id]
+	return ok && move < g.next
+}
+
+// claim reserves move of game id for answering. It is false when the move
+// has been answered already.
+func (s *statelessMoveStore) claim(id string, move int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if g, ok := s.games[id]; ok && move < g.next {
+		return false
+	}
+	s.advanceLocked(id, move+1)
+	return true
+}
+
+// advance records that game id's moves before next have been answered.
+func (s *statelessMoveStore) advance(id string, next int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advanceLocked(id, next)
+}
+
+func (s *statelessMoveStore) advanceLocked(id string, next int) {
+	now := time.Now()
+	g, ok := s.games[id]
+	if !ok {
+		if len(s.games) >= maxStatelessGames {
+			s.forgetLocked(now)
+		}
+		g = &statelessMove{}
+		s.games[id] = g
+	}
+	g.next = max(g.next, next)
+	g.seen = now
+}
+
+// forgetLocked drops the games whose tokens have all expired, or failing
+// that the least recently played half.
+func (s *statelessMoveStore) forgetLocked(now time.Time) {
+	if store.ttl > 0 {
+		for id, g := range s.games {
+			if now.Sub(g.seen) > store.ttl {
+				delete(s.games, id)
+			}
+		}
+		if len(s.games) < maxStatelessGames {
+			return
+		}
+	}
+	ids := make([]string, 0, len(s.games))
+	for id := range s.games {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return s.games[ids[i]].seen.Before(s.games[ids[j]].seen) })
+	for _, id := range ids[:len(ids)/2] {
+		delete(s.games, id)
+	}
+}
+
+type statelessMoveEvent struct {
+	ID     string `json:"id"`
+	Next   int    `json:"next"`
+	Origin string `json:"origin"`
+}
+
+// subscribeStatelessMoves applies the moves other instances answer.
+func subscribeStatelessMoves(ps PubSub) error {
+	return ps.Subscribe(context.Background(), statelessMovesTopic, func(payload []byte) {
+		var ev statelessMoveEvent
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			slog.Warn("pubsub: bad stateless move", "err", err)
+			return
+		}
+		if ev.Origin != instanceID {
+			statelessMoves.advance(ev.ID, ev.Next)
+		}
+	})
+}
+
+// publishStatelessMove tells other instances that move of game id has
+// been answered.
+func publishStatelessMove(id string, move int) {
+	if bus == nil {
+		return
+	}
+	payload, err := json.Marshal(statelessMoveEvent{ID: id, Next: move + 1, Origin: instanceID})
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := bus.Publish(ctx, statelessMovesTopic, payload); err != nil {
+		slog.Error("pubsub: publish stateless move", "err", err)
+	}
+}
+
+// writeStateless sends a captured handler response with session's new
+// token, for move, attached.
+func writeStateless(w http.ResponseWriter, session *Session, move int, resp *capturedResponse) {
+	token, err := stateless.encode(session, move)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "could not encode session")
+		return
+	}
+
+	for k, v := range resp.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set(statelessHeader, token)
+	if resp.status == 0 {
+		resp.status = http.StatusOK
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body.Bytes())
+}
+
+// statelessPlays are the session actions that change the game, and so
+// spend the token they were sent with.
+var statelessPlays = map[string]bool{
+	"ask":           true,
+	"guess":         true,
+	"giveup":        true,
+	"hint":          true,
+	"next-question": true,
+	"answer":        true,
+}
+
+// ---------------------------------
+// /api/v1/stateless/start             (POST, same body as /api/v1/session/start)
+// /api/v1/stateless/session[/action]  (same actions as /api/v1/session/{id})
+//
+// Both answer with the new token in X-Session-State; session requests
+// must send the latest one back in the same header.
+// ---------------------------------
+
+func StatelessHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !statelessEnabled(w) {
+			return
+		}
+
+		p, err := stateless.decode(r.Header.Get(statelessHeader), store.ttl)
+		if errors.Is(err, errStatelessExpired) {
+			writeError(w, http.StatusGone, CodeSessionExpired, err.Error())
+			return
+		}
+		if err != nil || p.Tenant != tenantID(r) {
+			writeError(w, http.StatusForbidden, CodeInvalidSessionState, errStatelessInvalid.Error())
+			return
+		}
+		if !allowKeyDataset(w, r, p.DatasetID) {
+			return
+		}
+
+		holder, ok := selectDataset(r, data, p.DatasetID)
+		if !ok || holder.Current().Version != p.Version {
+			writeError(w, http.StatusGone, CodeDatasetChanged, "the game's dataset has changed; start a new game")
+			return
+		}
+
+		action := mux.Vars(r)["action"]
+		move := p.Move
+		if statelessPlays[action] {
+			if !statelessMoves.claim(p.ID, p.Move) {
+				writeError(w, http.StatusConflict, CodeStaleSessionState, errStatelessStale.Error())
+				return
+			}
+			publishStatelessMove(p.ID, p.Move)
+			move++
+		} else if statelessMoves.played(p.ID, p.Move) {
+			writeError(w, http.StatusConflict, CodeStaleSessionState, errStatelessStale.Error())
+			return
+		}
+
+		session := &Session{
+			ID:        p.ID,
+			State:     p.State,
+			Tenant:    p.Tenant,
+			Snapshot:  holder.Current(),
+			Pool:      p.Pool,
+			DatasetID: p.DatasetID,
+		}
+		resp := &capturedResponse{header: http.Header{}}
+		serveSessionAction(resp, r, session, action)
+		writeStateless(w, session, move, resp)
+	})
+}
+
+func StatelessStartHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if statelessEnabled(w) {
+			handleStatelessStart(w, r, data)
+		}
+	})
+}
+
+// statelessEnabled answers 404 while stateless sessions are off.
+func statelessEnabled(w http.ResponseWriter) bool {
+	if stateless == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "stateless sessions are not enabled")
+		return false
+	}
+	return true
+}
+
+func handleStatelessStart(w http.ResponseWriter, r *http.Request, data *SnapshotHolder) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+
+	var req StartSessionRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "bad json")
+		return
+	}
+	if req.Seed != "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "seeds are only available for stored sessions")
+		return
+	}
+
+	client := recentClient(w, r, req)
+	snap, state, datasetID, reqErr := newSessionState(r, data, req, recentSecrets.recent(client))
+	if reqErr != nil {
+		reqErr.write(w)
+		return
+	}
+	recentSecrets.add(client, state.SecretID)
+
+	session := &Session{
+		ID:        randomSessionID(),
+		State:     state,
+		Tenant:    tenantID(r),
+		Snapshot:  snap,
+		Pool:      append([]int(nil), state.RemainingIDs...),
+		DatasetID: datasetID,
+	}
+
+	start := newStartResponse(snap, state, datasetID)
+	start.SessionID = session.ID
+
+	resp := &capturedResponse{header: http.Header{}}
+	writeResponse(resp, r, http.StatusOK, start)
+	writeStateless(w, session, 0, resp)
+}
@@ -0,0 +1,36 @@
+package guesser
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// The production frontend is compiled into the binary: `npm run build` in
+// frontend/ writes to backend/web/dist, which go:embed picks up, so the
+// server ships as a single file. Without a build the directory is absent
+// and the embedded frontend is empty; the API works either way.
+
+//go:embed all:web
+var embeddedWeb embed.FS
+
+func embeddedFrontend() fs.FS {
+	dist, _ := fs.Sub(embeddedWeb, "web/dist")
+	return dist
+}
+
+// HasEmbeddedFrontend reports whether a frontend build was embedded.
+func HasEmbeddedFrontend() bool {
+	_, err := fs.Stat(embeddedFrontend(), "index.html")
+	return err == nil
+}
+
+// StaticHandler serves the frontend from dir on disk, for development
+// with a rebuilt or live-edited dist, or from the embedded build when dir
+// is empty.
+func StaticHandler(dir string) http.Handler {
+	if dir != "" {
+		return http.FileServer(http.Dir(dir))
+	}
+	return http.FileServerFS(embeddedFrontend())
+}
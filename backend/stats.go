@@ -0,0 +1,283 @@
+package guesser
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Every finished classic or hot/cold game is added to per-dataset totals:
+// how it ended, how many questions it took, which question types it used
+// and which game was the secret. Nothing about the player is kept.
+// GET /api/v1/stats reports them. SaveSessions keeps them across
+// restarts.
+
+const (
+	defaultStatsLimit = 10
+	maxStatsLimit     = 100
+	// hardestMinPlays keeps games that have only come up once or twice
+	// off the hardest list.
+	hardestMinPlays = 3
+)
+
+type statsKey struct {
+	Tenant    string
+	DatasetID string
+}
+
+// datasetStats are the totals for one dataset.
+type datasetStats struct {
+	Games     int                `json:"games"`
+	Wins      int                `json:"wins"`
+	Questions int                `json:"questions"`
+	Secrets   map[int]*gameStats `json:"secrets"`
+	Templates map[string]int     `json:"templates"`
+}
+
+// gameStats are the totals for the games one title was the secret of.
+// Scores counts the games by score, losses being 0.
+type gameStats struct {
+	Played    int         `json:"played"`
+	Won       int         `json:"won"`
+	Questions int         `json:"questions"`
+	Scores    map[int]int `json:"scores,omitempty"`
+}
+
+type statsStore struct {
+	mu       sync.Mutex
+	datasets map[statsKey]*datasetStats
+}
+
+// global in-memory aggregate of finished games
+var stats = &statsStore{datasets: make(map[statsKey]*datasetStats)}
+
+func newDatasetStats() *datasetStats {
+	return &datasetStats{Secrets: map[int]*gameStats{}, Templates: map[string]int{}}
+}
+
+func statsKeyFor(tenant, datasetID string) statsKey {
+	if datasetID == "" {
+		datasetID = defaultDatasetID
+	}
+	return statsKey{Tenant: tenant, DatasetID: datasetID}
+}
+
+// record adds a finished game. Reverse games are left out: their secret
+// is the player's, and the questions are the solver's.
+func (s *statsStore) record(session *Session) {
+	state := session.State
+	if state.Mode == ModeReverse || state.Status != StatusFinished {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := statsKeyFor(session.Tenant, session.DatasetID)
+	ds, ok := s.datasets[key]
+	if !ok {
+		ds = newDatasetStats()
+		s.datasets[key] = ds
+	}
+	g, ok := ds.Secrets[state.SecretID]
+	if !ok {
+		g = &gameStats{}
+		ds.Secrets[state.SecretID] = g
+	}
+
+	if g.Scores == nil {
+		g.Scores = map[int]int{}
+	}
+
+	won := state.Outcome == OutcomeWon
+	ds.Games++
+	ds.Questions += len(state.Asked)
+	g.Played++
+	g.Questions += len(state.Asked)
+	g.Scores[Score(state)]++
+	if won {
+		ds.Wins++
+		g.Won++
+	}
+	for _, q := range state.Asked {
+		ds.Templates[q.QuestionTypeID]++
+	}
+}
+
+// percentile is the share (0-100) of the other recorded games on secretID
+// that scored below score, ties counting half, and how many there were.
+// The game being ranked must already be recorded. ok is false while it is
+// the only one.
+func (s *statsStore) percentile(key statsKey, secretID, score int) (pct float64, others int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds, found := s.datasets[key]
+	if !found || ds.Secrets[secretID] == nil {
+		return 0, 0, false
+	}
+	below, ties := 0, -1 // not counting the game itself
+	for other, n := range ds.Secrets[secretID].Scores {
+		switch {
+		case other < score:
+			below += n
+		case other == score:
+			ties += n
+		}
+		others += n
+	}
+	others--
+	if others <= 0 {
+		return 0, 0, false
+	}
+	return 100 * (float64(below) + float64(ties)/2) / float64(others), others, true
+}
+
+type StatsResponse struct {
+	DatasetID        string  `json:"datasetId"`
+	Games            int     `json:"games"`
+	Wins             int     `json:"wins"`
+	WinRate          float64 `json:"winRate"`
+	AverageQuestions float64 `json:"averageQuestions"`
+	// MostGuessed are the secrets solved most often; Hardest are those
+	// solved least often, of those played at least three times.
+	MostGuessed []GameStats     `json:"mostGuessed"`
+	Hardest     []GameStats     `json:"hardest"`
+	Templates   []TemplateUsage `json:"templates"`
+}
+
+type GameStats struct {
+	Game             GameSummary `json:"game"`
+	Played           int         `json:"played"`
+	Won              int         `json:"won"`
+	WinRate          float64     `json:"winRate"`
+	AverageQuestions float64     `json:"averageQuestions"`
+}
+
+type TemplateUsage struct {
+	QuestionTypeID string `json:"questionTypeId"`
+	Uses           int    `json:"uses"`
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// report summarises key's totals, naming games from idx. Secrets no
+// longer in the dataset are left out of the lists.
+func (s *statsStore) report(key statsKey, idx GameIndex, limit int) StatsResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := StatsResponse{
+		DatasetID:   key.DatasetID,
+		MostGuessed: []GameStats{},
+		Hardest:     []GameStats{},
+		Templates:   []TemplateUsage{},
+	}
+	ds, ok := s.datasets[key]
+	if !ok {
+		return resp
+	}
+	resp.Games = ds.Games
+	resp.Wins = ds.Wins
+	resp.WinRate = ratio(ds.Wins, ds.Games)
+	resp.AverageQuestions = ratio(ds.Questions, ds.Games)
+
+	var games []GameStats
+	for id, g := range ds.Secrets {
+		game, ok := idx.Games[id]
+		if !ok {
+			continue
+		}
+		games = append(games, GameStats{
+			Game:             summarize(game),
+			Played:           g.Played,
+			Won:              g.Won,
+			WinRate:          ratio(g.Won, g.Played),
+			AverageQuestions: ratio(g.Questions, g.Played),
+		})
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		a, b := games[i], games[j]
+		if a.Won != b.Won {
+			return a.Won > b.Won
+		}
+		if a.AverageQuestions != b.AverageQuestions {
+			return a.AverageQuestions < b.AverageQuestions
+		}
+		return a.Game.ID < b.Game.ID
+	})
+	for _, g := range games {
+		if len(resp.MostGuessed) == limit || g.Won == 0 {
+			break
+		}
+		resp.MostGuessed = append(resp.MostGuessed, g)
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		a, b := games[i], games[j]
+		if a.WinRate != b.WinRate {
+			return a.WinRate < b.WinRate
+		}
+		if a.AverageQuestions != b.AverageQuestions {
+			return a.AverageQuestions > b.AverageQuestions
+		}
+		return a.Game.ID < b.Game.ID
+	})
+	for _, g := range games {
+		if len(resp.Hardest) == limit {
+			break
+		}
+		if g.Played >= hardestMinPlays {
+			resp.Hardest = append(resp.Hardest, g)
+		}
+	}
+
+	for id, uses := range ds.Templates {
+		resp.Templates = append(resp.Templates, TemplateUsage{QuestionTypeID: id, Uses: uses})
+	}
+	sort.Slice(resp.Templates, func(i, j int) bool {
+		a, b := resp.Templates[i], resp.Templates[j]
+		if a.Uses != b.Uses {
+			return a.Uses > b.Uses
+		}
+		return a.QuestionTypeID < b.QuestionTypeID
+	})
+	return resp
+}
+
+// ---------------------------------
+// /api/v1/stats   (GET, ?dataset=, ?limit=)
+// ---------------------------------
+
+func StatsHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		datasetID := r.URL.Query().Get("dataset")
+		if !allowKeyDataset(w, r, datasetID) {
+			return
+		}
+		holder, ok := selectDataset(r, data, datasetID)
+		if !ok {
+			writeError(w, http.StatusNotFound, CodeUnknownDataset, "unknown dataset")
+			return
+		}
+		limit, ok := queryInt(r, "limit", defaultStatsLimit, maxStatsLimit)
+		if !ok {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "limit must be a positive integer")
+			return
+		}
+
+		key := statsKeyFor(tenantID(r), datasetID)
+		writeResponse(w, r, http.StatusOK, stats.report(key, holder.Current().Index, limit))
+	})
+}
@@ -0,0 +1,146 @@
+package guesser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// SteamOwnedGame is one entry of a user's Steam library.
+type SteamOwnedGame struct {
+	AppID int    `json:"appid"`
+	Name  string `json:"name"`
+}
+
+// SteamClient talks to the Steam Web API.
+type SteamClient struct {
+	APIKey  string
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// ErrSteamLibraryPrivate is returned when Steam hides the user's games,
+// which happens whenever their profile's game details are not public.
+var ErrSteamLibraryPrivate = errors.New("steam library is private or empty")
+
+func NewSteamClient(apiKey string) *SteamClient {
+	return &SteamClient{
+		APIKey:  apiKey,
+		BaseURL: "https://api.steampowered.com",
+		HTTP:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+var steamID64Pattern = regexp.MustCompile(`^\d{17}$`)
+
+func (c *SteamClient) getJSON(ctx context.Context, path string, params url.Values, out any) error {
+	params.Set("key", c.APIKey)
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("steam %s: HTTP %d", path, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ResolveSteamID accepts a 64-bit Steam ID or a profile vanity name and
+// returns the Steam ID.
+func (c *SteamClient) ResolveSteamID(ctx context.Context, input string) (string, error) {
+	if steamID64Pattern.MatchString(input) {
+		return input, nil
+	}
+
+	var body struct {
+		Response struct {
+			SteamID string `json:"steamid"`
+			Success int    `json:"success"`
+		} `json:"response"`
+	}
+
+	params := url.Values{"vanityurl": {input}}
+	if err := c.getJSON(ctx, "/ISteamUser/ResolveVanityURL/v1/", params, &body); err != nil {
+		return "", err
+	}
+
+	if body.Response.Success != 1 {
+		return "", fmt.Errorf("no steam profile named %q", input)
+	}
+
+	return body.Response.SteamID, nil
+}
+
+// OwnedGames returns the games in a user's library.
+func (c *SteamClient) OwnedGames(ctx context.Context, steamID string) ([]SteamOwnedGame, error) {
+	var body struct {
+		Response struct {
+			Games []SteamOwnedGame `json:"games"`
+		} `json:"response"`
+	}
+
+	params := url.Values{
+		"steamid":                   {steamID},
+		"include_appinfo":           {"1"},
+		"include_played_free_games": {"1"},
+	}
+	if err := c.getJSON(ctx, "/IPlayerService/GetOwnedGames/v1/", params, &body); err != nil {
+		return nil, err
+	}
+
+	if len(body.Response.Games) == 0 {
+		return nil, ErrSteamLibraryPrivate
+	}
+
+	return body.Response.Games, nil
+}
+
+// MatchSteamLibrary maps owned Steam games onto dataset IDs by normalized
+// title. Games that are not in the dataset are skipped.
+func MatchSteamLibrary(idx GameIndex, owned []SteamOwnedGame) []int {
+	byTitle := make(map[string]int, len(idx.Games))
+	for _, id := range idx.AllGameIDs {
+		byTitle[normalizeTitle(idx.Games[id].Name)] = id
+	}
+
+	seen := make(map[int]bool)
+	matched := make([]int, 0)
+
+	for _, g := range owned {
+		id, ok := byTitle[normalizeTitle(g.Name)]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		matched = append(matched, id)
+	}
+
+	return matched
+}
+
+// global Steam client; nil until ConfigureSteam is called with a key
+var steamClient *SteamClient
+
+// ConfigureSteam enables the Steam library endpoints.
+func ConfigureSteam(apiKey string) {
+	if apiKey == "" {
+		steamClient = nil
+		return
+	}
+	steamClient = NewSteamClient(apiKey)
+}
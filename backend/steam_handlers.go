@@ -0,0 +1,97 @@
+package guesser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type SteamStartRequest struct {
+	// SteamID is a 64-bit Steam ID or a profile vanity name.
+	SteamID string `json:"steamId"`
+}
+
+type SteamStartResponse struct {
+	StartSessionResponse
+	LibrarySize  int `json:"librarySize"`
+	MatchedCount int `json:"matchedCount"`
+}
+
+// minSteamPool is the smallest matched library we will start a game on;
+// anything less is not much of a guessing game.
+const minSteamPool = 2
+
+// ---------------------------------
+// /api/v1/steam/start   (POST)
+// ---------------------------------
+
+// SteamStartHandler starts a session whose candidates are the games from
+// the player's own Steam library that exist in the dataset.
+func SteamStartHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+
+		if steamClient == nil {
+			writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "steam import is not configured")
+			return
+		}
+
+		var req SteamStartRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidJSON, "bad json")
+			return
+		}
+
+		req.SteamID = strings.TrimSpace(req.SteamID)
+		if req.SteamID == "" {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "steamId is required")
+			return
+		}
+
+		steamID, err := steamClient.ResolveSteamID(r.Context(), req.SteamID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		owned, err := steamClient.OwnedGames(r.Context(), steamID)
+		if errors.Is(err, ErrSteamLibraryPrivate) {
+			writeError(w, http.StatusUnprocessableEntity, CodeSteamLibrary, err.Error())
+			return
+		} else if err != nil {
+			writeError(w, http.StatusBadGateway, CodeUpstream, "steam request failed")
+			return
+		}
+
+		snap := data.Current()
+		idx := snap.Index
+
+		pool := MatchSteamLibrary(idx, owned)
+		if len(pool) < minSteamPool {
+			msg := fmt.Sprintf("only %d of %d owned games are in our dataset", len(pool), len(owned))
+			writeError(w, http.StatusUnprocessableEntity, CodeSteamLibrary, msg)
+			return
+		}
+
+		state := NewSessionStateFromPool(pool, RandomSecret(secretPicker, idx, pool))
+		session := store.create(tenantID(r), snap, state)
+
+		writeResponse(w, r, http.StatusOK, SteamStartResponse{
+			StartSessionResponse: StartSessionResponse{
+				SessionID:       session.ID,
+				ClientToken:     session.Token,
+				DatasetID:       defaultDatasetID,
+				DatasetSize:     len(idx.Games),
+				CandidatesCount: len(state.RemainingIDs),
+				QuestionTypes:   snap.QuestionTypes,
+			},
+			LibrarySize:  len(owned),
+			MatchedCount: len(pool),
+		})
+	})
+}
@@ -0,0 +1,161 @@
+package guesser
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// titleKey is one searchable name of a game: its title or an alias,
+// normalized. A game has one key per name.
+type titleKey struct {
+	Key   string
+	ID    int
+	Alias string // original alias text; empty for the title itself
+}
+
+// buildTitleKeys lists every name in ids, sorted by key and then ID so
+// prefix lookups are a binary search.
+func buildTitleKeys(games map[int]*Game, ids []int) []titleKey {
+	keys := make([]titleKey, 0, len(ids))
+
+	for _, id := range ids {
+		g := games[id]
+		if key := normalizeTitle(g.Name); key != "" {
+			keys = append(keys, titleKey{Key: key, ID: id})
+		}
+		for _, alias := range g.Aliases {
+			if key := normalizeTitle(alias); key != "" {
+				keys = append(keys, titleKey{Key: key, ID: id, Alias: alias})
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Key != keys[j].Key {
+			return keys[i].Key < keys[j].Key
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+type Suggestion struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Year int    `json:"year"`
+	// Alias is set when the query matched an alias rather than the title.
+	Alias string `json:"alias,omitempty"`
+}
+
+type SuggestResponse struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+const (
+	defaultSuggestLimit = 8
+	maxSuggestLimit     = 20
+)
+
+// SuggestGames returns up to limit games for a typeahead query. Titles
+// or aliases that start with the query come first, then ones with a word
+// that does, then near-misses that are a typo or two off (see
+// fuzzyPrefix). Each game appears once.
+func SuggestGames(idx GameIndex, query string, limit int) []Suggestion {
+	q := normalizeTitle(query)
+	result := make([]Suggestion, 0, limit)
+	if q == "" {
+		return result
+	}
+
+	seen := make(map[int]bool)
+	add := func(k titleKey) bool {
+		if !seen[k.ID] {
+			seen[k.ID] = true
+			g := idx.Games[k.ID]
+			result = append(result, Suggestion{ID: g.ID, Name: g.Name, Year: g.Year, Alias: k.Alias})
+		}
+		return len(result) == limit
+	}
+
+	keys := idx.titles
+	start := sort.Search(len(keys), func(i int) bool { return keys[i].Key >= q })
+	for _, k := range keys[start:] {
+		if !strings.HasPrefix(k.Key, q) {
+			break
+		}
+		if add(k) {
+			return result
+		}
+	}
+
+	for _, k := range keys {
+		if strings.Contains(k.Key, " "+q) && add(k) {
+			return result
+		}
+	}
+
+	// Short queries are too ambiguous for typo tolerance.
+	n := utf8.RuneCountInString(q)
+	if n < 4 {
+		return result
+	}
+	for _, k := range keys {
+		if fuzzyPrefix(q, n, k.Key) && add(k) {
+			return result
+		}
+	}
+
+	return result
+}
+
+// fuzzyPrefix reports whether q (n runes long) is within one edit per
+// four runes of the start of key or of any word in it. The compared
+// stretch of key may be a rune shorter or longer than q, so a dropped or
+// doubled letter still lines up.
+func fuzzyPrefix(q string, n int, key string) bool {
+	allowed := max(1, n/4)
+	runes := []rune(key)
+
+	for i := range runes {
+		if i > 0 && runes[i-1] != ' ' {
+			continue
+		}
+		for size := n - 1; size <= n+1; size++ {
+			end := min(i+size, len(runes))
+			if levenshtein(q, string(runes[i:end])) <= allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ---------------------------------
+// /api/v1/games/suggest   (GET, ?q=&limit=)
+// ---------------------------------
+
+func SuggestHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+
+		query := r.URL.Query().Get("q")
+		if strings.TrimSpace(query) == "" {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "q is required")
+			return
+		}
+
+		limit, ok := queryInt(r, "limit", defaultSuggestLimit, maxSuggestLimit)
+		if !ok {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, "limit must be a positive integer")
+			return
+		}
+
+		resp := SuggestResponse{Suggestions: SuggestGames(data.Current().Index, query, limit)}
+		writeResponse(w, r, http.StatusOK, resp)
+	})
+}
@@ -0,0 +1,171 @@
+package guesser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateConfig is a question template defined in a file instead of in
+// Go. Check is an expr-lang expression (https://expr-lang.org) over the
+// game's fields, named as in games.json, that must come out true or
+// false. Templates with Values also see the asked option as value:
+//
+//	# templates.yaml
+//	- id: made_in_japan
+//	  category: Developer
+//	  prompt: Was it made in Japan?
+//	  check: developer_region == "Japan"
+//
+//	- id: tone_is
+//	  category: Tone
+//	  prompt: Is it %s?
+//	  values: [Dark, Cute]
+//	  check: value in tone
+//
+// With derive_values: true, values come from each dataset's field instead
+// (see DeriveValues). revealing: true keeps the template out of hard
+// sessions, and optional: true drops the values (or the yes/no template)
+// no game in a dataset matches.
+//
+// Besides expr's builtins, ageRating("16+") and scoreRank("80-89") turn
+// those fields into comparable numbers.
+type TemplateConfig struct {
+	ID       string   `json:"id" yaml:"id"`
+	Category string   `json:"category" yaml:"category"`
+	Field    string   `json:"field" yaml:"field"`
+	Prompt   string   `json:"prompt" yaml:"prompt"`
+	Values   []string `json:"values" yaml:"values"`
+	Check    string   `json:"check" yaml:"check"`
+
+	DeriveValues bool `json:"derive_values" yaml:"derive_values"`
+	Revealing    bool `json:"revealing" yaml:"revealing"`
+	Optional     bool `json:"optional" yaml:"optional"`
+}
+
+// templateEnv is what a Check expression can see.
+func templateEnv(g *Game, value string) map[string]any {
+	v := reflect.ValueOf(g).Elem()
+	env := make(map[string]any, len(gameFields)+3)
+	for name, index := range gameFields {
+		env[name] = v.FieldByIndex(index).Interface()
+	}
+	env["value"] = value
+	env["ageRating"] = ageRatingValue
+	env["scoreRank"] = scoreBucketRank
+	return env
+}
+
+// LoadTemplateConfigs reads and compiles a JSON or YAML (by extension)
+// list of TemplateConfig. Every expression is type-checked here, so a bad
+// file fails at startup rather than mid-game.
+func LoadTemplateConfigs(path string) ([]QuestionTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []TemplateConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &configs)
+	default:
+		err = json.Unmarshal(data, &configs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	templates := make([]QuestionTemplate, 0, len(configs))
+	seen := map[string]bool{}
+	for i, c := range configs {
+		if c.ID == "" {
+			return nil, fmt.Errorf("%s: template %d: id is required", path, i+1)
+		}
+		if seen[c.ID] {
+			return nil, fmt.Errorf("%s: template %s is defined twice", path, c.ID)
+		}
+		seen[c.ID] = true
+
+		t, err := compileTemplate(c)
+		if err != nil {
+			return nil, fmt.Errorf("%s: template %s: %w", path, c.ID, err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func compileTemplate(c TemplateConfig) (QuestionTemplate, error) {
+	if c.Category == "" {
+		return QuestionTemplate{}, errors.New("category is required")
+	}
+	if strings.TrimSpace(c.Check) == "" {
+		return QuestionTemplate{}, errors.New("check is required")
+	}
+
+	if _, ok := gameFields[c.Field]; c.DeriveValues && !ok {
+		return QuestionTemplate{}, fmt.Errorf("derive_values needs a known field, not %q", c.Field)
+	}
+
+	program, err := expr.Compile(c.Check, expr.Env(templateEnv(&Game{}, "")), expr.AsBool())
+	if err != nil {
+		return QuestionTemplate{}, fmt.Errorf("check: %w", err)
+	}
+
+	t := QuestionTemplate{
+		ID:       c.ID,
+		Category: c.Category,
+		Field:    c.Field,
+		Prompt:   c.Prompt,
+		Values:   c.Values,
+
+		DeriveValues: c.DeriveValues,
+		Revealing:    c.Revealing,
+		Optional:     c.Optional,
+	}
+	if len(c.Values) == 0 && !c.DeriveValues {
+		t.CheckBool = func(g *Game) bool { return runCheck(program, g, "") }
+	} else {
+		t.CheckString = func(g *Game, v string) bool { return runCheck(program, g, v) }
+	}
+	return t, nil
+}
+
+// runCheck evaluates a compiled Check. Runtime errors (int("n/a"), say)
+// count as "no", like a Go check that can't parse its value.
+func runCheck(program *vm.Program, g *Game, value string) bool {
+	out, err := expr.Run(program, templateEnv(g, value))
+	if err != nil {
+		return false
+	}
+	yes, _ := out.(bool)
+	return yes
+}
+
+// MergeTemplates adds extra to base: a template with an ID base already
+// has replaces it in place, new ones go at the end.
+func MergeTemplates(base, extra []QuestionTemplate) []QuestionTemplate {
+	merged := append([]QuestionTemplate(nil), base...)
+	at := make(map[string]int, len(merged))
+	for i, t := range merged {
+		at[t.ID] = i
+	}
+	for _, t := range extra {
+		if i, ok := at[t.ID]; ok {
+			merged[i] = t
+			continue
+		}
+		at[t.ID] = len(merged)
+		merged = append(merged, t)
+	}
+	return merged
+}
@@ -0,0 +1,123 @@
+package guesser
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// TemplateLintIssue is one problem found by LintTemplates.
+type TemplateLintIssue struct {
+	TemplateID string
+	Value      string // empty for template-wide problems
+	Problem    string
+}
+
+func (i TemplateLintIssue) String() string {
+	if i.Value == "" {
+		return fmt.Sprintf("%s: %s", i.TemplateID, i.Problem)
+	}
+	return fmt.Sprintf("%s=%q: %s", i.TemplateID, i.Value, i.Problem)
+}
+
+// gameFields maps Game's JSON field names to struct field indexes.
+var gameFields = func() map[string][]int {
+	fields := map[string][]int{}
+	t := reflect.TypeOf(Game{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = t.Field(i).Index
+		}
+	}
+	return fields
+}()
+
+// LintTemplates cross-checks templates against the dataset and reports
+// duplicate IDs, unknown or always-empty Game fields, and values that no
+// game or every game matches.
+func LintTemplates(idx GameIndex, templates []QuestionTemplate) []TemplateLintIssue {
+	var issues []TemplateLintIssue
+	fields := gameFields
+	seen := make(map[string]bool, len(templates))
+	emptyField := make(map[string]bool)
+
+	for _, t := range templates {
+		if seen[t.ID] {
+			issues = append(issues, TemplateLintIssue{TemplateID: t.ID, Problem: "duplicate template ID"})
+		}
+		seen[t.ID] = true
+
+		if !t.HasLogic() {
+			issues = append(issues, TemplateLintIssue{TemplateID: t.ID, Problem: "no check function"})
+			continue
+		}
+
+		switch i, ok := fields[t.Field]; {
+		case t.Field == "":
+			issues = append(issues, TemplateLintIssue{TemplateID: t.ID, Problem: "no Field declared"})
+		case !ok:
+			issues = append(issues, TemplateLintIssue{TemplateID: t.ID, Problem: fmt.Sprintf("unknown game field %q", t.Field)})
+		default:
+			empty, checked := emptyField[t.Field]
+			if !checked {
+				if idx.Attributes != nil {
+					empty = len(idx.Attributes.Values(t.Field)) == 0
+				} else {
+					empty = fieldAlwaysEmpty(idx, i)
+				}
+				emptyField[t.Field] = empty
+			}
+			if empty {
+				issues = append(issues, TemplateLintIssue{TemplateID: t.ID, Problem: fmt.Sprintf("field %q is empty for every game", t.Field)})
+			}
+		}
+
+		values := t.Values
+		if len(values) == 0 {
+			values = []string{""}
+		}
+		for _, v := range values {
+			switch countMatches(t, v, idx.AllGameIDs, idx) {
+			case 0:
+				issues = append(issues, TemplateLintIssue{TemplateID: t.ID, Value: v, Problem: "matches no games"})
+			case len(idx.AllGameIDs):
+				issues = append(issues, TemplateLintIssue{TemplateID: t.ID, Value: v, Problem: "matches every game"})
+			}
+		}
+	}
+
+	return issues
+}
+
+func fieldAlwaysEmpty(idx GameIndex, field []int) bool {
+	for _, g := range idx.Games {
+		v := reflect.ValueOf(g).Elem().FieldByIndex(field)
+		if v.Kind() == reflect.Slice {
+			if v.Len() > 0 {
+				return false
+			}
+		} else if !v.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// RunLintTemplates prints every issue to out and returns the process exit
+// code: 0 when clean, 1 otherwise.
+func RunLintTemplates(out io.Writer, idx GameIndex, templates []QuestionTemplate) int {
+	issues := LintTemplates(idx, templates)
+	for _, issue := range issues {
+		fmt.Fprintln(out, issue)
+	}
+
+	if len(issues) == 0 {
+		fmt.Fprintf(out, "%d templates OK against %d games\n", len(templates), len(idx.Games))
+		return 0
+	}
+
+	fmt.Fprintf(out, "%d issues in %d templates\n", len(issues), len(templates))
+	return 1
+}
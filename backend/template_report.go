@@ -0,0 +1,138 @@
+package guesser
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// TemplateReport is how well each question splits a dataset, plus what
+// LintTemplates found. An option every game or no game matches is
+// degenerate: asking it tells the player nothing.
+type TemplateReport struct {
+	Dataset   string          `json:"dataset"`
+	Games     int             `json:"games"`
+	Templates []TemplateStats `json:"templates"`
+	Issues    []TemplateIssue `json:"issues"`
+}
+
+type TemplateStats struct {
+	ID       string                `json:"id"`
+	Category string                `json:"category"`
+	Options  []TemplateOptionStats `json:"options"`
+}
+
+// TemplateOptionStats is one option; Value is empty for yes/no templates.
+type TemplateOptionStats struct {
+	Value      string  `json:"value,omitempty"`
+	Matches    int     `json:"matches"`
+	Share      float64 `json:"share"`
+	Degenerate bool    `json:"degenerate,omitempty"`
+}
+
+// TemplateIssue is a TemplateLintIssue as JSON.
+type TemplateIssue struct {
+	TemplateID string `json:"templateId"`
+	Value      string `json:"value,omitempty"`
+	Problem    string `json:"problem"`
+}
+
+// BuildTemplateReport counts every option's matches in idx.
+func BuildTemplateReport(dataset string, idx GameIndex, templates []QuestionTemplate) TemplateReport {
+	report := TemplateReport{
+		Dataset:   dataset,
+		Games:     len(idx.AllGameIDs),
+		Templates: make([]TemplateStats, 0, len(templates)),
+		Issues:    []TemplateIssue{},
+	}
+
+	for _, t := range templates {
+		if !t.HasLogic() {
+			continue
+		}
+		stats := TemplateStats{ID: t.ID, Category: t.Category}
+		values := t.Values
+		if t.CheckString == nil || len(values) == 0 {
+			values = []string{""}
+		}
+		for _, v := range values {
+			n := countMatches(t, v, idx.AllGameIDs, idx)
+			opt := TemplateOptionStats{Value: v, Matches: n, Degenerate: n == 0 || n == report.Games}
+			if report.Games > 0 {
+				opt.Share = float64(n) / float64(report.Games)
+			}
+			stats.Options = append(stats.Options, opt)
+		}
+		report.Templates = append(report.Templates, stats)
+	}
+
+	for _, issue := range LintTemplates(idx, templates) {
+		report.Issues = append(report.Issues, TemplateIssue(issue))
+	}
+	return report
+}
+
+// strictTemplates makes checkTemplates fail on degenerate options.
+var strictTemplates bool
+
+// ConfigureTemplateChecks sets whether a dataset whose templates have
+// degenerate options is refused (strict) or only logged.
+func ConfigureTemplateChecks(strict bool) {
+	strictTemplates = strict
+}
+
+// checkTemplates logs what LintTemplates finds in a freshly indexed
+// dataset. In strict mode, degenerate options make it an error, so the
+// dataset is not served.
+func checkTemplates(dataset string, idx GameIndex, templates []QuestionTemplate) error {
+	report := BuildTemplateReport(dataset, idx, templates)
+	for _, issue := range report.Issues {
+		slog.Warn("template issue", "dataset", dataset, "template", issue.TemplateID, "value", issue.Value, "problem", issue.Problem)
+	}
+
+	degenerate := 0
+	for _, t := range report.Templates {
+		for _, opt := range t.Options {
+			if opt.Degenerate {
+				degenerate++
+			}
+		}
+	}
+	if strictTemplates && degenerate > 0 {
+		return fmt.Errorf("%d template options match no games or every game (see GET /api/v1/admin/templates)", degenerate)
+	}
+	return nil
+}
+
+// ---------------------------------
+// /api/v1/admin/templates   (GET, admin only, ?dataset=)
+// ---------------------------------
+
+func TemplateReportHandler(data *SnapshotHolder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		if !isAdmin(r) {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "admin credentials required")
+			return
+		}
+
+		datasetID := r.URL.Query().Get("dataset")
+		if !allowKeyDataset(w, r, datasetID) {
+			return
+		}
+		holder, ok := selectDataset(r, data, datasetID)
+		if !ok {
+			writeError(w, http.StatusNotFound, CodeUnknownDataset, "unknown dataset")
+			return
+		}
+		if datasetID == "" {
+			datasetID = defaultDatasetID
+		}
+
+		snap := holder.Current()
+		writeJSON(w, http.StatusOK, BuildTemplateReport(datasetID, snap.Index, snap.Templates))
+	})
+}
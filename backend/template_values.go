@@ -0,0 +1,179 @@
+package guesser
+
+import (
+	"log/slog"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// With -derive-values, option lists come from the dataset instead of from
+// DefaultTemplates: each option template takes the distinct values of its
+// Field, keeping those that split the games, so a catalog never offers
+// "Souls-like" when it has no such game, and gains values nobody listed.
+// Templates with DeriveValues set always work this way.
+
+// deriveValues is set by ConfigureTemplateValues.
+var deriveValues bool
+
+// ConfigureTemplateValues turns deriving template Values from each
+// dataset on or off. Call it before loading datasets.
+func ConfigureTemplateValues(derive bool) {
+	deriveValues = derive
+}
+
+// indexDataset precomputes games for a snapshot, first trimming the
+// optional templates to what the dataset has data for and deriving the
+// templates' Values where that is on.
+func indexDataset(name string, games []Game, templates []QuestionTemplate) (GameIndex, []QuestionTemplate) {
+	templates = trimOptionalTemplates(name, games, templates)
+	templates = deriveTemplateValues(games, templates, deriveValues)
+	return PrecomputeIndex(name, games, templates), templates
+}
+
+// trimOptionalTemplates drops the options of Optional templates that no
+// game in games matches, and the templates left with none. A yes/no
+// template is kept while some game answers yes.
+func trimOptionalTemplates(name string, games []Game, templates []QuestionTemplate) []QuestionTemplate {
+	out := make([]QuestionTemplate, 0, len(templates))
+	for _, t := range templates {
+		// Derived values already leave out what no game has.
+		if !t.Optional || !t.HasLogic() || t.DeriveValues {
+			out = append(out, t)
+			continue
+		}
+
+		if t.CheckString == nil {
+			if matchesSome(games, t.CheckBool) {
+				out = append(out, t)
+				continue
+			}
+		} else {
+			var values []string
+			for _, v := range t.Values {
+				if matchesSome(games, func(g *Game) bool { return t.CheckString(g, v) }) {
+					values = append(values, v)
+				}
+			}
+			if len(values) > 0 {
+				t.Values = values
+				out = append(out, t)
+				continue
+			}
+		}
+		slog.Info("leaving out question: no game has data for it", "dataset", name, "template", t.ID, "field", t.Field)
+	}
+	return out
+}
+
+func matchesSome(games []Game, check func(*Game) bool) bool {
+	for i := range games {
+		if check(&games[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// publishDataset indexes games and, unless checkTemplates rejects the
+// result, publishes it as data's new snapshot.
+func publishDataset(data *SnapshotHolder, name string, games []Game, templates []QuestionTemplate) (*Snapshot, error) {
+	idx, templates := indexDataset(name, games, templates)
+	if err := checkTemplates(name, idx, templates); err != nil {
+		return nil, err
+	}
+	return data.Publish(idx, templates), nil
+}
+
+// DeriveTemplateValues returns templates with each option template's
+// Values replaced by the values of its Field found in games, minus any
+// that every game or no game matches. Numbers (years) are sorted as
+// such. Otherwise values the template already listed keep their order
+// and come first, since they are often ordinal (age ratings), and the
+// rest follow most common first. Templates without a known Field, yes/no
+// templates and templates left with no useful value are unchanged, apart
+// from DeriveValues templates, which have nothing to fall back on and are
+// dropped.
+func DeriveTemplateValues(games []Game, templates []QuestionTemplate) []QuestionTemplate {
+	return deriveTemplateValues(games, templates, true)
+}
+
+// deriveTemplateValues derives the DeriveValues templates, or all of them.
+func deriveTemplateValues(games []Game, templates []QuestionTemplate, all bool) []QuestionTemplate {
+	out := make([]QuestionTemplate, 0, len(templates))
+	for _, t := range templates {
+		index, ok := gameFields[t.Field]
+		if t.CheckString == nil || !ok || !(all || t.DeriveValues) {
+			out = append(out, t)
+			continue
+		}
+
+		counts := map[string]int{}
+		for _, g := range games {
+			for _, v := range attributeStrings(reflect.ValueOf(g).FieldByIndex(index)) {
+				counts[v]++
+			}
+		}
+
+		var values []string
+		for v := range counts {
+			if splitsGames(t, v, games) {
+				values = append(values, v)
+			}
+		}
+		if len(values) > 0 {
+			sortDerivedValues(values, t.Values, counts)
+			t.Values = values
+		}
+		if len(t.Values) > 0 {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// splitsGames reports whether some but not all games match t with value.
+func splitsGames(t QuestionTemplate, value string, games []Game) bool {
+	yes := 0
+	for i := range games {
+		if t.CheckString(&games[i], value) {
+			yes++
+		}
+	}
+	return yes > 0 && yes < len(games)
+}
+
+func sortDerivedValues(values, listed []string, counts map[string]int) {
+	rank := make(map[string]int, len(listed))
+	for i, v := range listed {
+		rank[v] = i
+	}
+
+	numeric := true
+	for _, v := range values {
+		if _, err := strconv.Atoi(v); err != nil {
+			numeric = false
+			break
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		a, b := values[i], values[j]
+		ra, aListed := rank[a]
+		rb, bListed := rank[b]
+		switch {
+		case numeric:
+			na, _ := strconv.Atoi(a)
+			nb, _ := strconv.Atoi(b)
+			return na < nb
+		case aListed && bListed:
+			return ra < rb
+		case aListed != bListed:
+			return aListed
+		case counts[a] != counts[b]:
+			return counts[a] > counts[b]
+		default:
+			return a < b
+		}
+	})
+}
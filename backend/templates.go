@@ -11,9 +11,11 @@ func DefaultTemplates() []QuestionTemplate {
 		// -----------------------
 		{
 			ID:       "year_at_least",
+			Field:    "year",
 			Category: "Release Year",
+			Prompt:   "Was it released in %s or later?",
 			Values:   []string{"2010", "2012", "2015", "2018", "2020"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				year, err := strconv.Atoi(v)
 				if err != nil {
 					return false
@@ -23,9 +25,11 @@ func DefaultTemplates() []QuestionTemplate {
 		},
 		{
 			ID:       "year_at_most",
+			Field:    "year",
 			Category: "Release Year",
+			Prompt:   "Was it released in %s or earlier?",
 			Values:   []string{"2012", "2015", "2018", "2020"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				year, err := strconv.Atoi(v)
 				if err != nil {
 					return false
@@ -33,29 +37,44 @@ func DefaultTemplates() []QuestionTemplate {
 				return g.Year <= year
 			},
 		},
+		{
+			ID:       "year_between",
+			Field:    "year",
+			Category: "Release Year",
+			Prompt:   "Was it released in %s?",
+			Values:   []string{"2010–2014", "2015–2019", "2020–2024"},
+			CheckString: func(g *Game, v string) bool {
+				from, to, ok := parseYearRange(v)
+				return ok && g.Year >= from && g.Year <= to
+			},
+		},
 
 		// -----------------------
 		// Genre / main genre
 		// -----------------------
 		{
 			ID:       "main_genre",
+			Field:    "main_genre",
 			Category: "Main Genre",
+			Prompt:   "Is its main genre %s?",
 			Values: []string{
 				"Action", "RPG", "Shooter", "Indie", "Platformer", "Adventure",
 				"Strategy", "Racing", "Casual", "Simulation",
 			},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.MainGenre == v
 			},
 		},
 		{
 			ID:       "genre_includes",
+			Field:    "genres",
 			Category: "Genres",
+			Prompt:   "Is it tagged with the %s genre?",
 			Values: []string{
 				"Action", "RPG", "Shooter", "Indie", "Platformer", "Adventure",
 				"Strategy", "Racing", "Casual", "Simulation",
 			},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.Genres, v)
 			},
 		},
@@ -65,9 +84,11 @@ func DefaultTemplates() []QuestionTemplate {
 		// -----------------------
 		{
 			ID:       "platform_includes",
+			Field:    "platforms",
 			Category: "Platforms",
+			Prompt:   "Is it available on %s?",
 			Values:   []string{"PC", "PlayStation", "Xbox", "Nintendo Switch", "Mobile"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.Platforms, v)
 			},
 		},
@@ -77,25 +98,31 @@ func DefaultTemplates() []QuestionTemplate {
 		// -----------------------
 		{
 			ID:       "perspective",
+			Field:    "perspective",
 			Category: "Perspective",
+			Prompt:   "Is it played from a %s perspective?",
 			Values:   []string{"First Person", "Third Person", "Isometric", "Side", "Top-down", "Unknown"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.Perspective == v
 			},
 		},
 		{
 			ID:       "world_type",
+			Field:    "world_type",
 			Category: "World Type",
+			Prompt:   "Is its world %s?",
 			Values:   []string{"Open World", "Metroidvania", "Level-based", "Hub-based", "Linear / Mixed"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.WorldType == v
 			},
 		},
 		{
 			ID:       "camera",
+			Field:    "camera",
 			Category: "Camera",
+			Prompt:   "Does it use a %s camera?",
 			Values:   []string{"First Person", "Third Person", "Isometric", "Side", "Top-down", "Unknown"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.Camera == v
 			},
 		},
@@ -105,39 +132,47 @@ func DefaultTemplates() []QuestionTemplate {
 		// -----------------------
 		{
 			ID:       "theme",
+			Field:    "theme",
 			Category: "Theme",
+			Prompt:   "Is its theme %s?",
 			Values:   []string{"Fantasy", "Sci-Fi", "Horror", "Historical", "Post-Apocalyptic", "Modern / Other"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.Theme == v
 			},
 		},
 		{
 			ID:       "tone",
+			Field:    "tone",
 			Category: "Tone",
+			Prompt:   "Is its tone %s?",
 			Values:   []string{"Dark", "Wholesome", "Comedic", "Emotional", "Cute", "Neutral"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.Tone, v)
 			},
 		},
 		{
 			ID:       "mood",
+			Field:    "mood",
 			Category: "Mood",
+			Prompt:   "Is its mood %s?",
 			Values: []string{
 				"Atmospheric", "Story-Driven", "Psychological", "Relaxing",
 				"Mysterious", "Neutral",
 			},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.Mood, v)
 			},
 		},
 		{
 			ID:       "setting",
+			Field:    "setting",
 			Category: "Setting",
+			Prompt:   "Is it set in a %s setting?",
 			Values: []string{
 				"Urban", "Medieval", "Space / Sci-Fi", "Wilderness", "Island",
 				"Unspecified / Mixed",
 			},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.Setting, v)
 			},
 		},
@@ -147,32 +182,38 @@ func DefaultTemplates() []QuestionTemplate {
 		// -----------------------
 		{
 			ID:       "visual_style",
+			Field:    "visual_style",
 			Category: "Visual Style",
+			Prompt:   "Is its visual style %s?",
 			Values: []string{
 				"Pixel Art", "Retro", "Anime", "Realistic", "Cartoon", "Stylized",
 				"Low Poly", "Minimalist", "Unspecified",
 			},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.VisualStyle, v)
 			},
 		},
 		{
 			ID:       "combat_style",
+			Field:    "combat_style",
 			Category: "Combat Style",
+			Prompt:   "Does its combat involve %s?",
 			Values:   []string{"Melee", "Guns", "Magic", "Stealth", "Tactical", "Unspecified"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.CombatStyle, v)
 			},
 		},
 		{
 			ID:       "structure_feature",
+			Field:    "structure_features",
 			Category: "Structure Features",
+			Prompt:   "Does it feature %s?",
 			Values: []string{
 				"Crafting", "Survival", "Skill Tree", "Loot",
 				"Procedural Generation", "Base Building", "Branching Story",
 				"None / Standard",
 			},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.Structure, v)
 			},
 		},
@@ -182,73 +223,168 @@ func DefaultTemplates() []QuestionTemplate {
 		// -----------------------
 		{
 			ID:       "difficulty",
+			Field:    "difficulty",
 			Category: "Difficulty",
+			Prompt:   "Is its difficulty %s?",
 			Values:   []string{"Easy", "Normal / Unknown", "Hard", "Souls-like"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.Difficulty == v
 			},
 		},
 		{
 			ID:       "replayability",
+			Field:    "replayability",
 			Category: "Replayability",
+			Prompt:   "Is its replayability %s?",
 			Values:   []string{"Roguelike", "High", "Medium / Low / Unknown"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.Replayability == v
 			},
 		},
 
+		// -----------------------
+		// Developer
+		// -----------------------
+		// The dataset builder buckets studios and regions; the catch-all
+		// buckets match nothing, so they are never offered.
+		{
+			ID:           "developer_bucket",
+			Field:        "developer_bucket",
+			Category:     "Developer",
+			Prompt:       "Was it made by %s?",
+			DeriveValues: true,
+			Revealing:    true,
+			CheckString: func(g *Game, v string) bool {
+				return g.Developer == v && v != "Indie / Other"
+			},
+		},
+		{
+			ID:           "developer_region",
+			Field:        "developer_region",
+			Category:     "Developer Region",
+			Prompt:       "Was it developed in %s?",
+			DeriveValues: true,
+			CheckString: func(g *Game, v string) bool {
+				return g.DeveloperRegion == v && v != "Unknown / Various"
+			},
+		},
+
 		// -----------------------
 		// Multiplayer / co-op / online
 		// -----------------------
 		{
 			ID:       "is_multiplayer",
+			Field:    "multiplayer",
 			Category: "Multiplayer",
+			Prompt:   "Does it have multiplayer?",
 			Values:   nil, // pure yes/no
-			CheckBool: func(g Game) bool {
+			CheckBool: func(g *Game) bool {
 				return g.Multiplayer
 			},
 		},
 		{
 			ID:       "has_coop",
+			Field:    "co_op",
 			Category: "Co-op",
+			Prompt:   "Does it have co-op?",
 			Values:   nil,
-			CheckBool: func(g Game) bool {
+			CheckBool: func(g *Game) bool {
 				return g.Coop
 			},
 		},
 		{
 			ID:       "is_online_only",
+			Field:    "online_only",
 			Category: "Online-only",
+			Prompt:   "Is it online-only?",
 			Values:   nil,
-			CheckBool: func(g Game) bool {
+			CheckBool: func(g *Game) bool {
 				return g.OnlineOnly
 			},
 		},
 
+		{
+			ID:       "multiplayer_mode",
+			Field:    "multiplayer_mode",
+			Category: "Multiplayer Mode",
+			Prompt:   "Is its multiplayer %s?",
+			Values:   []string{"PvP", "PvE", "MMO", "Local Co-op", "None"},
+			CheckString: func(g *Game, v string) bool {
+				return multiplayerModeMatches(g.MultiplayerMode, v)
+			},
+		},
+
+		// -----------------------
+		// VR
+		// -----------------------
+		{
+			ID:       "is_vr",
+			Field:    "vr_support",
+			Category: "VR",
+			Prompt:   "Can it be played in VR?",
+			Values:   nil,
+			Optional: true,
+			CheckBool: func(g *Game) bool {
+				return g.VRSupport == "VR-only" || g.VRSupport == "VR-optional"
+			},
+		},
+		{
+			ID:       "is_vr_only",
+			Field:    "vr_support",
+			Category: "VR",
+			Prompt:   "Is it VR-only?",
+			Values:   nil,
+			Optional: true,
+			CheckBool: func(g *Game) bool {
+				return g.VRSupport == "VR-only"
+			},
+		},
+
+		// -----------------------
+		// Controls
+		// -----------------------
+		{
+			ID:       "controller_support",
+			Field:    "controller_support",
+			Category: "Controls",
+			Prompt:   "Can it be played with a controller?",
+			Values:   nil,
+			Optional: true,
+			CheckBool: func(g *Game) bool {
+				return g.ControllerSupport == "Full" || g.ControllerSupport == "Partial"
+			},
+		},
+
 		// -----------------------
 		// Age rating / ESRB / violence
 		// -----------------------
 		{
 			ID:       "age_at_least",
+			Field:    "age_rating",
 			Category: "Age Rating",
+			Prompt:   "Is it rated %s or higher?",
 			Values:   []string{"3+", "7+", "12+", "16+", "18+"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return ageRatingValue(g.AgeRating) >= ageRatingValue(v)
 			},
 		},
 		{
 			ID:       "esrb_category",
+			Field:    "esrb",
 			Category: "ESRB",
+			Prompt:   "Is it rated ESRB %s?",
 			Values:   []string{"E", "E10+", "T", "M", "Unknown"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.ESRB == v
 			},
 		},
 		{
 			ID:       "violence_level",
+			Field:    "violence_level",
 			Category: "Violence",
+			Prompt:   "Is its violence level %s?",
 			Values:   []string{"Low", "Medium", "High", "Unknown / Varies"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return g.Violence == v
 			},
 		},
@@ -258,21 +394,79 @@ func DefaultTemplates() []QuestionTemplate {
 		// -----------------------
 		{
 			ID:       "score_bucket_at_least",
+			Field:    "score_bucket",
 			Category: "Score",
+			Prompt:   "Did it score %s or better?",
 			Values:   []string{"60-69", "70-79", "80-89", "90+"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return scoreBucketRank(g.Score) >= scoreBucketRank(v)
 			},
 		},
 
+		// -----------------------
+		// Playtime
+		// -----------------------
+		{
+			ID:       "playtime_at_least",
+			Field:    "playtime_bucket",
+			Category: "Playtime",
+			Prompt:   "Is the main story %s or longer?",
+			Values:   []string{"5-20h", "20-60h", "60h+"},
+			Optional: true,
+			CheckString: func(g *Game, v string) bool {
+				rank := playtimeBucketRank(g.Playtime)
+				return rank > 0 && rank >= playtimeBucketRank(v)
+			},
+		},
+		{
+			ID:       "playtime_at_most",
+			Field:    "playtime_bucket",
+			Category: "Playtime",
+			Prompt:   "Is the main story %s or shorter?",
+			Values:   []string{"<5h", "5-20h", "20-60h"},
+			Optional: true,
+			CheckString: func(g *Game, v string) bool {
+				rank := playtimeBucketRank(g.Playtime)
+				return rank > 0 && rank <= playtimeBucketRank(v)
+			},
+		},
+
+		// -----------------------
+		// Launch price
+		// -----------------------
+		{
+			ID:       "price_bucket",
+			Field:    "price_bucket",
+			Category: "Price",
+			Prompt:   "Did it launch at a %s price?",
+			Values:   []string{"Free", "Budget", "Standard", "Premium"},
+			Optional: true,
+			CheckString: func(g *Game, v string) bool {
+				return g.Price == v
+			},
+		},
+		{
+			ID:       "full_price",
+			Field:    "price_bucket",
+			Category: "Price",
+			Prompt:   "Did it cost full price at launch?",
+			Values:   nil,
+			Optional: true,
+			CheckBool: func(g *Game) bool {
+				return g.Price == "Premium"
+			},
+		},
+
 		// -----------------------
 		// Monetization
 		// -----------------------
 		{
 			ID:       "monetization",
+			Field:    "monetization",
 			Category: "Monetization",
+			Prompt:   "Is its monetization %s?",
 			Values:   []string{"Paid / Standard", "Free to Play", "Microtransactions", "DLC-heavy", "Seasonal"},
-			CheckString: func(g Game, v string) bool {
+			CheckString: func(g *Game, v string) bool {
 				return stringSliceContains(g.Monetization, v)
 			},
 		},
@@ -282,9 +476,11 @@ func DefaultTemplates() []QuestionTemplate {
 		// -----------------------
 		{
 			ID:       "is_sequel",
+			Field:    "franchise_entry",
 			Category: "Franchise",
+			Prompt:   "Is it a sequel?",
 			Values:   nil,
-			CheckBool: func(g Game) bool {
+			CheckBool: func(g *Game) bool {
 				// Treat "Unknown" and empty as non-sequel.
 				return g.FranchiseEntry != "" &&
 					g.FranchiseEntry != "Unknown" &&
@@ -293,12 +489,25 @@ func DefaultTemplates() []QuestionTemplate {
 		},
 		{
 			ID:       "has_franchise",
+			Field:    "franchise",
 			Category: "Franchise",
+			Prompt:   "Is it part of a franchise?",
 			Values:   nil,
-			CheckBool: func(g Game) bool {
+			CheckBool: func(g *Game) bool {
 				return g.Franchise != "" && g.Franchise != "Standalone / Other"
 			},
 		},
+		{
+			ID:           "franchise_is",
+			Field:        "franchise",
+			Category:     "Franchise",
+			Prompt:       "Is it a %s game?",
+			DeriveValues: true,
+			Revealing:    true,
+			CheckString: func(g *Game, v string) bool {
+				return g.Franchise == v && v != "Standalone / Other"
+			},
+		},
 	}
 
 	return templates
@@ -0,0 +1,157 @@
+package guesser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// TenantConfig describes one isolated catalog hosted by this deployment,
+// e.g. a "board game guesser" next to the default video game one.
+type TenantConfig struct {
+	ID string `json:"id"`
+	// Hosts routes requests for these hostnames to the tenant.
+	Hosts []string `json:"hosts"`
+	// PathPrefix routes "/<prefix>/api/..." to the tenant, e.g. "/t/retro".
+	PathPrefix string `json:"pathPrefix"`
+	Dataset    string `json:"dataset"`
+	// Templates lists the template IDs to enable; empty enables all.
+	Templates []string `json:"templates"`
+	// Branding holds display strings (title, tagline, ...) for the UI.
+	Branding map[string]string `json:"branding"`
+}
+
+type tenantContextKey struct{}
+
+// tenantID returns the tenant a request was routed to, or "" for the
+// default catalog.
+func tenantID(r *http.Request) string {
+	id, _ := r.Context().Value(tenantContextKey{}).(string)
+	return id
+}
+
+func withTenant(id string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), tenantContextKey{}, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// LoadTenants reads a JSON array of TenantConfig.
+func LoadTenants(path string) ([]TenantConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenants []TenantConfig
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, t := range tenants {
+		switch {
+		case t.ID == "":
+			return nil, fmt.Errorf("%s: tenant #%d has no id", path, i)
+		case seen[t.ID]:
+			return nil, fmt.Errorf("%s: duplicate tenant id %q", path, t.ID)
+		case len(t.Hosts) == 0 && t.PathPrefix == "":
+			return nil, fmt.Errorf("%s: tenant %q needs hosts or a pathPrefix", path, t.ID)
+		case t.Dataset == "":
+			return nil, fmt.Errorf("%s: tenant %q has no dataset", path, t.ID)
+		}
+		seen[t.ID] = true
+	}
+
+	return tenants, nil
+}
+
+// selectTemplates keeps the templates whose IDs are listed, in the order
+// of all. An empty list keeps everything.
+func selectTemplates(all []QuestionTemplate, ids []string) ([]QuestionTemplate, error) {
+	if len(ids) == 0 {
+		return all, nil
+	}
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	selected := make([]QuestionTemplate, 0, len(ids))
+	for _, t := range all {
+		if wanted[t.ID] {
+			selected = append(selected, t)
+			delete(wanted, t.ID)
+		}
+	}
+
+	if len(wanted) > 0 {
+		missing := make([]string, 0, len(wanted))
+		for id := range wanted {
+			missing = append(missing, id)
+		}
+		return nil, fmt.Errorf("unknown template IDs: %s", strings.Join(missing, ", "))
+	}
+
+	return selected, nil
+}
+
+// MountTenants loads every tenant's dataset and mounts its own copy of the
+// API on router, by host and/or path prefix. Call it before the default
+// routes so tenant matches take precedence.
+func MountTenants(router *mux.Router, tenants []TenantConfig, templates []QuestionTemplate) error {
+	for _, t := range tenants {
+		games, err := LoadGames(t.Dataset)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", t.ID, err)
+		}
+
+		tenantTemplates, err := selectTemplates(templates, t.Templates)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", t.ID, err)
+		}
+
+		data := &SnapshotHolder{}
+		if _, err := publishDataset(data, t.ID, games, tenantTemplates); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.ID, err)
+		}
+		datasets.registerTenant(t.ID, data)
+
+		api := mux.NewRouter()
+		RegisterAPIRoutes(api, data, t.Branding)
+		handler := withTenant(t.ID, api)
+
+		for _, host := range t.Hosts {
+			router.Host(host).PathPrefix("/api/").Handler(handler)
+			router.Host(host).PathPrefix("/ws/").Handler(handler)
+		}
+		if prefix := strings.TrimRight(t.PathPrefix, "/"); prefix != "" {
+			router.PathPrefix(prefix + "/api/").Handler(http.StripPrefix(prefix, handler))
+			router.PathPrefix(prefix + "/ws/").Handler(http.StripPrefix(prefix, handler))
+		}
+	}
+
+	return nil
+}
+
+// BrandingHandler serves a tenant's display strings.
+func BrandingHandler(branding map[string]string) http.Handler {
+	if branding == nil {
+		branding = map[string]string{}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, branding)
+	})
+}
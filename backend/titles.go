@@ -0,0 +1,174 @@
+package guesser
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// normalizeTitle folds a game title down to lowercase letters and digits
+// separated by single spaces, so "DOOM (2016)" and "Doom 2016" compare
+// equal. Apostrophes are dropped rather than turned into spaces.
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	space := false
+
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r == '\'' || r == '’':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+
+	return b.String()
+}
+
+// minFuzzyTitleLength is the shortest normalized name a guess may
+// misspell: in "Doom" or "Limbo" one typo is already another word.
+const minFuzzyTitleLength = 8
+
+// titleVariants lists the normalized names a guess may be matched against:
+// the full title and every alias.
+func titleVariants(g *Game) []string {
+	variants := []string{normalizeTitle(g.Name)}
+	for _, alias := range g.Aliases {
+		variants = append(variants, normalizeTitle(alias))
+	}
+	return variants
+}
+
+// titlePrefix is g's normalized title without its subtitle ("grim
+// fandango" for "Grim Fandango: Remastered"), when idx has no other game
+// whose name it also begins: "The Legend of Zelda" names a series, not a
+// game. It is "" otherwise.
+func titlePrefix(idx GameIndex, g *Game) string {
+	for _, sep := range []string{":", " - ", " – "} {
+		i := strings.Index(g.Name, sep)
+		if i <= 0 {
+			continue
+		}
+		prefix := normalizeTitle(g.Name[:i])
+		if prefix == "" || idx.beginsOtherTitle(prefix, g.ID) {
+			return ""
+		}
+		return prefix
+	}
+	return ""
+}
+
+// beginsOtherTitle reports whether name is the title or an alias of a
+// game other than id, or their first words.
+func (idx GameIndex) beginsOtherTitle(name string, id int) bool {
+	i := sort.Search(len(idx.titles), func(i int) bool { return idx.titles[i].Key >= name })
+	for ; i < len(idx.titles) && strings.HasPrefix(idx.titles[i].Key, name); i++ {
+		k := idx.titles[i]
+		if k.ID != id && (len(k.Key) == len(name) || k.Key[len(name)] == ' ') {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTitle reports whether normalized is exactly one of g's names.
+func hasTitle(idx GameIndex, g *Game, normalized string) bool {
+	if normalized == "" {
+		return false
+	}
+	for _, v := range titleVariants(g) {
+		if v == normalized {
+			return true
+		}
+	}
+	return normalized == titlePrefix(idx, g)
+}
+
+// MatchesTitle reports whether a typed guess names g closely enough: after
+// normalizing, it must be one of g's names exactly, or be within
+// threshold of its title or an alias when that is long enough to
+// misspell. Numbers are never fuzzy, so "Far Cry 4" doesn't win a game of
+// Far Cry 5, nor "Dark Souls II" one of Dark Souls III.
+func MatchesTitle(idx GameIndex, guess string, g *Game, threshold float64) bool {
+	normalized := normalizeTitle(guess)
+	if hasTitle(idx, g, normalized) {
+		return true
+	}
+	if normalized == "" {
+		return false
+	}
+
+	numbers := titleNumbers(normalized)
+	for _, v := range titleVariants(g) {
+		if utf8.RuneCountInString(v) < minFuzzyTitleLength || !slices.Equal(numbers, titleNumbers(v)) {
+			continue
+		}
+		if titleSimilarity(normalized, v) >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// titleNumbers lists the words of a normalized title that number it:
+// those with a digit in them and roman numerals up to 39.
+func titleNumbers(normalized string) []string {
+	var numbers []string
+	for _, word := range strings.Fields(normalized) {
+		if strings.ContainsAny(word, "0123456789") || romanNumeral.MatchString(word) {
+			numbers = append(numbers, word)
+		}
+	}
+	return numbers
+}
+
+var romanNumeral = regexp.MustCompile(`^x{0,3}(ix|iv|v?i{0,3})$`)
+
+// titleSimilarity is 1 minus the edit distance scaled by the longer
+// title's length, so a single typo matters less in a long name.
+func titleSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	longest := utf8.RuneCountInString(a)
+	if n := utf8.RuneCountInString(b); n > longest {
+		longest = n
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein counts the single-rune insertions, deletions and
+// substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,94 @@
+package guesser
+
+import "testing"
+
+func TestMatchesTitle(t *testing.T) {
+	idx := NewGameIndex([]Game{
+		{ID: 1, Name: "Far Cry 5"},
+		{ID: 2, Name: "Far Cry 4"},
+		{ID: 3, Name: "Dark Souls III"},
+		{ID: 4, Name: "Dark Souls II"},
+		{ID: 5, Name: "The Legend of Zelda: Breath of the Wild", Aliases: []string{"BotW"}},
+		{ID: 6, Name: "The Legend of Zelda: Ocarina of Time"},
+		{ID: 7, Name: "Grim Fandango: Remastered"},
+		{ID: 8, Name: "Doom"},
+		{ID: 9, Name: "Doom: Eternal"},
+		{ID: 10, Name: "The Witcher 3: Wild Hunt"},
+		{ID: 11, Name: "Mega Man X"},
+		{ID: 12, Name: "Baldur's Gate 3"},
+	})
+
+	tests := []struct {
+		guess string
+		id    int
+		want  bool
+	}{
+		{"Far Cry 5", 1, true},
+		{"far cry 5", 1, true},
+		{"far cry 4", 1, false},
+		{"far cri 5", 1, true},
+		{"far cry", 1, false},
+		{"dark souls iii", 3, true},
+		{"dark souls ii", 3, false},
+		{"dark soul iii", 3, true},
+		{"dark souls", 3, false},
+		{"The Legend of Zelda", 5, false},
+		{"the legend of zelda", 6, false},
+		{"legend of zelda", 5, false},
+		{"botw", 5, true},
+		{"the legend of zelda breath of the widl", 5, true},
+		{"Grim Fandango", 7, true},
+		{"grim fandago", 7, false},
+		{"grim fandango remasterd", 7, true},
+		{"doom", 9, false},
+		{"doon", 8, false},
+		{"doom eternal", 9, true},
+		{"the witcher 3", 10, true},
+		{"the witcher 2", 10, false},
+		{"mega man x", 11, true},
+		{"mega man", 11, false},
+		{"baldurs gate 3", 12, true},
+		{"baldurs gate 2", 12, false},
+		{"", 1, false},
+	}
+	for _, tt := range tests {
+		if got := MatchesTitle(idx, tt.guess, idx.Games[tt.id], 0.85); got != tt.want {
+			t.Errorf("MatchesTitle(%q, %q) = %v, want %v", tt.guess, idx.Games[tt.id].Name, got, tt.want)
+		}
+	}
+}
+
+func TestTitleNumbers(t *testing.T) {
+	tests := []struct {
+		title string
+		want  int
+	}{
+		{"far cry 5", 1},
+		{"dark souls iii", 1},
+		{"nba 2k17", 1},
+		{"final fantasy vii", 1},
+		{"grand theft auto v", 1},
+		{"civilization", 0},
+		{"mix civ", 0},
+		{"the witcher 3 wild hunt", 1},
+	}
+	for _, tt := range tests {
+		if got := titleNumbers(tt.title); len(got) != tt.want {
+			t.Errorf("titleNumbers(%q) = %q, want %d numbers", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestFindGameByNameSkipsSeriesNames(t *testing.T) {
+	idx := NewGameIndex([]Game{
+		{ID: 1, Name: "The Legend of Zelda: Breath of the Wild"},
+		{ID: 2, Name: "The Legend of Zelda: Ocarina of Time"},
+		{ID: 3, Name: "Grim Fandango: Remastered"},
+	})
+	if g, ok := findGameByName(idx, "The Legend of Zelda"); ok {
+		t.Errorf("findGameByName(series name) = %q", g.Name)
+	}
+	if g, ok := findGameByName(idx, "grim fandango"); !ok || g.ID != 3 {
+		t.Errorf("findGameByName(\"grim fandango\") = %v, %v; want Grim Fandango: Remastered", g, ok)
+	}
+}
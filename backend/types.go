@@ -1,5 +1,12 @@
 package guesser
 
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
 // -----------------------------------------
 // Game structure loaded from games.json
 // -----------------------------------------
@@ -9,6 +16,9 @@ type Game struct {
 	Name string `json:"name"`
 	Year int    `json:"year"`
 
+	// Other names players know the game by, e.g. "GTA V".
+	Aliases []string `json:"aliases"`
+
 	Platforms []string `json:"platforms"`
 	Genres    []string `json:"genres"`
 	MainGenre string   `json:"main_genre"`
@@ -43,10 +53,30 @@ type Game struct {
 	OnlineOnly      bool   `json:"online_only"`
 	MultiplayerMode string `json:"multiplayer_mode"`
 
+	// VRSupport is "VR-only", "VR-optional" or "None".
+	VRSupport string `json:"vr_support"`
+
+	// ControllerSupport is Steam's "Full", "Partial", "None", or
+	// "Unknown" for games the Steam importer hasn't matched.
+	ControllerSupport string `json:"controller_support"`
+
 	// Optional: filled by builder if you cache RAWG images.
 	ImageURL string `json:"image_url"`
 
 	Score string `json:"score_bucket"`
+
+	// Playtime is HowLongToBeat's main-story time, bucketed: "<5h",
+	// "5-20h", "20-60h", "60h+" or "Unknown".
+	Playtime string `json:"playtime_bucket"`
+
+	// Price is the launch price tier: "Free", "Budget" (under $20),
+	// "Standard" (under $50), "Premium" or "Unknown". Unlike Monetization
+	// it says nothing about what's sold after launch.
+	Price string `json:"price_bucket"`
+
+	// Popularity is how many players rated the game on RAWG (or IGDB),
+	// 0 when unknown. Secrets are drawn weighted by it.
+	Popularity int `json:"popularity"`
 }
 
 // -----------------------------------------
@@ -54,25 +84,133 @@ type Game struct {
 // -----------------------------------------
 
 type GameIndex struct {
-	Games      map[int]Game
+	Games      map[int]*Game
 	AllGameIDs []int
+
+	// ByName lists every ID in catalog order: normalized title, then ID.
+	ByName []int
+
+	// Inverted indexes for pool and catalog filters, sorted by ID and keyed
+	// by lower-cased value. ByYear lists every ID by year, then ID.
+	ByGenre     map[string][]int
+	ByMainGenre map[string][]int
+	ByPlatform  map[string][]int
+	ByYear      []int
+
+	// ByPopularity lists every ID by Popularity, most popular first, then
+	// ID; difficulty tiers cut it.
+	ByPopularity []int
+
+	// titles backs SuggestGames: every normalized title and alias, sorted.
+	titles []titleKey
+
+	// Answers and Attributes are filled in by PrecomputeIndex; code must
+	// still work without them.
+	Answers    *AnswerMatrix
+	Attributes *AttributeIndex
 }
 
 func NewGameIndex(list []Game) GameIndex {
-	gameMap := make(map[int]Game)
-	ids := make([]int, 0, len(list))
+	// One copy of the list backs every *Game, so the index neither shares
+	// the caller's slice nor allocates per game.
+	games := append([]Game(nil), list...)
+	gameMap := make(map[int]*Game, len(games))
+	ids := make([]int, 0, len(games))
 
-	for _, g := range list {
+	for i := range games {
+		g := &games[i]
 		gameMap[g.ID] = g
 		ids = append(ids, g.ID)
 	}
 
+	byName := make([]int, len(ids))
+	copy(byName, ids)
+	sort.Slice(byName, func(i, j int) bool {
+		return catalogLess(gameMap[byName[i]], gameMap[byName[j]])
+	})
+
+	byGenre := map[string][]int{}
+	byMainGenre := map[string][]int{}
+	byPlatform := map[string][]int{}
+	for _, id := range ids {
+		g := gameMap[id]
+		for _, genre := range g.Genres {
+			addToIndex(byGenre, genre, id)
+		}
+		addToIndex(byMainGenre, g.MainGenre, id)
+		for _, p := range g.Platforms {
+			addToIndex(byPlatform, p, id)
+		}
+	}
+	for _, index := range []map[string][]int{byGenre, byMainGenre, byPlatform} {
+		for _, list := range index {
+			slices.Sort(list)
+		}
+	}
+
+	byYear := make([]int, len(ids))
+	copy(byYear, ids)
+	sort.Slice(byYear, func(i, j int) bool {
+		a, b := gameMap[byYear[i]], gameMap[byYear[j]]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		return a.ID < b.ID
+	})
+
+	byPopularity := make([]int, len(ids))
+	copy(byPopularity, ids)
+	sort.Slice(byPopularity, func(i, j int) bool {
+		a, b := gameMap[byPopularity[i]], gameMap[byPopularity[j]]
+		if a.Popularity != b.Popularity {
+			return a.Popularity > b.Popularity
+		}
+		return a.ID < b.ID
+	})
+
 	return GameIndex{
-		Games:      gameMap,
-		AllGameIDs: ids,
+		Games:        gameMap,
+		AllGameIDs:   ids,
+		ByName:       byName,
+		ByGenre:      byGenre,
+		ByMainGenre:  byMainGenre,
+		ByPlatform:   byPlatform,
+		ByYear:       byYear,
+		ByPopularity: byPopularity,
+		titles:       buildTitleKeys(gameMap, ids),
 	}
 }
 
+// addToIndex appends id under value's lower-cased key, once: a game may
+// list the same platform bucket twice.
+func addToIndex(index map[string][]int, value string, id int) {
+	if value == "" {
+		return
+	}
+	key := strings.ToLower(value)
+	list := index[key]
+	if len(list) > 0 && list[len(list)-1] == id {
+		return
+	}
+	index[key] = append(list, id)
+}
+
+// YearRange returns the IDs released from..to (0 = open-ended), by year.
+func (idx GameIndex) YearRange(from, to int) []int {
+	lo := 0
+	if from != 0 {
+		lo = sort.Search(len(idx.ByYear), func(i int) bool { return idx.Games[idx.ByYear[i]].Year >= from })
+	}
+	hi := len(idx.ByYear)
+	if to != 0 {
+		hi = sort.Search(len(idx.ByYear), func(i int) bool { return idx.Games[idx.ByYear[i]].Year > to })
+	}
+	if lo >= hi {
+		return nil
+	}
+	return idx.ByYear[lo:hi]
+}
+
 // -----------------------------------------
 // GameSummary: minimal info sent to frontend
 // -----------------------------------------
@@ -83,6 +221,14 @@ type GameSummary struct {
 	Year int    `json:"year"`
 }
 
+func summarize(g *Game) GameSummary {
+	return GameSummary{
+		ID:   g.ID,
+		Name: g.Name,
+		Year: g.Year,
+	}
+}
+
 // -----------------------------------------
 // Question types for the frontend
 // -----------------------------------------
@@ -94,26 +240,226 @@ type QuestionTypeDef struct {
 	Values   []string `json:"values"`
 }
 
+// QuestionOption is one askable value of a template together with how it
+// would split the current candidates.
+type QuestionOption struct {
+	Value    string `json:"value"`
+	YesCount int    `json:"yesCount"`
+	NoCount  int    `json:"noCount"`
+	// Power is 1 for a perfect 50/50 split and 0 when every candidate
+	// would give the same answer.
+	Power float64 `json:"power"`
+	// Asked is set in split previews for options already asked.
+	Asked bool `json:"asked,omitempty"`
+}
+
+// RankedQuestion is one askable option scored by how much it would narrow
+// the candidates.
+type RankedQuestion struct {
+	QuestionTypeID string `json:"questionTypeId"`
+	Category       string `json:"category"`
+	Option         string `json:"option"`
+	YesCount       int    `json:"yesCount"`
+	NoCount        int    `json:"noCount"`
+	// Gain is the expected information in bits: 1 for a perfect 50/50
+	// split.
+	Gain float64 `json:"gain"`
+}
+
+// SessionQuestion is a template with the options still unasked in a session.
+type SessionQuestion struct {
+	ID       string           `json:"id"`
+	Category string           `json:"category"`
+	Options  []QuestionOption `json:"options"`
+}
+
 // QuestionTemplate holds server-side logic for each question.
 type QuestionTemplate struct {
 	ID       string
 	Category string
 	Values   []string
 
+	// Field is the JSON name of the Game field the check reads, so tooling
+	// can cross-check templates against a dataset.
+	Field string
+
+	// Prompt is the question as shown to the player; "%s" is replaced by
+	// the chosen value.
+	Prompt string
+
+	// DeriveValues takes Values from each dataset's Field, as
+	// -derive-values does for every template. Such a template is left
+	// out of datasets where no value splits the games.
+	DeriveValues bool
+
+	// Revealing templates narrow the candidates so far for a single
+	// question that hard sessions leave them out.
+	Revealing bool
+
+	// Optional templates read a Field the dataset may not have at all
+	// (one the build script fills in from an extra source). Their
+	// options that match no game are dropped, and the template with
+	// them when none is left.
+	Optional bool
+
 	// If non-nil, the question expects a string value (e.g. "2015", "RPG").
-	CheckString func(game Game, value string) bool
+	CheckString func(game *Game, value string) bool
 
 	// If non-nil, the question is a pure yes/no predicate on the game.
-	CheckBool func(game Game) bool
+	CheckBool func(game *Game) bool
+}
+
+// HasLogic reports whether the template can answer anything at all.
+func (t QuestionTemplate) HasLogic() bool {
+	return t.CheckString != nil || t.CheckBool != nil
+}
+
+// Text renders the human-readable question for value.
+func (t QuestionTemplate) Text(value string) string {
+	if t.Prompt == "" {
+		if value == "" {
+			return t.Category + "?"
+		}
+		return t.Category + ": " + value + "?"
+	}
+	if !strings.Contains(t.Prompt, "%s") {
+		return t.Prompt
+	}
+	return strings.Replace(t.Prompt, "%s", value, 1)
+}
+
+// NegatedText is Text asked the other way round: "Is it not an RPG?".
+// Prompts that don't open with a verb get a "Not:" prefix instead.
+func (t QuestionTemplate) NegatedText(value string) string {
+	text := t.Text(value)
+	words := strings.SplitN(text, " ", 3)
+	if len(words) < 3 || !negatableVerbs[strings.ToLower(words[0])] {
+		return "Not: " + text
+	}
+	// "Is it ..." becomes "Is it not ...", "Is the story ..." "Is not the
+	// story ...".
+	if strings.EqualFold(words[1], "it") {
+		return words[0] + " " + words[1] + " not " + words[2]
+	}
+	return words[0] + " not " + words[1] + " " + words[2]
+}
+
+var negatableVerbs = map[string]bool{
+	"is": true, "was": true, "are": true, "were": true, "does": true,
+	"did": true, "do": true, "can": true, "has": true, "have": true,
+}
+
+// Matches answers the question with the given option for one game.
+func (t QuestionTemplate) Matches(game *Game, value string) bool {
+	if t.CheckString != nil {
+		return t.CheckString(game, value)
+	}
+	if t.CheckBool != nil {
+		return t.CheckBool(game)
+	}
+	return false
 }
 
 // -----------------------------------------
 // Session State
 // -----------------------------------------
 
+// SessionStatus describes where a session is in its lifecycle.
+type SessionStatus string
+
+const (
+	StatusActive SessionStatus = "active"
+	// StatusFinalGuess means only one candidate is left: further questions
+	// cannot tell the player anything, so only a guess is accepted.
+	StatusFinalGuess SessionStatus = "final_guess"
+	StatusFinished   SessionStatus = "finished"
+)
+
+// Outcome says how a finished session ended.
+type Outcome string
+
+const (
+	OutcomeWon    Outcome = "won"
+	OutcomeLost   Outcome = "lost"
+	OutcomeGaveUp Outcome = "gave_up"
+)
+
+// AskedQuestion records one question put to the engine and its outcome.
+type AskedQuestion struct {
+	QuestionTypeID  string `json:"questionTypeId"`
+	Option          string `json:"option"`
+	Answer          bool   `json:"answer"`
+	CandidatesAfter int    `json:"candidatesAfter"`
+	// Negated questions were asked the other way round; Answer is the
+	// answer to the question as asked.
+	Negated bool `json:"negated,omitempty"`
+}
+
+// GuessRecord records one guess made in a session.
+type GuessRecord struct {
+	Guess   string `json:"guess"`
+	GameID  int    `json:"gameId,omitempty"`
+	Correct bool   `json:"correct"`
+	// Similarity to the secret; only meaningful in hot/cold mode.
+	Similarity float64 `json:"similarity,omitempty"`
+}
+
 // SessionState tracks which candidates are still possible and which
 // game is secretly the target.
 type SessionState struct {
-	RemainingIDs []int `json:"remaining"`
-	SecretID     int   `json:"secret"`
+	Mode         SessionMode     `json:"mode"`
+	RemainingIDs []int           `json:"remaining"`
+	SecretID     int             `json:"secret"`
+	Status       SessionStatus   `json:"status"`
+	Asked        []AskedQuestion `json:"asked"`
+	Guesses      []GuessRecord   `json:"guesses"`
+
+	// PoolSize is the candidate count before any question was asked.
+	PoolSize int `json:"poolSize"`
+	// Timeline is the candidate count at the start and after each
+	// question, e.g. [500 120 13 2].
+	Timeline   []int     `json:"timeline"`
+	Outcome    Outcome   `json:"outcome,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+
+	// Pending is the reverse-mode question (or guess) the solver is
+	// waiting on the player to answer.
+	Pending *PendingQuestion `json:"pending,omitempty"`
+
+	// Tolerance is how many answers a candidate may contradict before it
+	// is eliminated; 0 drops it on the first. Penalties counts each
+	// surviving candidate's contradictions so far.
+	Tolerance int         `json:"tolerance,omitempty"`
+	Penalties map[int]int `json:"penalties,omitempty"`
+
+	// Difficulty is the tier the secret was drawn from; hard sessions
+	// also ask fewer kinds of questions (see sessionTemplates).
+	Difficulty SessionDifficulty `json:"difficulty,omitempty"`
+
+	// Hints are the hints taken, in order (see handleHint).
+	Hints []HintKind `json:"hints,omitempty"`
+}
+
+// PendingQuestion is what the solver asked in reverse mode. GuessID is
+// set instead of a question when the solver names a game.
+type PendingQuestion struct {
+	QuestionTypeID string `json:"questionTypeId,omitempty"`
+	Option         string `json:"option,omitempty"`
+	GuessID        int    `json:"guessId,omitempty"`
+}
+
+// WasAsked reports whether this exact question has already been asked.
+func (s SessionState) WasAsked(questionTypeID, option string) bool {
+	_, ok := s.askedQuestion(questionTypeID, option)
+	return ok
+}
+
+func (s SessionState) askedQuestion(questionTypeID, option string) (AskedQuestion, bool) {
+	for _, q := range s.Asked {
+		if q.QuestionTypeID == questionTypeID && strings.EqualFold(q.Option, option) {
+			return q, true
+		}
+	}
+	return AskedQuestion{}, false
 }
@@ -0,0 +1,272 @@
+package guesser
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GameFinishedEvent is the payload POSTed to webhooks when a game ends.
+type GameFinishedEvent struct {
+	Event           string      `json:"event"`
+	SessionID       string      `json:"sessionId"`
+	Mode            SessionMode `json:"mode"`
+	Outcome         Outcome     `json:"outcome"`
+	QuestionsAsked  int         `json:"questionsAsked"`
+	Guesses         int         `json:"guesses"`
+	DurationSeconds float64     `json:"durationSeconds"`
+	Score           int         `json:"score"`
+	Secret          GameSummary `json:"secret"`
+	FinishedAt      time.Time   `json:"finishedAt"`
+}
+
+// WebhookDelivery is the log entry for one delivery attempt sequence.
+type WebhookDelivery struct {
+	URL         string    `json:"url"`
+	Event       string    `json:"event"`
+	SessionID   string    `json:"sessionId"`
+	Attempts    int       `json:"attempts"`
+	StatusCode  int       `json:"statusCode"`
+	Error       string    `json:"error,omitempty"`
+	DeliveredAt time.Time `json:"deliveredAt"`
+}
+
+const (
+	webhookSignatureHeader = "X-Guesser-Signature"
+	webhookTimestampHeader = "X-Guesser-Timestamp"
+	webhookEventHeader     = "X-Guesser-Event"
+	webhookMaxAttempts     = 4
+	webhookLogSize         = 100
+)
+
+type webhookJob struct {
+	url       string
+	event     string
+	sessionID string
+	body      []byte
+}
+
+// WebhookDispatcher delivers events in the background so a slow
+// subscriber never delays the player's response.
+type WebhookDispatcher struct {
+	urls   []string
+	secret []byte
+	client *http.Client
+	jobs   chan webhookJob
+
+	// backoff is the wait before the first retry; it doubles each time.
+	backoff time.Duration
+
+	mu         sync.Mutex
+	deliveries []WebhookDelivery
+}
+
+// NewWebhookDispatcher starts a dispatcher with one delivery worker.
+// Payloads are signed with HMAC-SHA256 using secret over the delivery's
+// X-Guesser-Timestamp (unix seconds), a ".", and the raw body, so a
+// receiver that rejects stale timestamps can't be sent a captured
+// delivery again.
+func NewWebhookDispatcher(urls []string, secret string) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		urls:    urls,
+		secret:  []byte(secret),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		jobs:    make(chan webhookJob, 256),
+		backoff: time.Second,
+	}
+	go d.run()
+	return d
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func (d *WebhookDispatcher) Sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, d.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publish queues event for every configured URL. If the queue is full the
+// event is dropped and logged rather than blocking the caller.
+func (d *WebhookDispatcher) Publish(eventName, sessionID string, event any) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("webhook: encode", "event", eventName, "err", err)
+		return
+	}
+
+	for _, url := range d.urls {
+		job := webhookJob{url: url, event: eventName, sessionID: sessionID, body: body}
+		select {
+		case d.jobs <- job:
+		default:
+			slog.Warn("webhook: queue full, dropping event", "event", eventName, "url", url)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) run() {
+	for job := range d.jobs {
+		d.record(d.deliver(job))
+	}
+}
+
+// deliver POSTs one job, retrying network errors and 5xx/429 responses
+// with exponential backoff.
+func (d *WebhookDispatcher) deliver(job webhookJob) WebhookDelivery {
+	delivery := WebhookDelivery{URL: job.url, Event: job.event, SessionID: job.sessionID}
+	wait := d.backoff
+
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		delivery.Attempts = attempt
+
+		status, err := d.post(job)
+		delivery.StatusCode = status
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+
+		retryable := err != nil || status == http.StatusTooManyRequests || status >= 500
+		if !retryable {
+			break
+		}
+		if attempt < webhookMaxAttempts {
+			time.Sleep(wait)
+			wait *= 2
+		}
+	}
+
+	delivery.DeliveredAt = time.Now()
+	if delivery.Error == "" && delivery.StatusCode >= 300 {
+		delivery.Error = fmt.Sprintf("HTTP %d", delivery.StatusCode)
+	}
+
+	if delivery.Error != "" {
+		slog.Warn("webhook: delivery failed", "event", job.event, "url", job.url,
+			"attempts", delivery.Attempts, "err", delivery.Error)
+	} else {
+		slog.Info("webhook: delivered", "event", job.event, "url", job.url, "status", delivery.StatusCode)
+	}
+
+	return delivery
+}
+
+func (d *WebhookDispatcher) post(job webhookJob) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, job.url, bytes.NewReader(job.body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, job.event)
+	// Stamped per attempt, so retries are fresh too.
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	req.Header.Set(webhookSignatureHeader, d.Sign(timestamp, job.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+func (d *WebhookDispatcher) record(delivery WebhookDelivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deliveries = append(d.deliveries, delivery)
+	if len(d.deliveries) > webhookLogSize {
+		d.deliveries = d.deliveries[len(d.deliveries)-webhookLogSize:]
+	}
+}
+
+// Deliveries returns the most recent delivery log, newest last.
+func (d *WebhookDispatcher) Deliveries() []WebhookDelivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]WebhookDelivery, len(d.deliveries))
+	copy(out, d.deliveries)
+	return out
+}
+
+// global dispatcher; nil when no webhooks are configured
+var webhooks *WebhookDispatcher
+
+// ConfigureWebhooks enables game-completion webhooks for urls, signed
+// with secret.
+func ConfigureWebhooks(urls []string, secret string) error {
+	cleaned := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			cleaned = append(cleaned, u)
+		}
+	}
+	urls = cleaned
+
+	if len(urls) == 0 {
+		webhooks = nil
+		return nil
+	}
+	if len(secret) < 16 {
+		return errors.New("secret must be at least 16 characters")
+	}
+	webhooks = NewWebhookDispatcher(urls, secret)
+	return nil
+}
+
+// notifyGameFinished publishes the game.finished event for a session.
+func notifyGameFinished(session *Session, recap Recap) {
+	if webhooks == nil {
+		return
+	}
+
+	webhooks.Publish("game.finished", session.ID, GameFinishedEvent{
+		Event:           "game.finished",
+		SessionID:       session.ID,
+		Mode:            recap.Mode,
+		Outcome:         recap.Outcome,
+		QuestionsAsked:  len(recap.Questions),
+		Guesses:         len(recap.Guesses),
+		DurationSeconds: recap.DurationSeconds,
+		Score:           recap.Score,
+		Secret:          summarize(&recap.Secret),
+		FinishedAt:      session.State.FinishedAt,
+	})
+}
+
+// ---------------------------------
+// /api/v1/admin/webhooks/deliveries   (GET, admin only)
+// ---------------------------------
+
+func WebhookDeliveriesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		if !isAdmin(r) {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "admin credentials required")
+			return
+		}
+
+		deliveries := []WebhookDelivery{}
+		if webhooks != nil {
+			deliveries = webhooks.Deliveries()
+		}
+		writeJSON(w, http.StatusOK, deliveries)
+	})
+}
@@ -4,24 +4,29 @@ build_games.py
 
 Generate an enriched games.json dataset for the Guess-The-Game project.
 
-- Fetches popular games (2010–2024) from the RAWG API.
+- Fetches popular games (2010–2024) from the RAWG API, or with
+  `--source igdb` from IGDB, whose themes, player perspectives, game
+  modes and franchises map more directly onto our fields.
 - Filters out niche / low-visibility games.
 - Derives a rich set of attributes from RAWG genres/tags/devs for
   better yes/no question variety.
+- Looks up main-story playtimes on HowLongToBeat (needs the optional
+  `howlongtobeatpy` package; without it every playtime is "Unknown").
+- Looks up launch prices on the Steam store.
 
 Output: games.json (around 500 sampled games)
 """
 
 from __future__ import annotations
 
+import argparse
 import json
-import os
-import time
 import re
+from datetime import datetime, timezone
 from dataclasses import dataclass, asdict
 from typing import List, Dict, Any, Optional
 
-import requests
+from credentials import client_for, load_credentials
 
 
 # ------------------------------------------------------------
@@ -66,8 +71,15 @@ class Game:
     co_op: bool
     online_only: bool
     multiplayer_mode: str     # Singleplayer / Online Co-op / MMO / Battle Royale / etc.
+    vr_support: str           # VR-only / VR-optional / None
+    controller_support: str   # Full / Partial / None / Unknown (see steam_import.py)
 
     score_bucket: str         # 90+ / 80-89 / 70-79 / 60-69 / <60 / Unknown
+    playtime_bucket: str      # <5h / 5-20h / 20-60h / 60h+ / Unknown
+    price_bucket: str         # Free / Budget / Standard / Premium / Unknown
+    popularity: int           # RAWG ratings count (IGDB: total rating count)
+
+    aliases: List[str]        # Other names players type: "GTA V", "Skyrim", etc.
 
 
 # ------------------------------------------------------------
@@ -84,6 +96,8 @@ PLATFORM_MAP: Dict[str, str] = {
     "Xbox 360": "Xbox",
     "Xbox One": "Xbox",
     "Xbox Series S/X": "Xbox",
+    "Xbox Series X|S": "Xbox",
+    "PC (Microsoft Windows)": "PC",
     "Android": "Mobile",
     "iOS": "Mobile",
 }
@@ -140,6 +154,41 @@ def bucket_score(score: Optional[float]) -> str:
     return "<60"
 
 
+def bucket_playtime(hours: Optional[float]) -> str:
+    """
+    Place a HowLongToBeat main-story time (hours) into a playtime bucket.
+    """
+    if hours is None or hours <= 0:
+        return "Unknown"
+
+    if hours < 5:
+        return "<5h"
+    if hours < 20:
+        return "5-20h"
+    if hours < 60:
+        return "20-60h"
+    return "60h+"
+
+
+def bucket_price(monetization: List[str], usd: Optional[float]) -> str:
+    """
+    Place a launch price (US dollars) into a price bucket. Free-to-play
+    games are "Free" whatever the store says.
+    """
+    if "Free to Play" in monetization:
+        return "Free"
+    if usd is None:
+        return "Unknown"
+
+    if usd == 0:
+        return "Free"
+    if usd < 20.0:
+        return "Budget"
+    if usd < 50.0:
+        return "Standard"
+    return "Premium"
+
+
 def to_lower_list(items: List[str]) -> List[str]:
     lowered: List[str] = []
     for item in items:
@@ -475,6 +524,43 @@ def detect_franchise(name: str) -> str:
     return "Standalone / Other"
 
 
+# Names players commonly use that can't be derived from the title.
+ALIAS_OVERRIDES: Dict[str, List[str]] = {
+    "Grand Theft Auto V": ["GTA 5"],
+    "The Elder Scrolls V: Skyrim": ["Skyrim"],
+    "The Witcher 3: Wild Hunt": ["Witcher 3"],
+    "Counter-Strike: Global Offensive": ["CS:GO", "CSGO"],
+    "PlayerUnknown's Battlegrounds": ["PUBG"],
+    "Red Dead Redemption 2": ["RDR2"],
+    "Tom Clancy's Rainbow Six Siege": ["Rainbow Six Siege", "R6 Siege"],
+}
+
+
+def derive_aliases(name: str) -> List[str]:
+    """
+    Acronym aliases for numbered multi-word titles ("Grand Theft Auto V"
+    -> "GTA V"), plus any manual overrides. The backend already ignores
+    case, punctuation and small typos, so only genuinely different names
+    belong here.
+    """
+    aliases: List[str] = list(ALIAS_OVERRIDES.get(name, []))
+
+    main_title: str = name.split(":")[0].strip()
+    words: List[str] = main_title.split()
+    if len(words) >= 4:
+        last: str = words[-1]
+        if TRAILING_NUMBER_PATTERN.fullmatch(last) is not None or ROMAN_NUMERAL_PATTERN.match(last) is not None:
+            acronym: str = "".join(w[0] for w in words[:-1] if w[0].isalnum()).upper()
+            if len(acronym) >= 3:
+                aliases.append(f"{acronym} {last}")
+
+    result: List[str] = []
+    for alias in aliases:
+        if alias != name and alias not in result:
+            result.append(alias)
+    return result
+
+
 ROMAN_NUMERAL_PATTERN = re.compile(r"^([ivx]+)$", re.IGNORECASE)
 TRAILING_NUMBER_PATTERN = re.compile(r"(\d+)$")
 
@@ -572,6 +658,18 @@ def classify_multiplayer_mode(multiplayer: bool, co_op: bool, online_only: bool,
     return "Unknown"
 
 
+def classify_vr_support(tags: List[str]) -> str:
+    # Compare whole tags: "vr" is a substring of far too many words.
+    t: List[str] = to_lower_list(tags)
+
+    if "vr only" in t or "vr-only" in t:
+        return "VR-only"
+    if "vr" in t or "vr support" in t or "vr supported" in t:
+        return "VR-optional"
+
+    return "None"
+
+
 def classify_monetization(tags: List[str]) -> List[str]:
     t: List[str] = to_lower_list(tags)
     monetization: List[str] = []
@@ -603,12 +701,8 @@ def load_raw_games_from_api() -> List[Dict[str, Any]]:
     """
     Fetch multiple pages of games from RAWG, filtered by dates and popularity ordering.
     """
-    api_key: Optional[str] = os.getenv("RAWG_API_KEY")
-    if api_key is None or api_key == "":
-        raise RuntimeError(
-            "RAWG_API_KEY environment variable not set. "
-            "Export RAWG_API_KEY before running this script."
-        )
+    api_key: str = load_credentials("rawg")["api_key"]
+    client = client_for("rawg")
 
     raw_games: List[Dict[str, Any]] = []
 
@@ -629,7 +723,7 @@ def load_raw_games_from_api() -> List[Dict[str, Any]]:
         }
 
         print(f"Fetching RAWG page {page}/{max_pages}...")
-        resp = requests.get(RAWG_BASE_URL, params=params, timeout=15)
+        resp = client.get(RAWG_BASE_URL, params=params)
         if resp.status_code != 200:
             print(f"WARNING: RAWG request failed with status {resp.status_code}: {resp.text[:200]}")
             break
@@ -647,17 +741,337 @@ def load_raw_games_from_api() -> List[Dict[str, Any]]:
         for r in results:
             raw_games.append(r)
 
-        time.sleep(0.2)
-
     print(f"Total raw games fetched from RAWG: {len(raw_games)}")
     return raw_games
 
 
+# ------------------------------------------------------------
+# 4b. HowLongToBeat lookup
+# ------------------------------------------------------------
+
+# Matches below this name similarity (0-1) are more often a different game.
+HLTB_MIN_SIMILARITY: float = 0.8
+
+
+def lookup_main_story_hours(name: str) -> Optional[float]:
+    """
+    Main-story hours for the closest HowLongToBeat match to `name`, or None
+    when howlongtobeatpy isn't installed or nothing close enough is found.
+    """
+    try:
+        from howlongtobeatpy import HowLongToBeat  # type: ignore
+    except ImportError:
+        return None
+
+    try:
+        results = HowLongToBeat(HLTB_MIN_SIMILARITY).search(name)
+    except Exception as exc:
+        print(f"WARNING: HowLongToBeat lookup for {name!r} failed: {exc}")
+        return None
+
+    if results is None or len(results) == 0:
+        return None
+
+    best = max(results, key=lambda r: r.similarity)
+    hours: Any = best.main_story
+    if hours is None:
+        return None
+    return float(hours)
+
+
+# ------------------------------------------------------------
+# 4c. Steam store price lookup
+# ------------------------------------------------------------
+
+STEAM_STORE_SEARCH_URL: str = "https://store.steampowered.com/api/storesearch/"
+
+
+def lookup_steam_price_usd(name: str) -> Optional[float]:
+    """
+    Undiscounted US price of the Steam store's exact-name match for
+    `name`, or None when there is none. Steam lists today's price, which
+    for most games is still their launch price.
+    """
+    client = client_for("steam")
+    resp = client.get(STEAM_STORE_SEARCH_URL, params={"term": name, "cc": "us", "l": "english"})
+    if resp.status_code != 200:
+        print(f"WARNING: Steam store search for {name!r} failed with status {resp.status_code}")
+        return None
+
+    items_value: Any = resp.json().get("items")
+    if items_value is None:
+        return None
+
+    for item in items_value:
+        if str(item.get("name", "")).strip().lower() != name.lower():
+            continue
+        price_value: Any = item.get("price")
+        if price_value is None:
+            # Listed without a price: free on Steam.
+            return 0.0
+        return float(price_value.get("initial", 0)) / 100.0
+
+    return None
+
+# ------------------------------------------------------------
+# 4d. IGDB fetching
+# ------------------------------------------------------------
+
+IGDB_TOKEN_URL: str = "https://id.twitch.tv/oauth2/token"
+IGDB_GAMES_URL: str = "https://api.igdb.com/v4/games"
+
+# IGDB counts far fewer ratings per game than RAWG.
+IGDB_MIN_RATING_COUNT: int = 50
+
+IGDB_FIELDS: str = ", ".join([
+    "name", "first_release_date", "total_rating_count", "aggregated_rating",
+    "platforms.name", "genres.name", "themes.name", "keywords.name",
+    "player_perspectives.name", "game_modes.name",
+    "franchises.name", "collection.name",
+    "involved_companies.developer", "involved_companies.company.name",
+    "age_ratings.category", "age_ratings.rating",
+])
+
+# IGDB genre names -> the RAWG names the rest of the builder expects.
+IGDB_GENRE_MAP: Dict[str, str] = {
+    "Role-playing (RPG)": "RPG",
+    "Shooter": "Shooter",
+    "Adventure": "Adventure",
+    "Indie": "Indie",
+    "Platform": "Platformer",
+    "Strategy": "Strategy",
+    "Real Time Strategy (RTS)": "Strategy",
+    "Turn-based strategy (TBS)": "Strategy",
+    "Tactical": "Strategy",
+    "Simulator": "Simulation",
+    "Sport": "Sports",
+    "Racing": "Racing",
+    "Puzzle": "Puzzle",
+    "Fighting": "Fighting",
+    "Hack and slash/Beat 'em up": "Action",
+    "Arcade": "Arcade",
+    "Card & Board Game": "Board Games",
+    "MOBA": "Strategy",
+}
+
+# IGDB age_ratings: category 1 is ESRB, with these rating codes.
+IGDB_ESRB_CATEGORY: int = 1
+IGDB_ESRB_RATINGS: Dict[int, str] = {
+    8: "Everyone",
+    9: "Everyone 10+",
+    10: "Teen",
+    11: "Mature",
+}
+
+
+def igdb_access_token(creds: Dict[str, str]) -> str:
+    client = client_for("igdb")
+    resp = client.post(IGDB_TOKEN_URL, params={
+        "client_id": creds["client_id"],
+        "client_secret": creds["client_secret"],
+        "grant_type": "client_credentials",
+    })
+    if resp.status_code != 200:
+        raise RuntimeError(f"IGDB token request failed with status {resp.status_code}: {resp.text[:200]}")
+    return str(resp.json()["access_token"])
+
+
+def load_raw_games_from_igdb() -> List[Dict[str, Any]]:
+    """
+    Fetch the most-rated games of 2010–2024 from IGDB, converted to the
+    RAWG shape transform_raw_to_games reads (see igdb_to_raw).
+    """
+    creds: Dict[str, str] = load_credentials("igdb")
+    token: str = igdb_access_token(creds)
+    client = client_for("igdb")
+    headers: Dict[str, str] = {
+        "Client-ID": creds["client_id"],
+        "Authorization": f"Bearer {token}",
+    }
+
+    start: int = int(datetime(2010, 1, 1, tzinfo=timezone.utc).timestamp())
+    end: int = int(datetime(2025, 1, 1, tzinfo=timezone.utc).timestamp())
+
+    raw_games: List[Dict[str, Any]] = []
+    page_size: int = 500
+    max_pages: int = 4
+
+    for page in range(max_pages):
+        query: str = (
+            f"fields {IGDB_FIELDS};"
+            f" where first_release_date >= {start} & first_release_date < {end}"
+            f" & total_rating_count >= {IGDB_MIN_RATING_COUNT};"
+            f" sort total_rating_count desc;"
+            f" limit {page_size}; offset {page * page_size};"
+        )
+
+        print(f"Fetching IGDB page {page + 1}/{max_pages}...")
+        resp = client.post(IGDB_GAMES_URL, headers=headers, data=query)
+        if resp.status_code != 200:
+            print(f"WARNING: IGDB request failed with status {resp.status_code}: {resp.text[:200]}")
+            break
+
+        results: List[Dict[str, Any]] = resp.json()
+        if len(results) == 0:
+            print("No more results, stopping pagination.")
+            break
+
+        for r in results:
+            raw_games.append(igdb_to_raw(r))
+
+    print(f"Total raw games fetched from IGDB: {len(raw_games)}")
+    return raw_games
+
+
+def igdb_names(record: Dict[str, Any], key: str) -> List[str]:
+    names: List[str] = []
+    for item in record.get(key) or []:
+        name_value: Any = item.get("name")
+        if name_value is not None:
+            names.append(str(name_value))
+    return names
+
+
+def igdb_to_raw(record: Dict[str, Any]) -> Dict[str, Any]:
+    """
+    One IGDB game as a RAWG-style record. Themes, keywords, perspectives
+    and game modes all go into tags, so the tag classifiers still see
+    them; the structured taxonomy also rides along under "igdb" for
+    apply_igdb_taxonomy.
+    """
+    themes: List[str] = igdb_names(record, "themes")
+    perspectives: List[str] = igdb_names(record, "player_perspectives")
+    game_modes: List[str] = igdb_names(record, "game_modes")
+    keywords: List[str] = igdb_names(record, "keywords")
+
+    genres: List[str] = []
+    for name in igdb_names(record, "genres"):
+        mapped: str = IGDB_GENRE_MAP.get(name, name)
+        if mapped not in genres:
+            genres.append(mapped)
+    # IGDB files "Action" under themes; main_genre is the first genre.
+    if "Action" in themes and "Action" not in genres:
+        genres.insert(0, "Action")
+
+    developers: List[Dict[str, str]] = []
+    for involved in record.get("involved_companies") or []:
+        company: Any = involved.get("company")
+        if involved.get("developer") and company is not None and company.get("name") is not None:
+            developers.append({"name": str(company["name"])})
+
+    esrb_rating: Optional[Dict[str, str]] = None
+    for rating in record.get("age_ratings") or []:
+        if rating.get("category") == IGDB_ESRB_CATEGORY and rating.get("rating") in IGDB_ESRB_RATINGS:
+            esrb_rating = {"name": IGDB_ESRB_RATINGS[rating["rating"]]}
+
+    released: Optional[str] = None
+    if record.get("first_release_date") is not None:
+        released = datetime.fromtimestamp(record["first_release_date"], tz=timezone.utc).strftime("%Y-%m-%d")
+
+    franchises: List[str] = igdb_names(record, "franchises")
+    collection: Any = record.get("collection")
+    if len(franchises) == 0 and collection is not None and collection.get("name") is not None:
+        franchises.append(str(collection["name"]))
+
+    return {
+        "name": record.get("name"),
+        "released": released,
+        "ratings_count": record.get("total_rating_count", 0),
+        "metacritic": round(record["aggregated_rating"]) if record.get("aggregated_rating") is not None else None,
+        "platforms": [{"platform": {"name": name}} for name in igdb_names(record, "platforms")],
+        "genres": [{"name": name} for name in genres],
+        "tags": [{"name": name} for name in themes + perspectives + game_modes + keywords],
+        "developers": developers,
+        "esrb_rating": esrb_rating,
+        "igdb": {
+            "themes": themes,
+            "player_perspectives": perspectives,
+            "game_modes": game_modes,
+            "keywords": keywords,
+            "franchises": franchises,
+        },
+    }
+
+
+# IGDB player perspectives, most specific first (like classify_camera,
+# third person wins when a game has both).
+IGDB_PERSPECTIVES: List[tuple] = [
+    ("Third person", "Third Person"),
+    ("First person", "First Person"),
+    ("Virtual Reality", "First Person"),
+    ("Bird view / Isometric", "Isometric"),
+    ("Side view", "Side"),
+]
+
+IGDB_THEMES: List[tuple] = [
+    ("Horror", "Horror"),
+    ("Science fiction", "Sci-Fi"),
+    ("Fantasy", "Fantasy"),
+    ("Historical", "Historical"),
+    ("Warfare", "Historical"),
+]
+
+
+def classify_igdb_perspective(perspectives: List[str], fallback: str) -> str:
+    for igdb_name, ours in IGDB_PERSPECTIVES:
+        if igdb_name in perspectives:
+            return ours
+    return fallback
+
+
+def classify_igdb_theme(themes: List[str], keywords: List[str]) -> str:
+    # Horror first, as in classify_theme; IGDB has no post-apocalyptic
+    # theme, only the keyword.
+    if "Horror" in themes:
+        return "Horror"
+    from_keywords: str = classify_theme([], keywords)
+    if from_keywords == "Post-Apocalyptic":
+        return from_keywords
+    for igdb_name, ours in IGDB_THEMES:
+        if igdb_name in themes:
+            return ours
+    return from_keywords
+
+
+def classify_igdb_world_type(themes: List[str], keywords: List[str]) -> str:
+    if "Open world" in themes or "Sandbox" in themes:
+        return "Open World"
+    return classify_world_type(keywords)
+
+
+def apply_igdb_taxonomy(game: Game, taxonomy: Dict[str, Any]) -> None:
+    """
+    Overwrite the tag-guessed fields of `game` with IGDB's structured
+    ones where IGDB has them.
+    """
+    themes: List[str] = taxonomy["themes"]
+    keywords: List[str] = taxonomy["keywords"]
+    modes: List[str] = taxonomy["game_modes"]
+
+    game.perspective = classify_igdb_perspective(taxonomy["player_perspectives"], game.perspective)
+    game.camera = game.perspective
+    game.theme = classify_igdb_theme(themes, keywords)
+    game.world_type = classify_igdb_world_type(themes, keywords)
+
+    if len(modes) > 0:
+        game.multiplayer = any(m in modes for m in (
+            "Multiplayer", "Massively Multiplayer Online (MMO)", "Battle Royale",
+        ))
+        game.co_op = "Co-operative" in modes
+        game.multiplayer_mode = classify_multiplayer_mode(
+            game.multiplayer, game.co_op, game.online_only, modes + keywords,
+        )
+
+    # Keep our own names for the franchises detect_franchise knows.
+    if game.franchise == "Standalone / Other" and len(taxonomy["franchises"]) > 0:
+        game.franchise = taxonomy["franchises"][0]
+
+
 # ------------------------------------------------------------
 # 5. Transform RAWG data -> Game objects
 # ------------------------------------------------------------
 
-def transform_raw_to_games(raw_games: List[Dict[str, Any]]) -> List[Game]:
+def transform_raw_to_games(raw_games: List[Dict[str, Any]], min_ratings_count: int = 500) -> List[Game]:
     games: List[Game] = []
     next_id: int = 1
 
@@ -697,7 +1111,7 @@ def transform_raw_to_games(raw_games: List[Dict[str, Any]]) -> List[Game]:
                 ratings_count = 0
 
         # Skip extremely niche games.
-        if ratings_count < 500:
+        if ratings_count < min_ratings_count:
             continue
 
         # ----- Platforms -----
@@ -786,6 +1200,7 @@ def transform_raw_to_games(raw_games: List[Dict[str, Any]]) -> List[Game]:
             online_only = True
 
         multiplayer_mode: str = classify_multiplayer_mode(multiplayer, co_op, online_only, tag_names)
+        vr_support: str = classify_vr_support(tag_names)
 
         # ----- Score bucket -----
         meta_value: Any = raw.get("metacritic")
@@ -800,6 +1215,9 @@ def transform_raw_to_games(raw_games: List[Dict[str, Any]]) -> List[Game]:
 
         score_bucket: str = bucket_score(score)
 
+        # ----- Playtime bucket -----
+        playtime_bucket: str = bucket_playtime(lookup_main_story_hours(name))
+
         # ----- ESRB + Age -----
         esrb_raw_value: Any = raw.get("esrb_rating")
         if esrb_raw_value is None:
@@ -833,6 +1251,9 @@ def transform_raw_to_games(raw_games: List[Dict[str, Any]]) -> List[Game]:
         # ----- Monetization -----
         monetization: List[str] = classify_monetization(tag_names)
 
+        # ----- Launch price -----
+        price_bucket: str = bucket_price(monetization, lookup_steam_price_usd(name))
+
         # ----- Build final Game -----
         game: Game = Game(
             id=next_id,
@@ -865,9 +1286,19 @@ def transform_raw_to_games(raw_games: List[Dict[str, Any]]) -> List[Game]:
             co_op=co_op,
             online_only=online_only,
             multiplayer_mode=multiplayer_mode,
+            vr_support=vr_support,
+            controller_support="Unknown",
             score_bucket=score_bucket,
+            playtime_bucket=playtime_bucket,
+            price_bucket=price_bucket,
+            popularity=ratings_count,
+            aliases=derive_aliases(name),
         )
 
+        igdb_value: Any = raw.get("igdb")
+        if igdb_value is not None:
+            apply_igdb_taxonomy(game, igdb_value)
+
         games.append(game)
         next_id += 1
 
@@ -888,11 +1319,21 @@ def sample_games(games: List[Game], target_size: int) -> List[Game]:
 
 
 def main() -> None:
-    print("Loading raw games...")
-    raw_games: List[Dict[str, Any]] = load_raw_games_from_api()
+    parser = argparse.ArgumentParser(description="Build games.json.")
+    parser.add_argument("--source", choices=["rawg", "igdb"], default="rawg", help="where to fetch games from")
+    args = parser.parse_args()
+
+    print(f"Loading raw games from {args.source}...")
+    raw_games: List[Dict[str, Any]]
+    min_ratings_count: int = 500
+    if args.source == "igdb":
+        raw_games = load_raw_games_from_igdb()
+        min_ratings_count = IGDB_MIN_RATING_COUNT
+    else:
+        raw_games = load_raw_games_from_api()
     print(f"Raw games count: {len(raw_games)}")
 
-    transformed: List[Game] = transform_raw_to_games(raw_games)
+    transformed: List[Game] = transform_raw_to_games(raw_games, min_ratings_count)
     print(f"After filtering/transform: {len(transformed)}")
 
     final_games: List[Game] = sample_games(transformed, target_size=500)
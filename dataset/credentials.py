@@ -0,0 +1,251 @@
+#!/usr/bin/env python3
+"""
+credentials.py
+
+Central place for the API keys the dataset builders need (RAWG, IGDB,
+Steam), plus a small rate-limit-aware HTTP client per provider.
+
+Lookup order for every credential field:
+
+1. Environment variable (e.g. RAWG_API_KEY).
+2. Credentials file: $GG_CREDENTIALS_FILE, or
+   ~/.config/game-guesser/credentials.json, shaped like
+       {"rawg": {"api_key": "..."}, "igdb": {"client_id": "...", ...}}
+3. System keychain via the optional `keyring` package
+   (service "game-guesser", username "<provider>:<field>").
+
+Usage:
+
+    from credentials import client_for, load_credentials
+
+    creds = load_credentials("rawg")
+    client = client_for("rawg")
+    resp = client.get(url, params={"key": creds["api_key"]})
+"""
+
+from __future__ import annotations
+
+import json
+import os
+import time
+from dataclasses import dataclass
+from typing import Any, Dict, List, Optional
+
+import requests
+
+
+# ------------------------------------------------------------
+# 1. Provider definitions
+# ------------------------------------------------------------
+
+@dataclass
+class ProviderSpec:
+    name: str
+    # field name -> environment variable
+    fields: Dict[str, str]
+    signup_url: str
+    # Conservative defaults; the providers publish their own limits.
+    requests_per_second: float
+
+
+PROVIDERS: Dict[str, ProviderSpec] = {
+    "rawg": ProviderSpec(
+        name="RAWG",
+        fields={"api_key": "RAWG_API_KEY"},
+        signup_url="https://rawg.io/apidocs",
+        requests_per_second=5.0,
+    ),
+    "igdb": ProviderSpec(
+        name="IGDB (Twitch)",
+        fields={
+            "client_id": "IGDB_CLIENT_ID",
+            "client_secret": "IGDB_CLIENT_SECRET",
+        },
+        signup_url="https://api-docs.igdb.com/#account-creation",
+        requests_per_second=4.0,
+    ),
+    "steam": ProviderSpec(
+        name="Steam Web API",
+        fields={"api_key": "STEAM_API_KEY"},
+        signup_url="https://steamcommunity.com/dev/apikey",
+        requests_per_second=1.0,
+    ),
+    # Keyless; SteamSpy asks for at most one request per second.
+    "steamspy": ProviderSpec(
+        name="SteamSpy",
+        fields={},
+        signup_url="https://steamspy.com/api.php",
+        requests_per_second=1.0,
+    ),
+}
+
+KEYRING_SERVICE: str = "game-guesser"
+
+
+class CredentialsError(RuntimeError):
+    pass
+
+
+# ------------------------------------------------------------
+# 2. Loading
+# ------------------------------------------------------------
+
+def credentials_file_path() -> str:
+    override: Optional[str] = os.getenv("GG_CREDENTIALS_FILE")
+    if override is not None and override != "":
+        return override
+
+    return os.path.join(os.path.expanduser("~"), ".config", "game-guesser", "credentials.json")
+
+
+def read_credentials_file(path: str) -> Dict[str, Dict[str, str]]:
+    if not os.path.exists(path):
+        return {}
+
+    try:
+        with open(path, "r", encoding="utf-8") as f:
+            data: Any = json.load(f)
+    except (OSError, ValueError) as exc:
+        raise CredentialsError(f"Could not read credentials file {path}: {exc}") from exc
+
+    if not isinstance(data, dict):
+        raise CredentialsError(f"Credentials file {path} must contain a JSON object keyed by provider.")
+
+    return data
+
+
+def read_keyring(provider: str, field: str) -> Optional[str]:
+    try:
+        import keyring  # type: ignore
+    except ImportError:
+        return None
+
+    try:
+        value: Optional[str] = keyring.get_password(KEYRING_SERVICE, f"{provider}:{field}")
+    except Exception:
+        # No usable keychain backend (headless CI, etc.).
+        return None
+
+    return value
+
+
+def load_credentials(provider: str) -> Dict[str, str]:
+    """
+    Return every field for `provider`, or raise CredentialsError naming
+    exactly which fields are missing and where we looked.
+    """
+    if provider not in PROVIDERS:
+        known: str = ", ".join(sorted(PROVIDERS.keys()))
+        raise CredentialsError(f"Unknown provider {provider!r} (known: {known}).")
+
+    spec: ProviderSpec = PROVIDERS[provider]
+    path: str = credentials_file_path()
+    file_section_value: Any = read_credentials_file(path).get(provider)
+    file_section: Dict[str, str] = {}
+    if isinstance(file_section_value, dict):
+        file_section = file_section_value
+
+    result: Dict[str, str] = {}
+    missing: List[str] = []
+
+    for field, env_var in spec.fields.items():
+        value: Optional[str] = os.getenv(env_var)
+
+        if value is None or value.strip() == "":
+            file_value: Any = file_section.get(field)
+            value = str(file_value) if file_value is not None else None
+
+        if value is None or value.strip() == "":
+            value = read_keyring(provider, field)
+
+        if value is None or value.strip() == "":
+            missing.append(field)
+            continue
+
+        result[field] = value.strip()
+
+    if len(missing) > 0:
+        lines: List[str] = [f"Missing {spec.name} credentials: {', '.join(missing)}.", "Provide them via one of:"]
+        for field in missing:
+            lines.append(f"  - export {spec.fields[field]}=...")
+        lines.append(f"  - \"{provider}\": {{\"{missing[0]}\": \"...\"}} in {path}")
+        lines.append(f"  - keyring set {KEYRING_SERVICE} {provider}:{missing[0]}")
+        lines.append(f"Get a key at {spec.signup_url}")
+        raise CredentialsError("\n".join(lines))
+
+    return result
+
+
+# ------------------------------------------------------------
+# 3. Rate-limit-aware client
+# ------------------------------------------------------------
+
+class RateLimitedClient:
+    """
+    Thin wrapper over requests.Session that spaces out calls to stay under
+    the provider's request rate and retries 429 / 5xx responses, honouring
+    Retry-After when the provider sends it.
+    """
+
+    def __init__(self, spec: ProviderSpec, max_retries: int = 4) -> None:
+        self.spec: ProviderSpec = spec
+        self.max_retries: int = max_retries
+        self.min_interval: float = 1.0 / spec.requests_per_second
+        self.session: requests.Session = requests.Session()
+        self.last_request_at: float = 0.0
+
+    def wait_for_slot(self) -> None:
+        elapsed: float = time.monotonic() - self.last_request_at
+        if elapsed < self.min_interval:
+            time.sleep(self.min_interval - elapsed)
+        self.last_request_at = time.monotonic()
+
+    def request(self, method: str, url: str, **kwargs: Any) -> requests.Response:
+        kwargs.setdefault("timeout", 15)
+        backoff: float = 1.0
+
+        for attempt in range(self.max_retries + 1):
+            self.wait_for_slot()
+            resp: requests.Response = self.session.request(method, url, **kwargs)
+
+            if resp.status_code != 429 and resp.status_code < 500:
+                return resp
+            if attempt == self.max_retries:
+                return resp
+
+            delay: float = backoff
+            retry_after: Optional[str] = resp.headers.get("Retry-After")
+            if retry_after is not None:
+                try:
+                    delay = max(delay, float(retry_after))
+                except ValueError:
+                    pass
+
+            print(f"{self.spec.name}: HTTP {resp.status_code}, retrying in {delay:.1f}s...")
+            time.sleep(delay)
+            backoff *= 2
+
+        return resp
+
+    def get(self, url: str, **kwargs: Any) -> requests.Response:
+        return self.request("GET", url, **kwargs)
+
+    def post(self, url: str, **kwargs: Any) -> requests.Response:
+        return self.request("POST", url, **kwargs)
+
+
+_clients: Dict[str, RateLimitedClient] = {}
+
+
+def client_for(provider: str) -> RateLimitedClient:
+    """
+    Shared client per provider, so separate builder stages still respect
+    one combined rate limit.
+    """
+    if provider not in PROVIDERS:
+        raise CredentialsError(f"Unknown provider {provider!r}.")
+
+    if provider not in _clients:
+        _clients[provider] = RateLimitedClient(PROVIDERS[provider])
+
+    return _clients[provider]
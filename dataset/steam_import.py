@@ -0,0 +1,246 @@
+#!/usr/bin/env python3
+"""
+steam_import.py
+
+Enrich an existing games.json with Steam store data:
+
+- Steam user tags (via SteamSpy) feed visual_style, mood and
+  structure_features, on top of what the builder already found.
+- The current list price (not a sale price) sets price_bucket.
+- Store categories set controller_support and vr_support.
+
+Games are matched to Steam apps by fuzzy title match against the store
+search; STEAM_APP_OVERRIDES pins the ones that match wrongly or not at
+all. Unmatched games are left as they are.
+
+Usage:
+
+    python steam_import.py [games.json] [--out games.json]
+"""
+
+from __future__ import annotations
+
+import argparse
+import difflib
+import json
+import os
+import re
+from typing import Any, Dict, List, Optional
+
+from build_games import (
+    bucket_price,
+    classify_mood,
+    classify_structure_features,
+    classify_visual_style,
+)
+from credentials import client_for
+
+
+STEAM_STORE_SEARCH_URL: str = "https://store.steampowered.com/api/storesearch/"
+STEAM_APP_DETAILS_URL: str = "https://store.steampowered.com/api/appdetails"
+STEAMSPY_URL: str = "https://steamspy.com/api.php"
+
+# Title similarity (0-1) below which a search result is another game.
+STEAM_MIN_SIMILARITY: float = 0.85
+
+# games.json name -> Steam app ID, or None for games that aren't on Steam
+# but have a lookalike that is.
+STEAM_APP_OVERRIDES: Dict[str, Optional[int]] = {
+    "Grand Theft Auto V": 271590,
+    "The Elder Scrolls V: Skyrim": 72850,
+    "Counter-Strike: Global Offensive": 730,
+    "PlayerUnknown's Battlegrounds": 578080,
+    "Tom Clancy's Rainbow Six Siege": 359550,
+    "The Legend of Zelda: Breath of the Wild": None,
+    "Bloodborne": None,
+}
+
+# Placeholders the classifiers return when no tag matched.
+PLACEHOLDERS: Dict[str, str] = {
+    "visual_style": "Unspecified",
+    "mood": "Neutral",
+    "structure_features": "None / Standard",
+}
+
+
+# ------------------------------------------------------------
+# 1. Matching
+# ------------------------------------------------------------
+
+NON_WORD_PATTERN = re.compile(r"[^a-z0-9]+")
+
+
+def normalize_title(name: str) -> str:
+    # Steam often adds ™/® and edition suffixes our names don't have.
+    lowered: str = name.lower().replace("™", "").replace("®", "")
+    return NON_WORD_PATTERN.sub(" ", lowered).strip()
+
+
+def title_similarity(a: str, b: str) -> float:
+    return difflib.SequenceMatcher(None, normalize_title(a), normalize_title(b)).ratio()
+
+
+def find_steam_app(name: str) -> Optional[int]:
+    """
+    App ID of the store search result closest to `name`, if it is close
+    enough, or the override for it.
+    """
+    if name in STEAM_APP_OVERRIDES:
+        return STEAM_APP_OVERRIDES[name]
+
+    client = client_for("steam")
+    resp = client.get(STEAM_STORE_SEARCH_URL, params={"term": name, "cc": "us", "l": "english"})
+    if resp.status_code != 200:
+        print(f"WARNING: Steam store search for {name!r} failed with status {resp.status_code}")
+        return None
+
+    best_id: Optional[int] = None
+    best_score: float = 0.0
+    for item in resp.json().get("items") or []:
+        score: float = title_similarity(name, str(item.get("name", "")))
+        if score > best_score:
+            best_id, best_score = int(item["id"]), score
+
+    if best_score < STEAM_MIN_SIMILARITY:
+        return None
+    return best_id
+
+
+# ------------------------------------------------------------
+# 2. Steam data
+# ------------------------------------------------------------
+
+def fetch_app_details(app_id: int) -> Optional[Dict[str, Any]]:
+    client = client_for("steam")
+    resp = client.get(STEAM_APP_DETAILS_URL, params={"appids": app_id, "cc": "us", "l": "english"})
+    if resp.status_code != 200:
+        print(f"WARNING: Steam app details for {app_id} failed with status {resp.status_code}")
+        return None
+
+    entry: Any = resp.json().get(str(app_id))
+    if entry is None or not entry.get("success"):
+        return None
+    return entry.get("data")
+
+
+def fetch_steam_tags(app_id: int) -> List[str]:
+    client = client_for("steamspy")
+    resp = client.get(STEAMSPY_URL, params={"request": "appdetails", "appid": app_id})
+    if resp.status_code != 200:
+        print(f"WARNING: SteamSpy lookup for {app_id} failed with status {resp.status_code}")
+        return []
+
+    # {"Pixel Graphics": 1520, ...}, or [] when the app has no tags.
+    tags_value: Any = resp.json().get("tags")
+    if not isinstance(tags_value, dict):
+        return []
+    return list(tags_value.keys())
+
+
+def steam_price_usd(details: Dict[str, Any]) -> Optional[float]:
+    if details.get("is_free"):
+        return 0.0
+    price: Any = details.get("price_overview")
+    if price is None:
+        return None
+    return float(price.get("initial", 0)) / 100.0
+
+
+def steam_categories(details: Dict[str, Any]) -> List[str]:
+    return [str(c.get("description", "")).lower() for c in details.get("categories") or []]
+
+
+def classify_controller_support(details: Dict[str, Any]) -> str:
+    categories: List[str] = steam_categories(details)
+    if "full controller support" in categories:
+        return "Full"
+    if "partial controller support" in categories:
+        return "Partial"
+    return "None"
+
+
+def classify_steam_vr_support(details: Dict[str, Any], current: str) -> str:
+    categories: List[str] = steam_categories(details)
+    if "vr only" in categories:
+        return "VR-only"
+    if "vr supported" in categories or "vr support" in categories:
+        return "VR-optional"
+    return current
+
+
+# ------------------------------------------------------------
+# 3. Enrichment
+# ------------------------------------------------------------
+
+def merge_labels(current: List[str], found: List[str], placeholder: str) -> List[str]:
+    """
+    Labels from both lists, dropping the placeholder unless neither has
+    anything else.
+    """
+    merged: List[str] = [v for v in current + found if v != placeholder]
+    result: List[str] = []
+    for v in merged:
+        if v not in result:
+            result.append(v)
+    if len(result) == 0:
+        result.append(placeholder)
+    return result
+
+
+def enrich_game(game: Dict[str, Any]) -> bool:
+    """
+    Update `game` in place from Steam; False when it isn't on Steam.
+    """
+    app_id: Optional[int] = find_steam_app(game["name"])
+    if app_id is None:
+        return False
+
+    details: Optional[Dict[str, Any]] = fetch_app_details(app_id)
+    if details is None:
+        return False
+
+    tags: List[str] = fetch_steam_tags(app_id)
+    classifiers = {
+        "visual_style": classify_visual_style,
+        "mood": classify_mood,
+        "structure_features": classify_structure_features,
+    }
+    for field, classify in classifiers.items():
+        game[field] = merge_labels(game.get(field) or [], classify(tags), PLACEHOLDERS[field])
+
+    usd: Optional[float] = steam_price_usd(details)
+    if usd is not None:
+        game["price_bucket"] = bucket_price(game.get("monetization") or [], usd)
+
+    game["controller_support"] = classify_controller_support(details)
+    game["vr_support"] = classify_steam_vr_support(details, game.get("vr_support", "None"))
+    return True
+
+
+def main() -> None:
+    parser = argparse.ArgumentParser(description="Enrich games.json with Steam tags, prices and controller/VR support.")
+    parser.add_argument("path", nargs="?", default="games.json", help="games.json to read")
+    parser.add_argument("--out", help="where to write the result (default: overwrite path)")
+    args = parser.parse_args()
+
+    with open(args.path, "r", encoding="utf-8") as f:
+        games: List[Dict[str, Any]] = json.load(f)
+
+    matched: int = 0
+    for i, game in enumerate(games, start=1):
+        print(f"[{i}/{len(games)}] {game['name']}")
+        game.setdefault("controller_support", "Unknown")
+        if enrich_game(game):
+            matched += 1
+
+    out_path: str = args.out or args.path
+    tmp_path: str = out_path + ".tmp"
+    with open(tmp_path, "w", encoding="utf-8") as f:
+        json.dump(games, f, indent=2, ensure_ascii=False)
+    os.replace(tmp_path, out_path)
+
+    print(f"Matched {matched}/{len(games)} games on Steam; wrote {out_path}")
+
+
+if __name__ == "__main__":
+    main()
@@ -148,7 +148,7 @@ const App: React.FC = () => {
   useEffect(() => {
     if (!sessionId) return;
 
-    fetch(`/api/session/${encodeURIComponent(sessionId)}/mystery`, {
+    fetch(`/api/v1/session/${encodeURIComponent(sessionId)}/mystery`, {
       method: "POST",
     })
       .then(res => res.json())
@@ -165,7 +165,7 @@ const App: React.FC = () => {
     setPhase("selectingMystery");
 
     try {
-      const response = await fetch("/api/session/start", {
+      const response = await fetch("/api/v1/session/start", {
         method: "POST",
       });
 
@@ -230,7 +230,7 @@ const App: React.FC = () => {
 
     try {
       const response: Response = await fetch(
-        "/api/session/" + encodeURIComponent(sessionId) + "/ask",
+        "/api/v1/session/" + encodeURIComponent(sessionId) + "/ask",
         {
           method: "POST",
           headers: {
@@ -290,7 +290,7 @@ const App: React.FC = () => {
 
     try {
       const response: Response = await fetch(
-        "/api/session/" + encodeURIComponent(sessionId) + "/guess",
+        "/api/v1/session/" + encodeURIComponent(sessionId) + "/guess",
         {
           method: "POST",
           headers: {
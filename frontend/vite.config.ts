@@ -4,6 +4,11 @@ import react from "@vitejs/plugin-react";
 
 export default defineConfig({
   plugins: [react()],
+  // The Go server embeds the build (backend/static.go).
+  build: {
+    outDir: "../backend/web/dist",
+    emptyOutDir: true,
+  },
   server: {
     proxy: {
       "/api": {